@@ -25,6 +25,7 @@ import (
 	"istio.io/bots/policybot/pkg/cmdutil"
 	"istio.io/bots/policybot/pkg/config"
 	"istio.io/bots/policybot/pkg/gh"
+	"istio.io/bots/policybot/pkg/storage"
 	"istio.io/bots/policybot/pkg/storage/cache"
 	"istio.io/bots/policybot/pkg/storage/spanner"
 )
@@ -33,21 +34,44 @@ func flakeMgrCmd() *cobra.Command {
 	cmd, _ := cmdutil.Run("flakemgr", "Run the test flake manager", 0,
 		cmdutil.ConfigPath|cmdutil.ConfigRepo|cmdutil.GitHubToken, runFlakeMgr)
 
+	issuesCmd, _ := cmdutil.Run("issues", "Files, updates, and closes issues for flaky tests", 0,
+		cmdutil.ConfigPath|cmdutil.ConfigRepo|cmdutil.GitHubToken, runFlakeIssues)
+	cmd.AddCommand(issuesCmd)
+
 	return cmd
 }
 
 // Runs the flake manager.
 func runFlakeMgr(reg *config.Registry, secrets *cmdutil.Secrets) error {
+	mgr, store, err := newFlakeMgr(reg, secrets)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	return writeReport(mgr.Nag(context.Background(), false))
+}
+
+// Runs the flake manager's issue filing.
+func runFlakeIssues(reg *config.Registry, secrets *cmdutil.Secrets) error {
+	mgr, store, err := newFlakeMgr(reg, secrets)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	return writeReport(mgr.FileIssues(context.Background(), false))
+}
+
+func newFlakeMgr(reg *config.Registry, secrets *cmdutil.Secrets) (*flakemgr.FlakeManager, storage.Store, error) {
 	core := reg.Core()
 
 	store, err := spanner.NewStore(context.Background(), core.SpannerDatabase)
 	if err != nil {
-		return fmt.Errorf("unable to create storage layer: %v", err)
+		return nil, nil, fmt.Errorf("unable to create storage layer: %v", err)
 	}
-	defer store.Close()
 
 	gc := gh.NewThrottledClient(context.Background(), secrets.GitHubToken)
 	c := cache.New(store, time.Duration(core.CacheTTL))
-	mgr := flakemgr.New(gc, store, c, reg)
-	return mgr.Nag(context.Background(), false)
+	return flakemgr.New(gc, store, c, reg), store, nil
 }
@@ -35,5 +35,5 @@ func labelMgrCmd() *cobra.Command {
 func runLabelMgr(reg *config.Registry, secrets *cmdutil.Secrets) error {
 	gc := gh.NewThrottledClient(context.Background(), secrets.GitHubToken)
 	mgr := labelmgr.New(gc, reg)
-	return mgr.MakeConfiguredLabels(context.Background(), false)
+	return writeReport(mgr.MakeConfiguredLabels(context.Background(), false))
 }
@@ -17,6 +17,7 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"os"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -25,6 +26,7 @@ import (
 	"istio.io/bots/policybot/pkg/cmdutil"
 	"istio.io/bots/policybot/pkg/config"
 	"istio.io/bots/policybot/pkg/gh"
+	"istio.io/bots/policybot/pkg/storage"
 	"istio.io/bots/policybot/pkg/storage/cache"
 	"istio.io/bots/policybot/pkg/storage/spanner"
 )
@@ -33,20 +35,72 @@ func lifecycleMgrCmd() *cobra.Command {
 	cmd, _ := cmdutil.Run("lifecyclemgr", "Runs the issue and pull request lifecycle manager", 0,
 		cmdutil.ConfigPath|cmdutil.ConfigRepo|cmdutil.GitHubToken, runLifecycleMgr)
 
+	cmd.AddCommand(lifecycleForecastCmd())
+
+	return cmd
+}
+
+func lifecycleForecastCmd() *cobra.Command {
+	days := 30
+	org := ""
+	format := "table"
+
+	cmd, _ := cmdutil.Run("forecast", "Reports which issues and pull requests the lifecycle manager will mark stale or close", 0,
+		cmdutil.ConfigPath|cmdutil.ConfigRepo|cmdutil.GitHubToken, func(reg *config.Registry, secrets *cmdutil.Secrets) error {
+			return runLifecycleForecast(reg, secrets, org, days, format)
+		})
+
+	cmd.PersistentFlags().IntVarP(&days, "days", "", days, "The number of days to forecast")
+	cmd.PersistentFlags().StringVarP(&org, "org", "", org, "The org whose repos to forecast, all orgs when empty")
+	cmd.PersistentFlags().StringVarP(&format, "format", "", format, "The output format, one of [table, csv, json]")
+
 	return cmd
 }
 
 func runLifecycleMgr(reg *config.Registry, secrets *cmdutil.Secrets) error {
+	mgr, store, err := newLifecycleMgr(reg, secrets)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	return writeReport(mgr.ManageAll(context.Background(), false))
+}
+
+func runLifecycleForecast(reg *config.Registry, secrets *cmdutil.Secrets, org string, days int, format string) error {
+	if days < 0 {
+		return fmt.Errorf("--days must not be negative")
+	}
+
+	mgr, store, err := newLifecycleMgr(reg, secrets)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	f, err := mgr.Forecast(context.Background(), org, days)
+	if err != nil {
+		return err
+	}
+
+	return f.Write(os.Stdout, format)
+}
+
+func newLifecycleMgr(reg *config.Registry, secrets *cmdutil.Secrets) (*lifecyclemgr.LifecycleMgr, storage.Store, error) {
 	core := reg.Core()
 
 	store, err := spanner.NewStore(context.Background(), core.SpannerDatabase)
 	if err != nil {
-		return fmt.Errorf("unable to create storage layer: %v", err)
+		return nil, nil, fmt.Errorf("unable to create storage layer: %v", err)
 	}
-	defer store.Close()
 
 	gc := gh.NewThrottledClient(context.Background(), secrets.GitHubToken)
 	c := cache.New(store, time.Duration(core.CacheTTL))
-	mgr := lifecyclemgr.New(gc, store, c, reg)
-	return mgr.ManageAll(context.Background(), false)
+	mgr, err := lifecyclemgr.New(gc, store, c, reg)
+	if err != nil {
+		_ = store.Close()
+		return nil, nil, fmt.Errorf("unable to create lifecycle manager: %v", err)
+	}
+
+	return mgr, store, nil
 }
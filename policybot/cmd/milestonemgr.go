@@ -35,5 +35,5 @@ func milestoneMgrCmd() *cobra.Command {
 func runMilestoneMgr(reg *config.Registry, secrets *cmdutil.Secrets) error {
 	gc := gh.NewThrottledClient(context.Background(), secrets.GitHubToken)
 	mgr := milestonemgr.New(gc, reg)
-	return mgr.MakeConfiguredMilestones(context.Background(), false)
+	return writeReport(mgr.MakeConfiguredMilestones(context.Background(), false))
 }
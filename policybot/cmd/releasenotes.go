@@ -0,0 +1,94 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"istio.io/bots/policybot/mgrs/releasenotesmgr"
+	"istio.io/bots/policybot/pkg/cmdutil"
+	"istio.io/bots/policybot/pkg/config"
+	"istio.io/bots/policybot/pkg/gh"
+	"istio.io/bots/policybot/pkg/storage/spanner"
+)
+
+func releaseNotesCmd() *cobra.Command {
+	repo := ""
+	milestone := ""
+	branch := ""
+	output := ""
+
+	cmd, _ := cmdutil.Run("releasenotes", "Generate the release notes for a milestone", 0,
+		cmdutil.ConfigPath|cmdutil.ConfigRepo|cmdutil.GitHubToken, func(reg *config.Registry, secrets *cmdutil.Secrets) error {
+			return runReleaseNotes(reg, secrets, repo, milestone, branch, output)
+		})
+
+	cmd.PersistentFlags().StringVarP(&repo, "repo", "", "", "The org/repo to generate release notes for")
+	cmd.PersistentFlags().StringVarP(&milestone, "milestone", "", "", "The milestone to generate release notes for, e.g. 1.5")
+	cmd.PersistentFlags().StringVarP(&branch, "branch", "", "",
+		"The release branch whose merged PRs are included, defaults to release-<milestone>")
+	cmd.PersistentFlags().StringVarP(&output, "output", "o", "", "File to write the release notes to, defaults to stdout")
+
+	return cmd
+}
+
+func runReleaseNotes(reg *config.Registry, secrets *cmdutil.Secrets, repo string, milestone string, branch string, output string) error {
+	if repo == "" || milestone == "" {
+		return fmt.Errorf("both --repo and --milestone must be specified")
+	}
+
+	if branch == "" {
+		branch = "release-" + milestone
+	}
+
+	core := reg.Core()
+
+	store, err := spanner.NewStore(context.Background(), core.SpannerDatabase)
+	if err != nil {
+		return fmt.Errorf("unable to create storage layer: %v", err)
+	}
+	defer store.Close()
+
+	gc := gh.NewThrottledClient(context.Background(), secrets.GitHubToken)
+	mgr := releasenotesmgr.New(gc, store, reg)
+
+	report, err := mgr.Generate(context.Background(), gh.NewRepoDesc(repo), milestone, branch)
+	if err != nil {
+		return err
+	}
+
+	if output == "" {
+		fmt.Print(report.Markdown())
+	} else if err := os.WriteFile(output, []byte(report.Markdown()), 0644); err != nil {
+		return fmt.Errorf("unable to write release notes: %v", err)
+	}
+
+	for _, inv := range report.Invalid {
+		fmt.Fprintf(os.Stderr, "Invalid release note %s\n", inv)
+	}
+
+	if len(report.Missing) > 0 {
+		fmt.Fprintf(os.Stderr, "\nThe following merged PRs don't have a release note:\n\n")
+		for _, pr := range report.Missing {
+			fmt.Fprintf(os.Stderr, "  #%d %s (%s)\n", pr.PullRequestNumber, pr.Title, pr.Author)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,31 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"istio.io/bots/policybot/pkg/report"
+)
+
+// writeReport prints a manager's end-of-run report to stdout and passes along the run's error.
+func writeReport(rep *report.Report, err error) error {
+	if werr := rep.Write(os.Stdout); werr != nil && err == nil {
+		return fmt.Errorf("unable to write run report: %v", werr)
+	}
+
+	return err
+}
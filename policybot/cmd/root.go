@@ -42,6 +42,9 @@ func GetRootCmd(args []string) *cobra.Command {
 	rootCmd.AddCommand(milestoneMgrCmd())
 	rootCmd.AddCommand(userdataMgrCmd())
 	rootCmd.AddCommand(lifecycleMgrCmd())
+	rootCmd.AddCommand(rebaseMgrCmd())
+	rootCmd.AddCommand(rulesCmd())
+	rootCmd.AddCommand(releaseNotesCmd())
 	rootCmd.AddCommand(version.CobraCommand())
 
 	return rootCmd
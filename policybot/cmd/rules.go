@@ -0,0 +1,143 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+
+	"istio.io/bots/policybot/pkg/cmdutil"
+	"istio.io/bots/policybot/pkg/config"
+	"istio.io/bots/policybot/pkg/gh"
+	"istio.io/bots/policybot/pkg/rules"
+	"istio.io/bots/policybot/pkg/storage/spanner"
+)
+
+func rulesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rules",
+		Short: "Work with the rules used by auto labels, nags, and boilerplates",
+	}
+
+	cmd.AddCommand(rulesTestCmd())
+
+	return cmd
+}
+
+func rulesTestCmd() *cobra.Command {
+	repo := ""
+	number := 0
+	record := ""
+	ruleFile := ""
+
+	cmd, _ := cmdutil.Run("test", "Evaluate a rule against an issue or PR from storage", 0,
+		cmdutil.ConfigPath|cmdutil.ConfigRepo, func(reg *config.Registry, _ *cmdutil.Secrets) error {
+			return runRulesTest(reg, repo, number, record, ruleFile)
+		})
+
+	cmd.PersistentFlags().StringVarP(&repo, "repo", "", "", "The org/repo holding the issue or PR")
+	cmd.PersistentFlags().IntVarP(&number, "number", "", 0, "The number of the issue or PR")
+	cmd.PersistentFlags().StringVarP(&record, "record", "", "",
+		"The configuration record whose rule to evaluate, as <type>/<name>, with type one of ["+strings.Join(sortedSourceTypes(), ", ")+"]")
+	cmd.PersistentFlags().StringVarP(&ruleFile, "rule_file", "", "", "A YAML file holding the rule to evaluate, instead of a configuration record")
+
+	return cmd
+}
+
+func runRulesTest(reg *config.Registry, repo string, number int, record string, ruleFile string) error {
+	if repo == "" || number == 0 {
+		return fmt.Errorf("both --repo and --number must be specified")
+	}
+	rd := gh.NewRepoDesc(repo)
+
+	var rule *rules.Rule
+	switch {
+	case record != "" && ruleFile != "":
+		return fmt.Errorf("only one of --record and --rule_file can be specified")
+
+	case record != "":
+		splits := strings.SplitN(record, "/", 2)
+		if len(splits) != 2 {
+			return fmt.Errorf("invalid record %s, expecting <type>/<name>", record)
+		}
+
+		var ok bool
+		if rule, ok = rules.FindRule(reg, splits[0], rd.OrgAndRepo, splits[1]); !ok {
+			return fmt.Errorf("no record %s applies to repo %s", record, rd)
+		}
+
+	case ruleFile != "":
+		b, err := os.ReadFile(ruleFile)
+		if err != nil {
+			return fmt.Errorf("unable to read rule: %v", err)
+		}
+
+		rule = &rules.Rule{}
+		if err := yaml.UnmarshalStrict(b, rule); err != nil {
+			return fmt.Errorf("unable to parse rule: %v", err)
+		}
+
+	default:
+		return fmt.Errorf("one of --record or --rule_file must be specified")
+	}
+
+	expr, err := rules.Compile(rule)
+	if err != nil {
+		return err
+	}
+
+	store, err := spanner.NewStore(context.Background(), reg.Core().SpannerDatabase)
+	if err != nil {
+		return fmt.Errorf("unable to create storage layer: %v", err)
+	}
+	defer store.Close()
+
+	var subject *rules.Subject
+	if pr, err := store.ReadPullRequest(context.Background(), rd.OrgLogin, rd.RepoName, number); err != nil {
+		return err
+	} else if pr != nil {
+		subject = rules.PullRequestSubject(pr)
+		fmt.Printf("PR %d in repo %s: %s\n", number, rd, pr.Title)
+		fmt.Printf("NOTE: the author association and size of PRs aren't kept in storage, so predicates on them don't match\n\n")
+	} else if issue, err := store.ReadIssue(context.Background(), rd.OrgLogin, rd.RepoName, number); err != nil {
+		return err
+	} else if issue != nil {
+		subject = rules.IssueSubject(issue)
+		fmt.Printf("Issue %d in repo %s: %s\n\n", number, rd, issue.Title)
+	} else {
+		return fmt.Errorf("no issue or PR %d found in repo %s", number, rd)
+	}
+
+	fmt.Print(expr.Trace(subject))
+	if expr.Eval(subject) {
+		fmt.Printf("\nThe rule matches\n")
+	} else {
+		fmt.Printf("\nThe rule doesn't match\n")
+	}
+
+	return nil
+}
+
+func sortedSourceTypes() []string {
+	types := rules.SourceTypes()
+	sort.Strings(types)
+	return types
+}
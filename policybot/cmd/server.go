@@ -26,14 +26,21 @@ import (
 
 	"istio.io/bots/policybot/dashboard"
 	"istio.io/bots/policybot/handlers/githubwebhook"
+	"istio.io/bots/policybot/handlers/githubwebhook/cherrypicker"
 	"istio.io/bots/policybot/handlers/githubwebhook/cleaner"
+	"istio.io/bots/policybot/handlers/githubwebhook/commander"
+	"istio.io/bots/policybot/handlers/githubwebhook/holder"
 	"istio.io/bots/policybot/handlers/githubwebhook/labeler"
 	"istio.io/bots/policybot/handlers/githubwebhook/lifecycler"
 	"istio.io/bots/policybot/handlers/githubwebhook/nagger"
+	"istio.io/bots/policybot/handlers/githubwebhook/rebaser"
 	"istio.io/bots/policybot/handlers/githubwebhook/refresher"
+	"istio.io/bots/policybot/handlers/githubwebhook/releasenoter"
+	"istio.io/bots/policybot/handlers/githubwebhook/reportchecker"
 	"istio.io/bots/policybot/handlers/githubwebhook/watcher"
 	"istio.io/bots/policybot/handlers/githubwebhook/welcomer"
 	"istio.io/bots/policybot/mgrs/lifecyclemgr"
+	"istio.io/bots/policybot/mgrs/rebasemgr"
 	"istio.io/bots/policybot/pkg/blobstorage/gcs"
 	"istio.io/bots/policybot/pkg/cmdutil"
 	"istio.io/bots/policybot/pkg/config"
@@ -128,14 +135,19 @@ func runWithConfig(reg *config.Registry, secrets *cmdutil.Secrets, httpsOnly boo
 
 	c := cache.New(store, time.Duration(core.CacheTTL))
 	gc := gh.NewThrottledClient(context.Background(), secrets.GitHubToken)
-	lf := lifecyclemgr.New(gc, store, c, reg)
+	lf, err := lifecyclemgr.New(gc, store, c, reg)
+	if err != nil {
+		return fmt.Errorf("unable to create lifecycle manager: %v", err)
+	}
+
+	rm := rebasemgr.New(gc, reg)
 
 	nag, err := nagger.NewNagger(gc, c, reg)
 	if err != nil {
 		return fmt.Errorf("unable to create nagger: %v", err)
 	}
 
-	labeler, err := labeler.NewLabeler(gc, c, reg)
+	labeler, err := labeler.NewLabeler(gc, store, c, reg)
 	if err != nil {
 		return fmt.Errorf("unable to create labeler: %v", err)
 	}
@@ -145,6 +157,20 @@ func runWithConfig(reg *config.Registry, secrets *cmdutil.Secrets, httpsOnly boo
 		return fmt.Errorf("unable to create boilerplate cleaner: %v", err)
 	}
 
+	releaseNoter, err := releasenoter.New(gc, c, reg)
+	if err != nil {
+		return fmt.Errorf("unable to create release note checker: %v", err)
+	}
+
+	reportChecker, err := reportchecker.New(gc, reg)
+	if err != nil {
+		return fmt.Errorf("unable to create issue report checker: %v", err)
+	}
+
+	cmdr := commander.New(gc, c, reg)
+	cmdr.Register(lifecycler.Commands(lf)...)
+	cmdr.Register(holder.Commands(gc, reg)...)
+
 	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", core.ServerPort))
 	if err != nil {
 		return fmt.Errorf("unable to listen to port: %v", err)
@@ -171,7 +197,13 @@ func runWithConfig(reg *config.Registry, secrets *cmdutil.Secrets, httpsOnly boo
 		lifecycler.New(gc, reg, lf, c),
 		labeler,
 		cleaner,
-		welcomer.NewWelcomer(gc, store, c, reg),
+		reportChecker,
+		welcomer.NewWelcomer(gc, store, reg),
+		cmdr,
+		cherrypicker.New(gc, store, reg),
+		releaseNoter,
+		rebaser.New(reg, rm),
+		holder.New(gc, reg),
 		watcher.NewRepoWatcher(reg.OriginRepo(), reg.OriginPath(), s.Close),
 	}
 
@@ -186,7 +218,7 @@ func runWithConfig(reg *config.Registry, secrets *cmdutil.Secrets, httpsOnly boo
 	router.Handle("/githubwebhook", githubwebhook.NewHandler(secrets.GitHubWebhookSecret, filters...)).Methods("POST")
 
 	// prep the UI
-	_ = dashboard.New(router, store, c, reg, secrets)
+	_ = dashboard.New(router, store, c, lf, reg, secrets)
 
 	log.Infof("Listening on port %d", core.ServerPort)
 
@@ -41,6 +41,7 @@ import (
 	"istio.io/bots/policybot/dashboard/topics/webanalytics"
 	"istio.io/bots/policybot/dashboard/topics/workinggroups"
 	"istio.io/bots/policybot/dashboard/types"
+	"istio.io/bots/policybot/mgrs/lifecyclemgr"
 	"istio.io/bots/policybot/pkg/cmdutil"
 	"istio.io/bots/policybot/pkg/config"
 	"istio.io/bots/policybot/pkg/storage"
@@ -72,7 +73,8 @@ type templateInfo struct {
 
 var scope = log.RegisterScope("dashboard", "The UI layer")
 
-func New(router *mux.Router, store storage.Store, cache *cache.Cache, reg *config.Registry, secrets *cmdutil.Secrets) *Dashboard {
+func New(router *mux.Router, store storage.Store, cache *cache.Cache, lifecycle *lifecyclemgr.LifecycleMgr, reg *config.Registry,
+	secrets *cmdutil.Secrets) *Dashboard {
 	d := &Dashboard{
 		primaryTemplates: template.Must(template.New("base").Parse(layout.BaseTemplate)),
 		errorTemplates:   template.Must(template.New("base").Parse(layout.BaseTemplate)),
@@ -121,12 +123,12 @@ func New(router *mux.Router, store storage.Store, cache *cache.Cache, reg *confi
 	// topics
 	maintainers := maintainers.New(store, cache, time.Duration(core.CacheTTL), time.Duration(core.MaintainerActivityWindow), core.DefaultOrg)
 	members := members.New(store, cache, time.Duration(core.CacheTTL), time.Duration(core.MemberActivityWindow), core.DefaultOrg, reg)
-	issues := issues.New(store, cache, core.DefaultOrg)
-	pullRequests := pullrequests.New(store, cache)
+	issues := issues.New(store, cache, lifecycle, core.DefaultOrg)
+	pullRequests := pullrequests.New(store, cache, core.DefaultOrg)
 	postSubmit := postsubmit.New(store, cache, router)
 	perf := perf.New(store, cache)
 	commitHub := commithub.New(store, cache)
-	flakes := flakes.New(store, cache)
+	flakes := flakes.New(store, cache, core.DefaultOrg, reg)
 	webanalytics := webanalytics.New(store, cache)
 	coverage := coverage.New(store, cache)
 	features := features.New(store, cache)
@@ -152,6 +154,9 @@ func New(router *mux.Router, store storage.Store, cache *cache.Cache, reg *confi
 		addEntry("Recently Inactive", "Members that have not recently contributed to the project.").
 		addPageWithQuery("/members", "filter", "inactive", members.RenderList).
 		endEntry().
+		addEntry("Mentors", "Mentors assigned to the PRs of new contributors, and how quickly they respond.").
+		addPage("/members/mentors", members.RenderMentors).
+		endEntry().
 		addPage("/members", members.RenderList).
 		addPage("/members/{login}", members.RenderSingle).
 		endEntry()
@@ -176,6 +181,15 @@ func New(router *mux.Router, store storage.Store, cache *cache.Cache, reg *confi
 		addEntry("Stale Issues", "List of all open issues marked stale").
 		addPageWithQuery("/issues", "option", "stale", issues.RenderStale).
 		endEntry().
+		addEntry("Issues By Field", "Counts of open issues by the values reported in issue forms, such as the version").
+		addPageWithQuery("/issues", "option", "fields", issues.RenderFields).
+		endEntry().
+		addEntry("Lifecycle Forecast", "Open issues and PRs the lifecycle manager will mark stale or close in the coming days").
+		addPageWithQuery("/issues", "option", "forecast", issues.RenderForecast).
+		endEntry().
+		addEntry("Lifecycle Timeline", "Changes the lifecycle manager made to issues and PRs, and why").
+		addPageWithQuery("/issues", "option", "timeline", issues.RenderTimeline).
+		endEntry().
 		addPage("/issues", issues.RenderSummary).
 		endEntry()
 
@@ -191,6 +205,9 @@ func New(router *mux.Router, store storage.Store, cache *cache.Cache, reg *confi
 		endEntry()
 
 	d.addEntry("Pull Requests", "Information on new and old pull requests.").
+		addEntry("Backports", "Status of automated cherry-picks to release branches").
+		addPageWithQuery("/prs", "option", "backports", pullRequests.RenderBackports).
+		endEntry().
 		addPage("/prs", pullRequests.Render).
 		endEntry()
 
@@ -207,6 +224,9 @@ func New(router *mux.Router, store storage.Store, cache *cache.Cache, reg *confi
 		endEntry()
 
 	d.addEntry("Test Flakes", "Discover the wonderful world of test flakes.").
+		addPageWithQuery("/flakes", "option", "runs", flakes.RenderRuns).
+		addPageWithQuery("/flakes", "option", "clusters", flakes.RenderClusters).
+		addPageWithQuery("/flakes", "option", "case", flakes.RenderCase).
 		addPage("/flakes", flakes.Render).
 		endEntry()
 
@@ -1,6 +1,9 @@
 // Code generated for package flakes by go-bindata DO NOT EDIT. (@generated)
 // sources:
+// cases.html
+// clusters.html
 // page.html
+// runs.html
 package flakes
 
 import (
@@ -54,21 +57,208 @@ func (fi bindataFileInfo) Sys() interface{} {
 	return nil
 }
 
-var _pageHtml = []byte(`<aside class="callout warning">
-    <div class="type">
-        <svg class="large-icon">
-            <use xlink:href="/icons/icons.svg#callout-warning">
-            </use>
-        </svg>
-    </div>
-    <div class="content">
-        This page is under construction
-    </div>
-</aside>
+var _casesHtml = []byte(`<p>
+    <a href="/flakes?repo={{ .RepoName | urlquery }}">Back to the flakiest tests in {{ .RepoName }}</a>
+</p>
+
+<p>
+    Runs of {{ .CaseName }} in {{ .PackageName }} across all jobs, as reported by the jobs' JUnit XML artifacts.
+    Skipped runs don't count towards the durations.
+</p>
+
+{{ if .Durations.Runs }}
+<table>
+  <caption>Duration Of {{ .CaseName }} Over The Last {{ .Days }} Days</caption>
+  <thead>
+  <tr>
+      <th>Runs</th>
+      <th>Mean</th>
+      <th>Median</th>
+      <th>90th Percentile</th>
+      <th>Max</th>
+  </tr>
+  </thead>
+  <tbody>
+      <tr>
+          <td>{{ .Durations.Runs }}</td>
+          <td>{{ printf "%.2f" .Durations.Mean }}s</td>
+          <td>{{ printf "%.2f" .Durations.Median }}s</td>
+          <td>{{ printf "%.2f" .Durations.P90 }}s</td>
+          <td>{{ printf "%.2f" .Durations.Max }}s</td>
+      </tr>
+  </tbody>
+</table>
+{{ end }}
+
+<table>
+  <caption>Runs Of {{ .CaseName }} Over The Last {{ .Days }} Days</caption>
+  <thead>
+  <tr>
+      <th>Finished</th>
+      <th>Job</th>
+      <th>Pull Request</th>
+      <th>Run</th>
+      <th>Status</th>
+      <th>Duration</th>
+      <th>Failure</th>
+  </tr>
+  </thead>
+  <tbody>
+      {{ range .Runs }}
+          <tr>
+              <td>{{ .FinishTime.Format "02-Jan-2006 15:04" }}</td>
+              <td><a href="/flakes?option=runs&repo={{ .RepoName | urlquery }}&test={{ .TestName | urlquery }}">{{ .TestName }}</a></td>
+              <td><a href="https://github.com/{{ .OrgLogin }}/{{ .RepoName }}/pull/{{ .PullRequestNumber }}">{{ .PullRequestNumber }}</a></td>
+              <td>{{ .RunNumber }}</td>
+              <td>{{ .Status }}</td>
+              <td>{{ printf "%.2f" .Duration }}s</td>
+              <td>{{ with .FailureMessage }}<pre>{{ . }}</pre>{{ end }}</td>
+          </tr>
+      {{ else }}
+          <tr><td colspan="7">No runs of this test case over the last {{ $.Days }} days.</td></tr>
+      {{ end }}
+  </tbody>
+</table>
+`)
+
+func casesHtmlBytes() ([]byte, error) {
+	return _casesHtml, nil
+}
+
+func casesHtml() (*asset, error) {
+	bytes, err := casesHtmlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "cases.html", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _clustersHtml = []byte(`<p>
+    <a href="/flakes">Back to the flakiest tests</a>
+</p>
+
+<p>
+    Failed runs that no failure signature recognizes are grouped by the fingerprint of their normalized
+    output, so the same failure seen on different pull requests lands in the same cluster. To promote a
+    cluster to a named signature, pick a name and a category, check the suggested pattern, and add the
+    record to the <code>config/failuresignatures</code> directory. Promoted clusters stop growing once
+    the signature is deployed.
+</p>
+
+<table>
+  <caption>Top Unrecognized Failures Over The Last {{ .Days }} Days</caption>
+  <thead>
+  <tr>
+      <th>Fingerprint</th>
+      <th>Runs</th>
+      <th>PRs Hit</th>
+      <th>Tests</th>
+      <th>First Seen</th>
+      <th>Last Seen</th>
+      <th>Excerpt</th>
+      <th>Signature</th>
+  </tr>
+  </thead>
+  <tbody>
+      {{ range .Clusters }}
+          <tr>
+              <td><code>{{ .Fingerprint }}</code></td>
+              <td>{{ .Runs }}</td>
+              <td>{{ .PullRequests }}</td>
+              <td>{{ .Tests }}</td>
+              <td>{{ .FirstSeen.Format "02-Jan-2006" }}</td>
+              <td>{{ .LastSeen.Format "02-Jan-2006" }}</td>
+              <td><pre>{{ .Excerpt }}</pre></td>
+              <td>
+                  {{ with .Matching }}
+                      The excerpt matches {{ range $i, $s := . }}{{ if $i }}, {{ end }}<code>{{ $s }}</code>{{ end }}.
+                      The cluster is promoted once new runs stop landing in it.
+                  {{ else }}
+<pre>name: &lt;name&gt;
+type: failuresignature
+category: &lt;infra|timeout|test-failure|build-failure&gt;
+description: &lt;what went wrong&gt;
+patterns:
+  - '{{ .Pattern | yamlquote }}'</pre>
+                  {{ end }}
+              </td>
+          </tr>
+      {{ else }}
+          <tr><td colspan="8">No unrecognized failures over the last {{ $.Days }} days.</td></tr>
+      {{ end }}
+  </tbody>
+</table>
+`)
+
+func clustersHtmlBytes() ([]byte, error) {
+	return _clustersHtml, nil
+}
+
+func clustersHtml() (*asset, error) {
+	bytes, err := clustersHtmlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "clusters.html", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _pageHtml = []byte(`<form method="get" action="/flakes">
+    <input type="hidden" name="sort" value="{{ .SortBy }}">
+    <label for="repo">Repository</label>
+    <input type="text" id="repo" name="repo" value="{{ .RepoName }}">
+    <input type="submit" value="Show">
+</form>
+
+<p>
+    The flake rate is the fraction of a test's pre-submit runs that failed and then passed on a retry at the
+    same commit. The trend compares the last {{ .ShortDays }} days against the last {{ .LongDays }} days, so a
+    positive trend means the test has been getting flakier. Click on a column heading to sort by it.
+</p>
 
 <p>
-    <iframe height="750" width="1000" src="https://datastudio.google.com/embed/reporting/80ea1cb5-c64d-4ba1-a727-5e53e1c2e957/page/OQoAB" frameborder="0" style="border:0" allowfullscreen=""></iframe>
+    <a href="/flakes?option=clusters">See the failures no signature recognizes</a>
 </p>
+
+{{ $repo := .RepoName }}
+<table>
+  <caption>Tests That Flaked Over The Last {{ .LongDays }} Days</caption>
+  <thead>
+  <tr>
+      <th><a href="/flakes?repo={{ $repo | urlquery }}&sort=test">Test</a></th>
+      <th>Repository</th>
+      <th><a href="/flakes?repo={{ $repo | urlquery }}&sort=rate7">Flake Rate ({{ .ShortDays }}d)</a></th>
+      <th><a href="/flakes?repo={{ $repo | urlquery }}&sort=rate30">Flake Rate ({{ .LongDays }}d)</a></th>
+      <th><a href="/flakes?repo={{ $repo | urlquery }}&sort=trend">Trend</a></th>
+      <th><a href="/flakes?repo={{ $repo | urlquery }}&sort=prs7">PRs Hit ({{ .ShortDays }}d)</a></th>
+      <th><a href="/flakes?repo={{ $repo | urlquery }}&sort=prs30">PRs Hit ({{ .LongDays }}d)</a></th>
+      <th><a href="/flakes?repo={{ $repo | urlquery }}&sort=runs">Runs ({{ .LongDays }}d)</a></th>
+      <th>Failures ({{ .LongDays }}d)</th>
+  </tr>
+  </thead>
+  <tbody>
+      {{ range .Scores }}
+          <tr>
+              <td><a href="/flakes?option=runs&repo={{ .RepoName | urlquery }}&test={{ .TestName | urlquery }}">{{ .TestName }}</a></td>
+              <td>{{ .RepoName }}</td>
+              <td>{{ printf "%.1f%%" (percent .Short.Rate) }}</td>
+              <td>{{ printf "%.1f%%" (percent .Long.Rate) }}</td>
+              <td>{{ printf "%+.1f%%" (percent .Trend) }}</td>
+              <td>{{ .Short.PullRequests }}</td>
+              <td>{{ .Long.PullRequests }}</td>
+              <td>{{ .Long.Runs }}</td>
+              <td>{{ .Long.Failures }}</td>
+          </tr>
+      {{ else }}
+          <tr><td colspan="9">No test flaked over the last {{ $.LongDays }} days.</td></tr>
+      {{ end }}
+  </tbody>
+</table>
 `)
 
 func pageHtmlBytes() ([]byte, error) {
@@ -86,6 +276,87 @@ func pageHtml() (*asset, error) {
 	return a, nil
 }
 
+var _runsHtml = []byte(`<p>
+    <a href="/flakes?repo={{ .RepoName | urlquery }}">Back to the flakiest tests in {{ .RepoName }}</a>
+</p>
+
+{{ $repo := .RepoName }}
+<table>
+  <caption>Test Cases Of {{ .TestName }} That Flaked Over The Last {{ .Days }} Days</caption>
+  <thead>
+  <tr>
+      <th>Test Case</th>
+      <th>Package</th>
+      <th>Flaky Failures</th>
+      <th>Failures</th>
+      <th>Runs</th>
+      <th>PRs Hit</th>
+      <th>Mean Duration</th>
+      <th>Last Flaked</th>
+  </tr>
+  </thead>
+  <tbody>
+      {{ range .Cases }}
+          <tr>
+              <td><a href="/flakes?option=case&repo={{ $repo | urlquery }}&package={{ .PackageName | urlquery }}&case={{ .CaseName | urlquery }}">{{ .CaseName }}</a></td>
+              <td>{{ .PackageName }}</td>
+              <td>{{ .FlakyFailures }}</td>
+              <td>{{ .Failures }}</td>
+              <td>{{ .Runs }}</td>
+              <td>{{ .PullRequests }}</td>
+              <td>{{ printf "%.2f" .MeanDuration }}s</td>
+              <td>{{ .LastFlaked.Format "02-Jan-2006 15:04" }}</td>
+          </tr>
+      {{ else }}
+          <tr><td colspan="8">No test case reports show a flake over the last {{ $.Days }} days.</td></tr>
+      {{ end }}
+  </tbody>
+</table>
+
+<table>
+  <caption>Failed Runs Of {{ .TestName }} In {{ .RepoName }} Over The Last {{ .Days }} Days</caption>
+  <thead>
+  <tr>
+      <th>Finished</th>
+      <th>Pull Request</th>
+      <th>Run</th>
+      <th>Result</th>
+      <th>Passed On Retry</th>
+      <th>Signatures</th>
+  </tr>
+  </thead>
+  <tbody>
+      {{ range .Failures }}
+          <tr>
+              <td>{{ .FinishTime.Format "02-Jan-2006 15:04" }}</td>
+              <td><a href="https://github.com/{{ .OrgLogin }}/{{ .RepoName }}/pull/{{ .PullRequestNumber }}">{{ .PullRequestNumber }}</a></td>
+              <td><a href="https://prow.istio.io/view/gcs/istio-prow/{{ .RunPath }}">{{ .RunNumber }}</a></td>
+              <td>{{ .Result }}</td>
+              <td>{{ with .PassingRunNumber }}run {{ . }}{{ else }}no{{ end }}</td>
+              <td>{{ range $i, $s := .Signatures }}{{ if $i }}, {{ end }}{{ $s }}{{ end }}</td>
+          </tr>
+      {{ else }}
+          <tr><td colspan="6">No failed runs of this test over the last {{ $.Days }} days.</td></tr>
+      {{ end }}
+  </tbody>
+</table>
+`)
+
+func runsHtmlBytes() ([]byte, error) {
+	return _runsHtml, nil
+}
+
+func runsHtml() (*asset, error) {
+	bytes, err := runsHtmlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "runs.html", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
 // Asset loads and returns the asset for the given name.
 // It returns an error if the asset could not be found or
 // could not be loaded.
@@ -138,7 +409,10 @@ func AssetNames() []string {
 
 // _bindata is a table, holding each asset generator, mapped to its name.
 var _bindata = map[string]func() (*asset, error){
-	"page.html": pageHtml,
+	"cases.html":    casesHtml,
+	"clusters.html": clustersHtml,
+	"page.html":     pageHtml,
+	"runs.html":     runsHtml,
 }
 
 // AssetDir returns the file names below a certain
@@ -182,7 +456,10 @@ type bintree struct {
 }
 
 var _bintree = &bintree{nil, map[string]*bintree{
-	"page.html": {pageHtml, map[string]*bintree{}},
+	"cases.html":    {casesHtml, map[string]*bintree{}},
+	"clusters.html": {clustersHtml, map[string]*bintree{}},
+	"page.html":     {pageHtml, map[string]*bintree{}},
+	"runs.html":     {runsHtml, map[string]*bintree{}},
 }}
 
 // RestoreAsset restores an asset under the given directory
@@ -17,32 +17,263 @@
 package flakes
 
 import (
+	"html/template"
 	"net/http"
+	"strings"
+	"time"
 
 	"istio.io/bots/policybot/dashboard/types"
+	"istio.io/bots/policybot/pkg/config"
+	"istio.io/bots/policybot/pkg/resultgatherer"
+	"istio.io/bots/policybot/pkg/signatures"
 	"istio.io/bots/policybot/pkg/storage"
 	"istio.io/bots/policybot/pkg/storage/cache"
+	"istio.io/bots/policybot/pkg/testflakes"
+	"istio.io/bots/policybot/pkg/util"
 )
 
 // Flakes lets users visualize the set of outstanding test flakes in the project.
 type Flakes struct {
-	store storage.Store
-	cache *cache.Cache
-	page  string
+	store      storage.Store
+	cache      *cache.Cache
+	page       *template.Template
+	runs       *template.Template
+	clusters   *template.Template
+	cases      *template.Template
+	defaultOrg string
+	reg        *config.Registry
+}
+
+type scoresInfo struct {
+	RepoName  string
+	SortBy    string
+	ShortDays int
+	LongDays  int
+	Scores    []*testflakes.FlakeScore
+}
+
+type runsInfo struct {
+	RepoName string
+	TestName string
+	Days     int
+	Failures []*storage.TestFailure
+	Cases    []*storage.TestCaseFlake
+}
+
+type caseInfo struct {
+	RepoName    string
+	PackageName string
+	CaseName    string
+	Days        int
+	Durations   testflakes.Durations
+	Runs        []*storage.TestCaseResult
+}
+
+type clustersInfo struct {
+	Days     int
+	Clusters []*clusterInfo
+}
+
+type clusterInfo struct {
+	*storage.FailureCluster
+	Matching []string // the signatures whose patterns match the cluster's excerpt, a hint rather than proof of promotion
+	Pattern  string   // the pattern suggested for a new signature
+}
+
+// the number of clusters shown on the clusters page
+const maxClusters = 50
+
+var funcs = template.FuncMap{
+	"percent":   func(f float64) float64 { return f * 100 },
+	"yamlquote": func(s string) string { return strings.ReplaceAll(s, "'", "''") },
 }
 
 // New creates a new Flakes instance.
-func New(store storage.Store, cache *cache.Cache) *Flakes {
+func New(store storage.Store, cache *cache.Cache, defaultOrg string, reg *config.Registry) *Flakes {
 	return &Flakes{
-		store: store,
-		cache: cache,
-		page:  string(MustAsset("page.html")),
+		store:      store,
+		cache:      cache,
+		page:       template.Must(template.New("page").Funcs(funcs).Parse(string(MustAsset("page.html")))),
+		runs:       template.Must(template.New("runs").Parse(string(MustAsset("runs.html")))),
+		clusters:   template.Must(template.New("clusters").Funcs(funcs).Parse(string(MustAsset("clusters.html")))),
+		cases:      template.Must(template.New("cases").Parse(string(MustAsset("cases.html")))),
+		defaultOrg: defaultOrg,
+		reg:        reg,
 	}
 }
 
-// Renders the HTML for this topic.
+// Renders the HTML for this topic, a table of the tests that flaked recently, flakiest first.
 func (f *Flakes) Render(req *http.Request) (types.RenderInfo, error) {
+	orgLogin := req.URL.Query().Get("org")
+	if orgLogin == "" {
+		orgLogin = f.defaultOrg
+	}
+
+	si := scoresInfo{
+		RepoName:  req.URL.Query().Get("repo"),
+		SortBy:    req.URL.Query().Get("sort"),
+		ShortDays: testflakes.ShortWindowDays,
+		LongDays:  testflakes.LongWindowDays,
+	}
+
+	if si.SortBy == "" {
+		si.SortBy = testflakes.ByShortRate
+	}
+
+	now := time.Now()
+	var days []*storage.TestFlakeDay
+	if err := f.store.QueryTestFlakeDays(req.Context(), orgLogin, now.AddDate(0, 0, -testflakes.LongWindowDays), func(day *storage.TestFlakeDay) error {
+		if si.RepoName == "" || day.RepoName == si.RepoName {
+			days = append(days, day)
+		}
+		return nil
+	}); err != nil {
+		return types.RenderInfo{}, util.HTTPErrorf(http.StatusInternalServerError, "unable to read test flake history: %v", err)
+	}
+
+	// tests that never flaked over the long window have nothing to show
+	for _, score := range testflakes.ScoreFlakes(days, now) {
+		if score.Long.FlakyFailures > 0 {
+			si.Scores = append(si.Scores, score)
+		}
+	}
+	testflakes.SortScores(si.Scores, si.SortBy)
+
+	var sb strings.Builder
+	if err := f.page.Execute(&sb, si); err != nil {
+		return types.RenderInfo{}, err
+	}
+
+	return types.RenderInfo{
+		Content: sb.String(),
+	}, nil
+}
+
+// RenderRuns drills down into the recent failed runs of a single test.
+func (f *Flakes) RenderRuns(req *http.Request) (types.RenderInfo, error) {
+	orgLogin := req.URL.Query().Get("org")
+	if orgLogin == "" {
+		orgLogin = f.defaultOrg
+	}
+
+	ri := runsInfo{
+		RepoName: req.URL.Query().Get("repo"),
+		TestName: req.URL.Query().Get("test"),
+		Days:     testflakes.LongWindowDays,
+	}
+
+	if ri.RepoName == "" || ri.TestName == "" {
+		return types.RenderInfo{}, util.HTTPErrorf(http.StatusBadRequest, "both a repo and a test must be specified")
+	}
+
+	if err := f.store.QueryTestFailures(req.Context(), orgLogin, ri.RepoName, ri.TestName, time.Now().AddDate(0, 0, -ri.Days),
+		func(failure *storage.TestFailure) error {
+			ri.Failures = append(ri.Failures, failure)
+			return nil
+		}); err != nil {
+		return types.RenderInfo{}, util.HTTPErrorf(http.StatusInternalServerError, "unable to read failed runs of test %s: %v", ri.TestName, err)
+	}
+
+	if err := f.store.QueryTestCaseFlakes(req.Context(), orgLogin, ri.RepoName, ri.TestName, time.Now().AddDate(0, 0, -ri.Days),
+		func(flake *storage.TestCaseFlake) error {
+			ri.Cases = append(ri.Cases, flake)
+			return nil
+		}); err != nil {
+		return types.RenderInfo{}, util.HTTPErrorf(http.StatusInternalServerError, "unable to read flaky test cases of test %s: %v", ri.TestName, err)
+	}
+
+	var sb strings.Builder
+	if err := f.runs.Execute(&sb, ri); err != nil {
+		return types.RenderInfo{}, err
+	}
+
+	return types.RenderInfo{
+		Content: sb.String(),
+	}, nil
+}
+
+// RenderCase shows the recent runs of a single test case across jobs, along with how long it has been taking.
+func (f *Flakes) RenderCase(req *http.Request) (types.RenderInfo, error) {
+	orgLogin := req.URL.Query().Get("org")
+	if orgLogin == "" {
+		orgLogin = f.defaultOrg
+	}
+
+	ci := caseInfo{
+		RepoName:    req.URL.Query().Get("repo"),
+		PackageName: req.URL.Query().Get("package"),
+		CaseName:    req.URL.Query().Get("case"),
+		Days:        testflakes.LongWindowDays,
+	}
+
+	if ci.RepoName == "" || ci.CaseName == "" {
+		return types.RenderInfo{}, util.HTTPErrorf(http.StatusBadRequest, "both a repo and a test case must be specified")
+	}
+
+	var durations []float64
+	if err := f.store.QueryTestCaseHistory(req.Context(), orgLogin, ci.RepoName, ci.PackageName, ci.CaseName, time.Now().AddDate(0, 0, -ci.Days),
+		func(result *storage.TestCaseResult) error {
+			ci.Runs = append(ci.Runs, result)
+			if result.Status != resultgatherer.CaseSkipped {
+				durations = append(durations, result.Duration)
+			}
+			return nil
+		}); err != nil {
+		return types.RenderInfo{}, util.HTTPErrorf(http.StatusInternalServerError, "unable to read runs of test case %s: %v", ci.CaseName, err)
+	}
+	ci.Durations = testflakes.SummarizeDurations(durations)
+
+	var sb strings.Builder
+	if err := f.cases.Execute(&sb, ci); err != nil {
+		return types.RenderInfo{}, err
+	}
+
+	return types.RenderInfo{
+		Content: sb.String(),
+	}, nil
+}
+
+// RenderClusters shows the failures no signature recognizes, grouped by fingerprint, largest first.
+func (f *Flakes) RenderClusters(req *http.Request) (types.RenderInfo, error) {
+	orgLogin := req.URL.Query().Get("org")
+	if orgLogin == "" {
+		orgLogin = f.defaultOrg
+	}
+
+	sigs, err := signatures.All(f.reg)
+	if err != nil {
+		return types.RenderInfo{}, util.HTTPErrorf(http.StatusInternalServerError, "unable to load failure signatures: %v", err)
+	}
+
+	ci := clustersInfo{
+		Days: testflakes.LongWindowDays,
+	}
+
+	if err := f.store.QueryFailureClusters(req.Context(), orgLogin, time.Now().AddDate(0, 0, -ci.Days), maxClusters,
+		func(cluster *storage.FailureCluster) error {
+			// only the normalized excerpt of a cluster is kept, so matching it against the signatures can't tell
+			// whether they recognize the raw output of the cluster's runs, only that one is likely to
+			output := make(map[string][]byte)
+			for _, file := range signatures.Files(sigs) {
+				output[file] = []byte(cluster.Excerpt)
+			}
+
+			ci.Clusters = append(ci.Clusters, &clusterInfo{
+				FailureCluster: cluster,
+				Matching:       signatures.Match(sigs, output),
+				Pattern:        signatures.PromotionPattern(cluster.Excerpt),
+			})
+			return nil
+		}); err != nil {
+		return types.RenderInfo{}, util.HTTPErrorf(http.StatusInternalServerError, "unable to read failure clusters: %v", err)
+	}
+
+	var sb strings.Builder
+	if err := f.clusters.Execute(&sb, ci); err != nil {
+		return types.RenderInfo{}, err
+	}
+
 	return types.RenderInfo{
-		Content: f.page,
+		Content: sb.String(),
 	}, nil
 }
@@ -1,7 +1,10 @@
 // Code generated for package issues by go-bindata DO NOT EDIT. (@generated)
 // sources:
+// fields.html
+// forecast.html
 // list.html
 // summary.html
+// timeline.html
 package issues
 
 import (
@@ -55,6 +58,119 @@ func (fi bindataFileInfo) Sys() interface{} {
 	return nil
 }
 
+var _fieldsHtml = []byte(`<form method="get" action="/issues">
+    <input type="hidden" name="option" value="fields">
+    <label for="field">Field</label>
+    <input type="text" id="field" name="field" value="{{ .FieldName }}">
+    <input type="submit" value="Show">
+</form>
+
+<table>
+  <caption>Open Issues By {{ .FieldName }}</caption>
+  <thead>
+  <tr>
+      <th>Value</th>
+      <th>Count</th>
+  </tr>
+  </thead>
+  <tbody>
+      {{ $field := .FieldName }}
+      {{ range .Values }}
+          <tr>
+              <td><a href="/issues?option=list&field={{ $field | urlquery }}&value={{ .Value | urlquery }}">{{ .Value }}</a></td>
+              <td>{{ .Count }}</td>
+          </tr>
+      {{ end }}
+  </tbody>
+</table>
+`)
+
+func fieldsHtmlBytes() ([]byte, error) {
+	return _fieldsHtml, nil
+}
+
+func fieldsHtml() (*asset, error) {
+	bytes, err := fieldsHtmlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "fields.html", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _forecastHtml = []byte(`<form method="get" action="/issues">
+    <input type="hidden" name="option" value="forecast">
+    <label for="days">Days</label>
+    <input type="number" id="days" name="days" min="0" max="90" value="{{ .Days }}">
+    <input type="submit" value="Forecast">
+</form>
+
+<table>
+  <caption>Expected Lifecycle Actions By Repository And Label</caption>
+  <thead>
+  <tr>
+      <th>Repository</th>
+      <th>Label</th>
+      <th>Marked Stale</th>
+      <th>Closed</th>
+  </tr>
+  </thead>
+  <tbody>
+      {{ range .Breakdown }}
+          <tr>
+              <td>{{ .RepoName }}</td>
+              <td>{{ .Label }}</td>
+              <td>{{ .Stale }}</td>
+              <td>{{ .Closed }}</td>
+          </tr>
+      {{ end }}
+  </tbody>
+</table>
+
+<table>
+  <caption>Expected Lifecycle Actions Over The Next {{ .Days }} Days</caption>
+  <thead>
+  <tr>
+      <th>Date</th>
+      <th>Repository</th>
+      <th>Number</th>
+      <th>Title</th>
+      <th>Action</th>
+      <th>Policy</th>
+  </tr>
+  </thead>
+  <tbody>
+      {{ range .Events }}
+          <tr>
+              <td>{{ .Date.Format "02-Jan-2006" }}</td>
+              <td>{{ .RepoName }}</td>
+              <td><a href="https://github.com/{{ .OrgLogin }}/{{ .RepoName }}/issues/{{ .Number }}">{{ .Number }}</a></td>
+              <td>{{ .Title }}</td>
+              <td>{{ if eq .Action "close" }}Closed{{ else }}Marked stale{{ end }}</td>
+              <td>{{ .Policy }}</td>
+          </tr>
+      {{ end }}
+  </tbody>
+</table>
+`)
+
+func forecastHtmlBytes() ([]byte, error) {
+	return _forecastHtml, nil
+}
+
+func forecastHtml() (*asset, error) {
+	bytes, err := forecastHtmlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "forecast.html", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
 var _listHtml = []byte(`<aside class="callout warning">
   <div class="type">
       <svg class="large-icon">
@@ -87,7 +203,7 @@ var _listHtml = []byte(`<aside class="callout warning">
 {{ end }}
 
 <table>
-  <caption>{{ .Title }}</caption>
+  <caption>{{ .Title }}{{ with .Filter }} with {{ .FieldName }} matching "{{ .Value }}"{{ end }}</caption>
   <thead>
   <tr>
       <th>Repository</th>
@@ -178,6 +294,68 @@ func summaryHtml() (*asset, error) {
 	return a, nil
 }
 
+var _timelineHtml = []byte(`<form method="get" action="/issues">
+    <input type="hidden" name="option" value="timeline">
+    <label for="repo">Repository</label>
+    <input type="text" id="repo" name="repo" value="{{ .RepoName }}">
+    <label for="number">Issue or PR</label>
+    <input type="number" id="number" name="number" min="0" value="{{ if .IssueNumber }}{{ .IssueNumber }}{{ end }}">
+    <input type="submit" value="Show">
+</form>
+
+{{ if .RepoName }}
+<table>
+  <caption>
+      {{ if .IssueNumber }}
+          Lifecycle Changes To {{ .RepoName }}#{{ .IssueNumber }}
+      {{ else }}
+          Lifecycle Changes In {{ .RepoName }} Over The Last {{ .Days }} Days
+      {{ end }}
+  </caption>
+  <thead>
+  <tr>
+      <th>Date</th>
+      {{ if not .IssueNumber }}<th>Number</th>{{ end }}
+      <th>Change</th>
+      <th>Policy</th>
+      <th>Reason</th>
+      <th>Clock Started</th>
+      <th>Next Step Due</th>
+  </tr>
+  </thead>
+  <tbody>
+      {{ $single := .IssueNumber }}
+      {{ range .Events }}
+          <tr>
+              <td>{{ .RecordedAt.Format "02-Jan-2006 15:04" }}</td>
+              {{ if not $single }}<td><a href="/issues?option=timeline&repo={{ .RepoName | urlquery }}&number={{ .IssueNumber }}">{{ .IssueNumber }}</a></td>{{ end }}
+              <td>{{ .Transition }}</td>
+              <td>{{ .Policy }}</td>
+              <td>{{ .Reason }}</td>
+              <td>{{ .ClockStart.Format "02-Jan-2006" }}</td>
+              <td>{{ with .Deadline }}{{ .Format "02-Jan-2006" }}{{ end }}</td>
+          </tr>
+      {{ end }}
+  </tbody>
+</table>
+{{ end }}
+`)
+
+func timelineHtmlBytes() ([]byte, error) {
+	return _timelineHtml, nil
+}
+
+func timelineHtml() (*asset, error) {
+	bytes, err := timelineHtmlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "timeline.html", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
 // Asset loads and returns the asset for the given name.
 // It returns an error if the asset could not be found or
 // could not be loaded.
@@ -230,8 +408,11 @@ func AssetNames() []string {
 
 // _bindata is a table, holding each asset generator, mapped to its name.
 var _bindata = map[string]func() (*asset, error){
-	"list.html":    listHtml,
-	"summary.html": summaryHtml,
+	"fields.html":   fieldsHtml,
+	"forecast.html": forecastHtml,
+	"list.html":     listHtml,
+	"summary.html":  summaryHtml,
+	"timeline.html": timelineHtml,
 }
 
 // AssetDir returns the file names below a certain
@@ -275,8 +456,11 @@ type bintree struct {
 }
 
 var _bintree = &bintree{nil, map[string]*bintree{
-	"list.html":    {listHtml, map[string]*bintree{}},
-	"summary.html": {summaryHtml, map[string]*bintree{}},
+	"fields.html":   {fieldsHtml, map[string]*bintree{}},
+	"forecast.html": {forecastHtml, map[string]*bintree{}},
+	"list.html":     {listHtml, map[string]*bintree{}},
+	"summary.html":  {summaryHtml, map[string]*bintree{}},
+	"timeline.html": {timelineHtml, map[string]*bintree{}},
 }}
 
 // RestoreAsset restores an asset under the given directory
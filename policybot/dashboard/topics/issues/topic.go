@@ -18,16 +18,20 @@ package issues
 
 import (
 	"context"
+	"html/template"
 	"net/http"
+	"regexp"
 	"sort"
+	"strconv"
 	"strings"
-	"text/template"
 	"time"
 
 	"istio.io/bots/policybot/dashboard/types"
+	"istio.io/bots/policybot/mgrs/lifecyclemgr"
 	"istio.io/bots/policybot/pkg/storage"
 	"istio.io/bots/policybot/pkg/storage/cache"
 	"istio.io/bots/policybot/pkg/util"
+	rawcache "istio.io/istio/pkg/cache"
 )
 
 // Issues lets users visualize critical information about outstanding issues.
@@ -36,6 +40,11 @@ type Issues struct {
 	cache      *cache.Cache
 	list       *template.Template
 	summary    *template.Template
+	fields     *template.Template
+	forecast   *template.Template
+	timeline   *template.Template
+	lifecycle  *lifecyclemgr.LifecycleMgr
+	forecasts  rawcache.ExpiringCache
 	defaultOrg string
 }
 
@@ -63,22 +72,62 @@ type issueCountByMonth struct {
 
 type listInfo struct {
 	Title      string
+	Filter     *issueFieldFilter
 	Issues     []issueInfo
 	AreaCounts []areaCount
 }
 
+// issueFieldFilter restricts lists to the issues whose body has a field matching a regular expression
+type issueFieldFilter struct {
+	FieldName string
+	Value     string
+}
+
+type fieldsInfo struct {
+	FieldName string
+	Values    []fieldValueCount
+}
+
+type fieldValueCount struct {
+	Value string
+	Count int
+}
+
+type timelineInfo struct {
+	RepoName    string
+	IssueNumber int
+	Days        int
+	Events      []*storage.LifecycleEvent
+}
+
 type areaCount struct {
 	Area  string
 	Count int
 }
 
+const (
+	// maxForecastDays bounds how far ahead the lifecycle forecast page looks
+	maxForecastDays = 90
+
+	// forecastTTL is how long the forecast of an org is reused before simulating the lifecycle manager's runs again
+	forecastTTL = time.Hour
+
+	// timelineDays is how far back the timeline of a repo goes
+	timelineDays = 30
+)
+
 // New creates a new Issues instance.
-func New(store storage.Store, cache *cache.Cache, defaultOrg string) *Issues {
+func New(store storage.Store, cache *cache.Cache, lifecycle *lifecyclemgr.LifecycleMgr, defaultOrg string) *Issues {
 	return &Issues{
 		store:      store,
 		cache:      cache,
 		list:       template.Must(template.New("list").Parse(string(MustAsset("list.html")))),
 		summary:    template.Must(template.New("summary").Parse(string(MustAsset("summary.html")))),
+		fields:     template.Must(template.New("fields").Parse(string(MustAsset("fields.html")))),
+		forecast:   template.Must(template.New("forecast").Parse(string(MustAsset("forecast.html")))),
+		timeline:   template.Must(template.New("timeline").Parse(string(MustAsset("timeline.html")))),
+		lifecycle:  lifecycle,
+		forecasts:  rawcache.NewTTL(forecastTTL, time.Minute),
 		defaultOrg: defaultOrg,
 	}
 }
@@ -89,13 +138,14 @@ func (i *Issues) RenderList(req *http.Request) (types.RenderInfo, error) {
 		orgLogin = i.defaultOrg
 	}
 
-	mi, ac, err := i.getOpenIssues(req.Context(), orgLogin, "all")
+	mi, ac, err := i.getOpenIssues(req.Context(), orgLogin, "all", fieldFilter(req))
 	if err != nil {
 		return types.RenderInfo{}, err
 	}
 
 	li := &listInfo{
 		Title:      "All Open Issues",
+		Filter:     fieldFilter(req),
 		Issues:     mi,
 		AreaCounts: ac,
 	}
@@ -116,13 +166,14 @@ func (i *Issues) RenderNeedsEscalation(req *http.Request) (types.RenderInfo, err
 		orgLogin = i.defaultOrg
 	}
 
-	mi, ac, err := i.getOpenIssues(req.Context(), orgLogin, "escalation")
+	mi, ac, err := i.getOpenIssues(req.Context(), orgLogin, "escalation", fieldFilter(req))
 	if err != nil {
 		return types.RenderInfo{}, err
 	}
 
 	li := &listInfo{
 		Title:      "All Issues Needing Escalation",
+		Filter:     fieldFilter(req),
 		Issues:     mi,
 		AreaCounts: ac,
 	}
@@ -143,13 +194,14 @@ func (i *Issues) RenderTriage(req *http.Request) (types.RenderInfo, error) {
 		orgLogin = i.defaultOrg
 	}
 
-	mi, ac, err := i.getOpenIssues(req.Context(), orgLogin, "triage")
+	mi, ac, err := i.getOpenIssues(req.Context(), orgLogin, "triage", fieldFilter(req))
 	if err != nil {
 		return types.RenderInfo{}, err
 	}
 
 	li := &listInfo{
 		Title:      "All Issues Needing Triage",
+		Filter:     fieldFilter(req),
 		Issues:     mi,
 		AreaCounts: ac,
 	}
@@ -170,13 +222,14 @@ func (i *Issues) RenderStale(req *http.Request) (types.RenderInfo, error) {
 		orgLogin = i.defaultOrg
 	}
 
-	mi, ac, err := i.getOpenIssues(req.Context(), orgLogin, "stale")
+	mi, ac, err := i.getOpenIssues(req.Context(), orgLogin, "stale", fieldFilter(req))
 	if err != nil {
 		return types.RenderInfo{}, err
 	}
 
 	li := &listInfo{
 		Title:      "All Stale Issues",
+		Filter:     fieldFilter(req),
 		Issues:     mi,
 		AreaCounts: ac,
 	}
@@ -212,7 +265,138 @@ func (i *Issues) RenderSummary(req *http.Request) (types.RenderInfo, error) {
 	}, nil
 }
 
-func (i *Issues) getOpenIssues(context context.Context, orgLogin string, kind string) ([]issueInfo, []areaCount, error) {
+// RenderFields shows how many open issues report each value of an issue form field, such as the Istio version.
+func (i *Issues) RenderFields(req *http.Request) (types.RenderInfo, error) {
+	orgLogin := req.URL.Query().Get("org")
+	if orgLogin == "" {
+		orgLogin = i.defaultOrg
+	}
+
+	fieldName := req.URL.Query().Get("field")
+	if fieldName == "" {
+		fieldName = "version"
+	}
+
+	fi, err := i.getFieldValues(req.Context(), orgLogin, fieldName)
+	if err != nil {
+		return types.RenderInfo{}, err
+	}
+
+	var sb strings.Builder
+	if err := i.fields.Execute(&sb, fi); err != nil {
+		return types.RenderInfo{}, err
+	}
+
+	return types.RenderInfo{
+		Content: sb.String(),
+	}, nil
+}
+
+// RenderForecast shows which open issues and PRs the lifecycle manager will mark stale or close in the coming days.
+func (i *Issues) RenderForecast(req *http.Request) (types.RenderInfo, error) {
+	orgLogin := req.URL.Query().Get("org")
+	if orgLogin == "" {
+		orgLogin = i.defaultOrg
+	}
+
+	days := 30
+	if d := req.URL.Query().Get("days"); d != "" {
+		var err error
+		if days, err = strconv.Atoi(d); err != nil || days < 0 || days > maxForecastDays {
+			return types.RenderInfo{}, util.HTTPErrorf(http.StatusBadRequest, "invalid number of days %s, expecting 0 to %d", d, maxForecastDays)
+		}
+	}
+
+	f, err := i.getForecast(req.Context(), orgLogin)
+	if err != nil {
+		return types.RenderInfo{}, err
+	}
+
+	var sb strings.Builder
+	if err := i.forecast.Execute(&sb, f.Truncate(days)); err != nil {
+		return types.RenderInfo{}, err
+	}
+
+	return types.RenderInfo{
+		Content: sb.String(),
+	}, nil
+}
+
+// getForecast returns the longest forecast the page offers for an org, which shorter ones are cut out of
+func (i *Issues) getForecast(context context.Context, orgLogin string) (*lifecyclemgr.Forecast, error) {
+	if result, ok := i.forecasts.Get(orgLogin); ok {
+		return result.(*lifecyclemgr.Forecast), nil
+	}
+
+	f, err := i.lifecycle.Forecast(context, orgLogin, maxForecastDays)
+	if err != nil {
+		return nil, err
+	}
+
+	i.forecasts.Set(orgLogin, f)
+	return f, nil
+}
+
+// RenderTimeline shows the changes the lifecycle manager made to an issue or PR, or to all of those in a repo recently.
+func (i *Issues) RenderTimeline(req *http.Request) (types.RenderInfo, error) {
+	orgLogin := req.URL.Query().Get("org")
+	if orgLogin == "" {
+		orgLogin = i.defaultOrg
+	}
+
+	ti := timelineInfo{
+		RepoName: req.URL.Query().Get("repo"),
+		Days:     timelineDays,
+	}
+
+	if n := req.URL.Query().Get("number"); n != "" {
+		var err error
+		if ti.IssueNumber, err = strconv.Atoi(n); err != nil || ti.IssueNumber < 0 {
+			return types.RenderInfo{}, util.HTTPErrorf(http.StatusBadRequest, "invalid issue or PR number %s", n)
+		}
+	}
+
+	if ti.RepoName != "" {
+		cb := func(event *storage.LifecycleEvent) error {
+			ti.Events = append(ti.Events, event)
+			return nil
+		}
+
+		var err error
+		if ti.IssueNumber > 0 {
+			err = i.store.QueryLifecycleEventsByIssue(req.Context(), orgLogin, ti.RepoName, ti.IssueNumber, cb)
+		} else {
+			err = i.store.QueryLifecycleEventsByRepo(req.Context(), orgLogin, ti.RepoName, time.Now().AddDate(0, 0, -timelineDays), cb)
+		}
+
+		if err != nil {
+			return types.RenderInfo{}, err
+		}
+	}
+
+	var sb strings.Builder
+	if err := i.timeline.Execute(&sb, ti); err != nil {
+		return types.RenderInfo{}, err
+	}
+
+	return types.RenderInfo{
+		Content: sb.String(),
+	}, nil
+}
+
+func fieldFilter(req *http.Request) *issueFieldFilter {
+	fieldName := req.URL.Query().Get("field")
+	if fieldName == "" {
+		return nil
+	}
+
+	return &issueFieldFilter{
+		FieldName: fieldName,
+		Value:     req.URL.Query().Get("value"),
+	}
+}
+
+func (i *Issues) getOpenIssues(context context.Context, orgLogin string, kind string, filter *issueFieldFilter) ([]issueInfo, []areaCount, error) {
 	org, err := i.cache.ReadOrg(context, orgLogin)
 	if err != nil {
 		return nil, nil, util.HTTPErrorf(http.StatusInternalServerError, "unable to get information on organization %s: %v", orgLogin, err)
@@ -223,8 +407,8 @@ func (i *Issues) getOpenIssues(context context.Context, orgLogin string, kind st
 	areas := make(map[string]int)
 
 	var issues []issueInfo
-	if err = i.store.QueryOpenIssues(context, org.OrgLogin, func(issue *storage.Issue) error {
-		keep := false
+	cb := func(issue *storage.Issue) error {
+		keep := kind == "all"
 		switch kind {
 		case "escalation":
 			for _, lb := range issue.Labels {
@@ -292,7 +476,15 @@ func (i *Issues) getOpenIssues(context context.Context, orgLogin string, kind st
 		})
 
 		return nil
-	}); err != nil {
+	}
+
+	if filter != nil {
+		err = i.store.QueryOpenIssuesByField(context, org.OrgLogin, filter.FieldName, regexp.QuoteMeta(filter.Value), cb)
+	} else {
+		err = i.store.QueryOpenIssues(context, org.OrgLogin, cb)
+	}
+
+	if err != nil {
 		return nil, nil, err
 	}
 
@@ -307,6 +499,42 @@ func (i *Issues) getOpenIssues(context context.Context, orgLogin string, kind st
 	return issues, ac, nil
 }
 
+func (i *Issues) getFieldValues(context context.Context, orgLogin string, fieldName string) (fieldsInfo, error) {
+	fi := fieldsInfo{FieldName: fieldName}
+
+	org, err := i.cache.ReadOrg(context, orgLogin)
+	if err != nil {
+		return fi, util.HTTPErrorf(http.StatusInternalServerError, "unable to get information on organization %s: %v", orgLogin, err)
+	} else if org == nil {
+		return fi, util.HTTPErrorf(http.StatusNotFound, "no information available on organization %s", orgLogin)
+	}
+
+	counts := make(map[string]int)
+	if err = i.store.QueryOpenIssueFields(context, org.OrgLogin, fieldName, func(field *storage.IssueField) error {
+		// fields like checkbox lists hold one value per line
+		for _, v := range strings.Split(field.Value, "\n") {
+			if v = strings.TrimSpace(v); v != "" {
+				counts[v]++
+			}
+		}
+		return nil
+	}); err != nil {
+		return fi, err
+	}
+
+	for v, c := range counts {
+		fi.Values = append(fi.Values, fieldValueCount{Value: v, Count: c})
+	}
+	sort.Slice(fi.Values, func(i, j int) bool {
+		if fi.Values[i].Count != fi.Values[j].Count {
+			return fi.Values[i].Count > fi.Values[j].Count
+		}
+		return fi.Values[i].Value < fi.Values[j].Value
+	})
+
+	return fi, nil
+}
+
 func (i *Issues) getIssuesSummary(context context.Context, orgLogin string) (issuesSummary, error) {
 	var summary issuesSummary
 	opened := make(map[string]map[string]int)
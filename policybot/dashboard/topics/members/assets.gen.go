@@ -1,6 +1,7 @@
 // Code generated for package members by go-bindata DO NOT EDIT. (@generated)
 // sources:
 // list.html
+// mentors.html
 // single.html
 // single_control.html
 // user.html
@@ -167,6 +168,75 @@ func listHtml() (*asset, error) {
 	return a, nil
 }
 
+var _mentorsHtml = []byte(`<p>
+    New contributors get a mentor assigned to their first PRs. These are the people who have been mentoring lately,
+    and how long it took them to first respond.
+</p>
+
+<table>
+    <caption>Mentors</caption>
+    <thead>
+    <tr>
+        <th>Mentor</th>
+        <th>Assigned PRs</th>
+        <th>Awaiting Response</th>
+        <th>Median Response Time</th>
+    </tr>
+    </thead>
+    <tbody>
+        {{ range .Mentors }}
+            <tr>
+                <td><a href="/members/{{ .Mentor }}">{{ .Mentor }}</a></td>
+                <td>{{ .Assigned }}</td>
+                <td>{{ .Pending }}</td>
+                <td>{{ .MedianResponse }}</td>
+            </tr>
+        {{ end }}
+    </tbody>
+</table>
+
+<table>
+    <caption>Recent Assignments</caption>
+    <thead>
+    <tr>
+        <th>Repository</th>
+        <th>PR</th>
+        <th>Author</th>
+        <th>Mentor</th>
+        <th>Assigned</th>
+        <th>Response Time</th>
+    </tr>
+    </thead>
+    <tbody>
+        {{ range .Assignments }}
+            <tr>
+                <td>{{ .RepoName }}</td>
+                <td><a href="https://github.com/{{ .OrgLogin }}/{{ .RepoName }}/pull/{{ .PullRequestNumber }}">{{ .PullRequestNumber }}</a></td>
+                <td>{{ .Author }}</td>
+                <td>{{ .Mentor }}</td>
+                <td>{{ .AssignedAt }}</td>
+                <td>{{ .ResponseTime }}</td>
+            </tr>
+        {{ end }}
+    </tbody>
+</table>
+`)
+
+func mentorsHtmlBytes() ([]byte, error) {
+	return _mentorsHtml, nil
+}
+
+func mentorsHtml() (*asset, error) {
+	bytes, err := mentorsHtmlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "mentors.html", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
 var _singleHtml = []byte(`<div class="user-page">
     <div class="profile">
         <div class="avatar">
@@ -426,6 +496,7 @@ func AssetNames() []string {
 // _bindata is a table, holding each asset generator, mapped to its name.
 var _bindata = map[string]func() (*asset, error){
 	"list.html":           listHtml,
+	"mentors.html":        mentorsHtml,
 	"single.html":         singleHtml,
 	"single_control.html": single_controlHtml,
 	"user.html":           userHtml,
@@ -473,6 +544,7 @@ type bintree struct {
 
 var _bintree = &bintree{nil, map[string]*bintree{
 	"list.html":           {listHtml, map[string]*bintree{}},
+	"mentors.html":        {mentorsHtml, map[string]*bintree{}},
 	"single.html":         {singleHtml, map[string]*bintree{}},
 	"single_control.html": {single_controlHtml, map[string]*bintree{}},
 	"user.html":           {userHtml, map[string]*bintree{}},
@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"html/template"
 	"net/http"
+	"sort"
 	"strings"
 	"time"
 
@@ -46,6 +47,7 @@ type Members struct {
 	user           *template.Template
 	singleControl  *template.Template
 	list           *template.Template
+	mentors        *template.Template
 	activityWindow time.Duration
 	defaultOrg     string
 	reg            *config.Registry
@@ -58,6 +60,26 @@ type combo struct {
 	TimeZero   time.Time // hack to provide a zero-initialized timestamp to the Go templates
 }
 
+type mentorSummary struct {
+	Mentor         string
+	Assigned       int
+	Pending        int
+	MedianResponse string
+}
+
+type mentorAssignment struct {
+	OrgLogin          string
+	RepoName          string
+	PullRequestNumber int64
+	Author            string
+	Mentor            string
+	AssignedAt        string
+	ResponseTime      string
+}
+
+// how many assignments to list on the mentors page
+const maxMentorAssignments = 100
+
 type filterFlags int
 
 // what this page can display
@@ -83,6 +105,7 @@ func New(store storage.Store, cache *cache.Cache, cacheTTL time.Duration, activi
 		user:           template.Must(template.New("user").Parse(string(MustAsset("user.html")))),
 		singleControl:  template.Must(template.New("singleControl").Parse(string(MustAsset("single_control.html")))),
 		list:           template.Must(template.New("list").Parse(string(MustAsset("list.html")))),
+		mentors:        template.Must(template.New("mentors").Parse(string(MustAsset("mentors.html")))),
 		activityWindow: activityWindow,
 		defaultOrg:     defaultOrg,
 		reg:            reg,
@@ -173,6 +196,118 @@ func (m *Members) RenderList(req *http.Request) (types.RenderInfo, error) {
 	}, nil
 }
 
+// Renders the HTML for the mentors of new contributors.
+func (m *Members) RenderMentors(req *http.Request) (types.RenderInfo, error) {
+	orgLogin := req.URL.Query().Get("org")
+	if orgLogin == "" {
+		orgLogin = m.defaultOrg
+	}
+
+	org, err := m.cache.ReadOrg(req.Context(), orgLogin)
+	if err != nil {
+		return types.RenderInfo{}, err
+	} else if org == nil {
+		return types.RenderInfo{}, util.HTTPErrorf(http.StatusNotFound, "no information available on organization %s", orgLogin)
+	}
+
+	var assignments []*storage.MentorAssignment
+	if err := m.store.QueryMentorAssignments(req.Context(), org.OrgLogin, func(ma *storage.MentorAssignment) error {
+		assignments = append(assignments, ma)
+		return nil
+	}); err != nil {
+		return types.RenderInfo{}, util.HTTPErrorf(http.StatusInternalServerError, "unable to read mentor assignments: %v", err)
+	}
+
+	info := struct {
+		Mentors     []mentorSummary
+		Assignments []mentorAssignment
+	}{
+		Mentors: summarizeMentors(assignments),
+	}
+
+	for i, ma := range assignments {
+		if i == maxMentorAssignments {
+			break
+		}
+
+		info.Assignments = append(info.Assignments, mentorAssignment{
+			OrgLogin:          ma.OrgLogin,
+			RepoName:          ma.RepoName,
+			PullRequestNumber: ma.PullRequestNumber,
+			Author:            ma.Author,
+			Mentor:            ma.Mentor,
+			AssignedAt:        ma.AssignedAt.Format("02-Jan-2006"),
+			ResponseTime:      responseTime(ma),
+		})
+	}
+
+	var sb strings.Builder
+	if err := m.mentors.Execute(&sb, info); err != nil {
+		return types.RenderInfo{}, err
+	}
+
+	return types.RenderInfo{
+		Title:   "Mentors",
+		Content: sb.String(),
+	}, nil
+}
+
+// summarizeMentors computes per-mentor statistics, busiest mentors first
+func summarizeMentors(assignments []*storage.MentorAssignment) []mentorSummary {
+	responses := make(map[string][]time.Duration)
+	summaries := make(map[string]*mentorSummary)
+	for _, ma := range assignments {
+		s := summaries[ma.Mentor]
+		if s == nil {
+			s = &mentorSummary{Mentor: ma.Mentor}
+			summaries[ma.Mentor] = s
+		}
+
+		s.Assigned++
+		if ma.RespondedAt == nil {
+			s.Pending++
+		} else {
+			responses[ma.Mentor] = append(responses[ma.Mentor], ma.RespondedAt.Sub(ma.AssignedAt))
+		}
+	}
+
+	result := make([]mentorSummary, 0, len(summaries))
+	for mentor, s := range summaries {
+		if r := responses[mentor]; len(r) > 0 {
+			sort.Slice(r, func(i, j int) bool { return r[i] < r[j] })
+			s.MedianResponse = formatDuration(r[len(r)/2])
+		}
+		result = append(result, *s)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Assigned != result[j].Assigned {
+			return result[i].Assigned > result[j].Assigned
+		}
+		return result[i].Mentor < result[j].Mentor
+	})
+
+	return result
+}
+
+func responseTime(ma *storage.MentorAssignment) string {
+	if ma.RespondedAt == nil {
+		return "awaiting"
+	}
+
+	return formatDuration(ma.RespondedAt.Sub(ma.AssignedAt))
+}
+
+func formatDuration(d time.Duration) string {
+	if d < time.Hour {
+		return d.Round(time.Minute).String()
+	} else if d < 48*time.Hour {
+		return d.Round(time.Hour).String()
+	}
+
+	return fmt.Sprintf("%d days", int(d/(24*time.Hour)))
+}
+
 // Returns the list of members via WebSocket.
 func (m *Members) GetList(w http.ResponseWriter, req *http.Request) {
 	orgLogin := req.URL.Query().Get("org")
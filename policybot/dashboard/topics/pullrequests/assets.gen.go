@@ -1,5 +1,6 @@
 // Code generated for package pullrequests by go-bindata DO NOT EDIT. (@generated)
 // sources:
+// backports.html
 // page.html
 package pullrequests
 
@@ -54,6 +55,50 @@ func (fi bindataFileInfo) Sys() interface{} {
 	return nil
 }
 
+var _backportsHtml = []byte(`<table>
+  <caption>Automated Backports</caption>
+  <thead>
+  <tr>
+      <th>Repository</th>
+      <th>Pull Request</th>
+      <th>Target Branch</th>
+      <th>Status</th>
+      <th>Backport</th>
+      <th>Details</th>
+      <th>Last Updated</th>
+  </tr>
+  </thead>
+  <tbody>
+      {{ range .Backports }}
+          <tr>
+              <td>{{ .RepoName }}</td>
+              <td><a href="https://github.com/{{ .OrgLogin }}/{{ .RepoName }}/pull/{{ .PullRequestNumber }}">{{ .PullRequestNumber }}</a></td>
+              <td>{{ .TargetBranch }}</td>
+              <td>{{ .Status }}</td>
+              <td>{{ if .BackportNumber }}<a href="https://github.com/{{ .OrgLogin }}/{{ .RepoName }}/pull/{{ .BackportNumber }}">{{ .BackportNumber }}</a>{{ end }}</td>
+              <td>{{ .Details }}</td>
+              <td>{{ .UpdatedAt }}</td>
+          </tr>
+      {{ end }}
+  </tbody>
+</table>
+`)
+
+func backportsHtmlBytes() ([]byte, error) {
+	return _backportsHtml, nil
+}
+
+func backportsHtml() (*asset, error) {
+	bytes, err := backportsHtmlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "backports.html", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
 var _pageHtml = []byte(`<aside class="callout warning">
     <div class="type">
         <svg class="large-icon">
@@ -138,7 +183,8 @@ func AssetNames() []string {
 
 // _bindata is a table, holding each asset generator, mapped to its name.
 var _bindata = map[string]func() (*asset, error){
-	"page.html": pageHtml,
+	"backports.html": backportsHtml,
+	"page.html":      pageHtml,
 }
 
 // AssetDir returns the file names below a certain
@@ -182,7 +228,8 @@ type bintree struct {
 }
 
 var _bintree = &bintree{nil, map[string]*bintree{
-	"page.html": {pageHtml, map[string]*bintree{}},
+	"backports.html": {backportsHtml, map[string]*bintree{}},
+	"page.html":      {pageHtml, map[string]*bintree{}},
 }}
 
 // RestoreAsset restores an asset under the given directory
@@ -17,7 +17,9 @@
 package pullrequests
 
 import (
+	"html/template"
 	"net/http"
+	"strings"
 
 	"istio.io/bots/policybot/dashboard/types"
 	"istio.io/bots/policybot/pkg/storage"
@@ -26,17 +28,32 @@ import (
 
 // PullRequests lets users visualize critical information about the project's outstanding pull requests.
 type PullRequests struct {
-	store storage.Store
-	cache *cache.Cache
-	page  string
+	store      storage.Store
+	cache      *cache.Cache
+	page       string
+	backports  *template.Template
+	defaultOrg string
+}
+
+type backportInfo struct {
+	OrgLogin          string
+	RepoName          string
+	PullRequestNumber int64
+	TargetBranch      string
+	Status            string
+	BackportNumber    int64
+	Details           string
+	UpdatedAt         string
 }
 
 // New creates a new PullRequests instance.
-func New(store storage.Store, cache *cache.Cache) *PullRequests {
+func New(store storage.Store, cache *cache.Cache, defaultOrg string) *PullRequests {
 	return &PullRequests{
-		store: store,
-		cache: cache,
-		page:  string(MustAsset("page.html")),
+		store:      store,
+		cache:      cache,
+		page:       string(MustAsset("page.html")),
+		backports:  template.Must(template.New("backports").Parse(string(MustAsset("backports.html")))),
+		defaultOrg: defaultOrg,
 	}
 }
 
@@ -46,3 +63,37 @@ func (pr *PullRequests) Render(req *http.Request) (types.RenderInfo, error) {
 		Content: pr.page,
 	}, nil
 }
+
+// Renders the HTML for the status of automated backports.
+func (pr *PullRequests) RenderBackports(req *http.Request) (types.RenderInfo, error) {
+	orgLogin := req.URL.Query().Get("org")
+	if orgLogin == "" {
+		orgLogin = pr.defaultOrg
+	}
+
+	var backports []backportInfo
+	if err := pr.store.QueryBackports(req.Context(), orgLogin, func(b *storage.Backport) error {
+		backports = append(backports, backportInfo{
+			OrgLogin:          b.OrgLogin,
+			RepoName:          b.RepoName,
+			PullRequestNumber: b.PullRequestNumber,
+			TargetBranch:      b.TargetBranch,
+			Status:            b.Status,
+			BackportNumber:    b.BackportNumber,
+			Details:           b.Details,
+			UpdatedAt:         b.UpdatedAt.Format("2006-01-02"),
+		})
+		return nil
+	}); err != nil {
+		return types.RenderInfo{}, err
+	}
+
+	var sb strings.Builder
+	if err := pr.backports.Execute(&sb, struct{ Backports []backportInfo }{backports}); err != nil {
+		return types.RenderInfo{}, err
+	}
+
+	return types.RenderInfo{
+		Content: sb.String(),
+	}, nil
+}
@@ -0,0 +1,296 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cherrypicker
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v26/github"
+
+	"istio.io/bots/policybot/handlers/githubwebhook"
+	"istio.io/bots/policybot/pkg/config"
+	"istio.io/bots/policybot/pkg/gh"
+	"istio.io/bots/policybot/pkg/storage"
+	"istio.io/istio/pkg/log"
+)
+
+// Creates backport PRs for merged PRs that carry cherry-pick labels.
+type CherryPicker struct {
+	gc    *gh.ThrottledClient
+	store storage.Store
+	reg   *config.Registry
+}
+
+// Backport status values
+const (
+	StatusCreated  = "created"
+	StatusConflict = "conflict"
+	StatusFailed   = "failed"
+)
+
+const cherrypickSignature = "\n\n_Courtesy of your friendly cherry-picker_."
+
+var scope = log.RegisterScope("cherrypicker", "Automated backports of merged PRs")
+
+func New(gc *gh.ThrottledClient, store storage.Store, reg *config.Registry) githubwebhook.Filter {
+	return &CherryPicker{
+		gc:    gc,
+		store: store,
+		reg:   reg,
+	}
+}
+
+// process an event arriving from GitHub
+func (cp *CherryPicker) Handle(context context.Context, event interface{}) {
+	prp, ok := event.(*github.PullRequestEvent)
+	if !ok {
+		// not what we're looking for
+		scope.Debugf("Unknown event received: %T %+v", event, event)
+		return
+	}
+
+	scope.Infof("Received PullRequestEvent: %s, %d, %s", prp.GetRepo().GetFullName(), prp.GetPullRequest().GetNumber(), prp.GetAction())
+
+	repo := prp.GetRepo().GetFullName()
+	pr := prp.GetPullRequest()
+
+	action := prp.GetAction()
+	if action != "closed" && action != "labeled" {
+		scope.Infof("Ignoring event for PR %d from repo %s since it doesn't have a supported action: %s", pr.GetNumber(), repo, action)
+		return
+	}
+
+	r, ok := cp.reg.SingleRecord(recordType, repo)
+	if !ok {
+		scope.Infof("Ignoring event for PR %d from repo %s since cherry-picking isn't configured", pr.GetNumber(), repo)
+		return
+	}
+	cr := r.(*cherrypickRecord)
+
+	if !pr.GetMerged() {
+		scope.Infof("Ignoring event for PR %d from repo %s since it isn't merged", pr.GetNumber(), repo)
+		return
+	}
+
+	var targets []string
+	if action == "closed" {
+		for _, label := range pr.Labels {
+			if target := cr.targetBranch(label.GetName()); target != "" {
+				targets = append(targets, target)
+			}
+		}
+	} else if target := cr.targetBranch(prp.GetLabel().GetName()); target != "" {
+		targets = append(targets, target)
+	}
+
+	if len(targets) == 0 {
+		scope.Infof("Ignoring event for PR %d from repo %s since it doesn't request any backports", pr.GetNumber(), repo)
+		return
+	}
+
+	scope.Infof("Processing PR %d from repo %s", pr.GetNumber(), repo)
+
+	cp.processPR(context, prp.GetRepo().GetOwner().GetLogin(), prp.GetRepo().GetName(), pr, targets, cr)
+}
+
+func (cp *CherryPicker) processPR(context context.Context, orgLogin string, repoName string, pr *github.PullRequest,
+	targets []string, cr *cherrypickRecord) {
+	backports := make(map[string]*storage.Backport)
+	if err := cp.store.QueryBackportsByPullRequest(context, orgLogin, repoName, pr.GetNumber(), func(b *storage.Backport) error {
+		backports[b.TargetBranch] = b
+		return nil
+	}); err != nil {
+		scope.Errorf("Unable to get backports for PR %d in repo %s/%s: %v", pr.GetNumber(), orgLogin, repoName, err)
+		return
+	}
+
+	var updated []*storage.Backport
+	for _, target := range targets {
+		if b, ok := backports[target]; ok && b.Status == StatusCreated {
+			scope.Infof("PR %d in repo %s/%s was already backported to %s", pr.GetNumber(), orgLogin, repoName, target)
+			continue
+		}
+
+		if target == pr.GetBase().GetRef() {
+			scope.Infof("PR %d in repo %s/%s already targets %s", pr.GetNumber(), orgLogin, repoName, target)
+			continue
+		}
+
+		b := cp.backport(context, orgLogin, repoName, pr, target, cr)
+		backports[target] = b
+		updated = append(updated, b)
+	}
+
+	if len(updated) == 0 {
+		return
+	}
+
+	if err := cp.store.WriteBackports(context, updated); err != nil {
+		scope.Errorf("Unable to record backports for PR %d in repo %s/%s: %v", pr.GetNumber(), orgLogin, repoName, err)
+	}
+
+	cp.report(context, orgLogin, repoName, pr, backports)
+}
+
+// backport cherry-picks a merged PR onto a target branch and opens a PR for the result
+func (cp *CherryPicker) backport(context context.Context, orgLogin string, repoName string, pr *github.PullRequest,
+	target string, cr *cherrypickRecord) *storage.Backport {
+	b := &storage.Backport{
+		OrgLogin:          orgLogin,
+		RepoName:          repoName,
+		PullRequestNumber: int64(pr.GetNumber()),
+		TargetBranch:      target,
+		UpdatedAt:         time.Now(),
+	}
+
+	shas, err := cp.gc.LandedCommits(context, orgLogin, repoName, pr)
+	if err != nil {
+		scope.Errorf("Unable to get the commits of PR %d in repo %s/%s: %v", pr.GetNumber(), orgLogin, repoName, err)
+		b.Status = StatusFailed
+		b.Details = err.Error()
+		return b
+	}
+
+	branch := fmt.Sprintf("%s%d-to-%s", cr.BranchPrefix, pr.GetNumber(), target)
+	_, err = cp.gc.CherryPick(context, orgLogin, repoName, shas, target, branch)
+	if err == gh.ErrCherryPickConflict {
+		scope.Infof("PR %d in repo %s/%s doesn't apply cleanly to %s", pr.GetNumber(), orgLogin, repoName, target)
+		b.Status = StatusConflict
+		b.Details = cp.conflictingFiles(context, orgLogin, repoName, pr, target)
+		return b
+	} else if err != nil {
+		scope.Errorf("Unable to cherry-pick PR %d in repo %s/%s to %s: %v", pr.GetNumber(), orgLogin, repoName, target, err)
+		b.Status = StatusFailed
+		b.Details = err.Error()
+		return b
+	}
+
+	title := fmt.Sprintf("[%s] %s", target, pr.GetTitle())
+	body := fmt.Sprintf("This is an automated cherry-pick of #%d\n\n%s", pr.GetNumber(), pr.GetBody())
+	np, _, err := cp.gc.ThrottledCall(func(client *github.Client) (interface{}, *github.Response, error) {
+		return client.PullRequests.Create(context, orgLogin, repoName, &github.NewPullRequest{
+			Title: &title,
+			Head:  &branch,
+			Base:  &target,
+			Body:  &body,
+		})
+	})
+	if err != nil {
+		scope.Errorf("Unable to create backport PR for PR %d in repo %s/%s to %s: %v", pr.GetNumber(), orgLogin, repoName, target, err)
+
+		// don't leave the branch behind, a later attempt starts over
+		if _, err := cp.gc.ThrottledCallNoResult(func(client *github.Client) (*github.Response, error) {
+			return client.Git.DeleteRef(context, orgLogin, repoName, "heads/"+branch)
+		}); err != nil {
+			scope.Warnf("Unable to delete branch %s in repo %s/%s: %v", branch, orgLogin, repoName, err)
+		}
+
+		b.Status = StatusFailed
+		b.Details = err.Error()
+		return b
+	}
+
+	b.Status = StatusCreated
+	b.BackportNumber = int64(np.(*github.PullRequest).GetNumber())
+
+	scope.Infof("Created backport PR %d for PR %d in repo %s/%s to %s", b.BackportNumber, pr.GetNumber(), orgLogin, repoName, target)
+	return b
+}
+
+// conflictingFiles returns a description of the PR's files that were also changed in the target branch, which are
+// the likely source of conflicts.
+func (cp *CherryPicker) conflictingFiles(context context.Context, orgLogin string, repoName string, pr *github.PullRequest, target string) string {
+	prFiles := make(map[string]bool)
+	if err := cp.gc.FetchFiles(context, orgLogin, repoName, pr.GetNumber(), func(files []string) error {
+		for _, f := range files {
+			prFiles[f] = true
+		}
+		return nil
+	}); err != nil {
+		scope.Warnf("Unable to get files for PR %d in repo %s/%s: %v", pr.GetNumber(), orgLogin, repoName, err)
+		return ""
+	}
+
+	c, _, err := cp.gc.ThrottledCall(func(client *github.Client) (interface{}, *github.Response, error) {
+		return client.Repositories.CompareCommits(context, orgLogin, repoName, pr.GetMergeCommitSHA(), target)
+	})
+	if err != nil {
+		scope.Warnf("Unable to compare PR %d in repo %s/%s with %s: %v", pr.GetNumber(), orgLogin, repoName, target, err)
+		return ""
+	}
+
+	var conflicts []string
+	for _, f := range c.(*github.CommitsComparison).Files {
+		if prFiles[f.GetFilename()] {
+			conflicts = append(conflicts, f.GetFilename())
+		}
+	}
+
+	if len(conflicts) == 0 {
+		return ""
+	}
+
+	sort.Strings(conflicts)
+	return "Likely conflicts in `" + strings.Join(conflicts, "`, `") + "`"
+}
+
+// report updates the status comment on the original PR
+func (cp *CherryPicker) report(context context.Context, orgLogin string, repoName string, pr *github.PullRequest,
+	backports map[string]*storage.Backport) {
+	var targets []string
+	for target := range backports {
+		targets = append(targets, target)
+	}
+	sort.Strings(targets)
+
+	var sb strings.Builder
+	sb.WriteString("🍒 Automated cherry-picks of this PR:\n\n")
+	sb.WriteString("| Branch | Status |\n")
+	sb.WriteString("|--------|--------|\n")
+	for _, target := range targets {
+		b := backports[target]
+
+		status := ""
+		switch b.Status {
+		case StatusCreated:
+			status = fmt.Sprintf("Created #%d", b.BackportNumber)
+		case StatusConflict:
+			status = "⚠️ Doesn't apply cleanly, please backport manually"
+		default:
+			status = "❌ Failed, please backport manually"
+		}
+
+		if b.Details != "" && b.Status != StatusCreated {
+			status += ". " + b.Details
+		}
+
+		// branch names, file names and errors all come from outside, so keep them from breaking the comment
+		sb.WriteString(fmt.Sprintf("| `%s` | %s |\n", escapeCell(target), escapeCell(status)))
+	}
+
+	if err := cp.gc.AddOrReplaceBotComment(context, orgLogin, repoName, pr.GetNumber(), pr.GetUser().GetLogin(), sb.String(), cherrypickSignature); err != nil {
+		scope.Errorf("Unable to report backports for PR %d in repo %s/%s: %v", pr.GetNumber(), orgLogin, repoName, err)
+	}
+}
+
+// escapeCell keeps text from being interpreted by the comment template engine or breaking out of a table cell
+func escapeCell(s string) string {
+	s = strings.NewReplacer("|", "\\|", "\r", "", "\n", " ", "<", "&lt;", ">", "&gt;").Replace(s)
+	return gh.EscapeTemplate(s)
+}
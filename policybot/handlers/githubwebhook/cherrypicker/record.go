@@ -0,0 +1,51 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cherrypicker
+
+import (
+	"strings"
+
+	"istio.io/bots/policybot/pkg/config"
+)
+
+const recordType = "cherrypick"
+
+type cherrypickRecord struct {
+	config.RecordBase
+
+	// LabelPrefix identifies labels that request a backport. The rest of the label's name is the target branch.
+	LabelPrefix string
+
+	// BranchPrefix is used when naming the branches that hold backported changes
+	BranchPrefix string
+}
+
+func init() {
+	config.RegisterType(recordType, config.OnePerRepo, func() config.Record {
+		return &cherrypickRecord{
+			LabelPrefix:  "cherrypick/",
+			BranchPrefix: "cherrypick-",
+		}
+	})
+}
+
+// targetBranch returns the branch a label asks to backport to, or "" if the label isn't a backport request
+func (cr *cherrypickRecord) targetBranch(label string) string {
+	if !strings.HasPrefix(label, cr.LabelPrefix) {
+		return ""
+	}
+
+	return strings.TrimPrefix(label, cr.LabelPrefix)
+}
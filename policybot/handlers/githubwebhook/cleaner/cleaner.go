@@ -25,6 +25,7 @@ import (
 	"istio.io/bots/policybot/handlers/githubwebhook"
 	"istio.io/bots/policybot/pkg/config"
 	"istio.io/bots/policybot/pkg/gh"
+	"istio.io/bots/policybot/pkg/rules"
 	"istio.io/bots/policybot/pkg/storage"
 	"istio.io/istio/pkg/log"
 )
@@ -33,6 +34,7 @@ import (
 type Cleaner struct {
 	gc               *gh.ThrottledClient
 	multiLineRegexes map[string]*regexp.Regexp
+	exprs            map[*boilerplateRecord]*rules.Expr
 	reg              *config.Registry
 }
 
@@ -42,6 +44,7 @@ func New(gc *gh.ThrottledClient, reg *config.Registry) (githubwebhook.Filter, er
 	l := &Cleaner{
 		gc:               gc,
 		multiLineRegexes: make(map[string]*regexp.Regexp),
+		exprs:            make(map[*boilerplateRecord]*rules.Expr),
 		reg:              reg,
 	}
 
@@ -63,6 +66,12 @@ func (l *Cleaner) processBoilerplateRegexes(b *boilerplateRecord) error {
 	}
 	l.multiLineRegexes[b.Regex] = r
 
+	expr, err := rules.Compile(b.Rule)
+	if err != nil {
+		return fmt.Errorf("invalid rule for boilerplate %s: %v", b.Name, err)
+	}
+	l.exprs[b] = expr
+
 	return nil
 }
 
@@ -104,7 +113,7 @@ func (l *Cleaner) Handle(context context.Context, event interface{}) {
 		return
 	}
 
-	if action != "opened" {
+	if action != "opened" && action != "edited" && action != "reopened" && action != "synchronize" {
 		// not what we care about
 		scope.Infof("Ignoring event for issue/PR %d from repo %s since it doesn't have a supported action: %s", number, repo, action)
 		return
@@ -135,9 +144,13 @@ func (l *Cleaner) processIssue(context context.Context, issue *storage.Issue, bo
 	}
 
 	body := original
+	subject := rules.IssueSubject(issue)
 
 	for _, rec := range boilerplates {
 		b := rec.(*boilerplateRecord)
+		if !l.exprs[b].Eval(subject) {
+			continue
+		}
 		r := l.multiLineRegexes[b.Regex]
 
 		oldBody := body
@@ -171,9 +184,13 @@ func (l *Cleaner) processPullRequest(context context.Context, pr *storage.PullRe
 	}
 
 	body := original
+	subject := rules.PullRequestSubject(pr)
 
 	for _, rec := range boilerplates {
 		b := rec.(*boilerplateRecord)
+		if !l.exprs[b].Eval(subject) {
+			continue
+		}
 		r := l.multiLineRegexes[b.Regex]
 
 		oldBody := body
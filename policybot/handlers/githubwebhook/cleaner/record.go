@@ -16,6 +16,7 @@ package cleaner
 
 import (
 	"istio.io/bots/policybot/pkg/config"
+	"istio.io/bots/policybot/pkg/rules"
 )
 
 const recordType = "boilerplate"
@@ -24,10 +25,18 @@ type boilerplateRecord struct {
 	config.RecordBase
 	Regex       string `json:"regex"`
 	Replacement string `json:"replacement"`
+
+	// Rule limits the issues and PRs the boilerplate is removed from, it applies to all of them when absent
+	Rule *rules.Rule `json:"rule"`
 }
 
 func init() {
 	config.RegisterType(recordType, config.MultiplePerRepo, func() config.Record {
 		return new(boilerplateRecord)
 	})
+
+	rules.RegisterSource(recordType, func(r config.Record) (string, *rules.Rule) {
+		b := r.(*boilerplateRecord)
+		return b.Name, b.Rule
+	})
 }
@@ -0,0 +1,201 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commander
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v26/github"
+
+	"istio.io/bots/policybot/mgrs/milestonemgr"
+	"istio.io/bots/policybot/pkg/gh"
+)
+
+// the commands supported out of the box
+func (c *Commander) builtins() []*Command {
+	return []*Command{
+		{
+			Name:        "help",
+			Usage:       "/help",
+			Description: "Lists the commands available in this repo.",
+			Permission:  Anyone,
+			Handler:     c.help,
+		},
+		{
+			Name:        "label",
+			Usage:       "/label <label>...",
+			Description: "Adds one or more labels.",
+			Permission:  Member,
+			Handler:     c.label,
+		},
+		{
+			Name:        "remove-label",
+			Usage:       "/remove-label <label>...",
+			Description: "Removes one or more labels.",
+			Permission:  Member,
+			Handler:     c.removeLabel,
+		},
+		{
+			Name:        "assign",
+			Usage:       "/assign [@user...]",
+			Description: "Assigns the given users, or yourself if no users are given.",
+			Permission:  Member,
+			Handler:     c.assign,
+		},
+		{
+			Name:        "unassign",
+			Usage:       "/unassign [@user...]",
+			Description: "Unassigns the given users, or yourself if no users are given.",
+			Permission:  Member,
+			Handler:     c.unassign,
+		},
+		{
+			Name:        "milestone",
+			Usage:       "/milestone <milestone>",
+			Description: "Sets the milestone.",
+			Permission:  Maintainer,
+			Handler:     c.milestone,
+		},
+		{
+			Name:        "close",
+			Usage:       "/close",
+			Description: "Closes the issue or PR.",
+			Permission:  Author,
+			Handler:     c.close,
+		},
+	}
+}
+
+func (c *Commander) help(_ context.Context, inv *Invocation) (string, error) {
+	var sb strings.Builder
+	sb.WriteString("These are the commands available in this repo:\n\n")
+	for _, cmd := range c.available(inv.OrgLogin + "/" + inv.RepoName) {
+		sb.WriteString(fmt.Sprintf("- `%s`: %s\n", cmd.Usage, cmd.Description))
+	}
+
+	return sb.String(), nil
+}
+
+func (c *Commander) label(context context.Context, inv *Invocation) (string, error) {
+	if len(inv.Args) == 0 {
+		return "", errors.New("no labels specified")
+	}
+
+	var toApply []string
+	for _, name := range inv.Args {
+		label, err := c.cache.ReadLabel(context, inv.OrgLogin, inv.RepoName, name)
+		if err != nil {
+			return "", err
+		} else if label == nil {
+			return "", fmt.Errorf("label `%s` doesn't exist in this repo", name)
+		}
+
+		if !inv.HasLabel(name) {
+			toApply = append(toApply, label.LabelName)
+		}
+	}
+
+	if len(toApply) == 0 {
+		return "", nil
+	}
+
+	_, _, err := c.gc.ThrottledCall(func(client *github.Client) (interface{}, *github.Response, error) {
+		return client.Issues.AddLabelsToIssue(context, inv.OrgLogin, inv.RepoName, inv.Number, toApply)
+	})
+
+	return "", err
+}
+
+func (c *Commander) removeLabel(context context.Context, inv *Invocation) (string, error) {
+	if len(inv.Args) == 0 {
+		return "", errors.New("no labels specified")
+	}
+
+	for _, name := range inv.Args {
+		if !inv.HasLabel(name) {
+			continue
+		}
+
+		if _, err := c.gc.ThrottledCallNoResult(func(client *github.Client) (*github.Response, error) {
+			return client.Issues.RemoveLabelForIssue(context, inv.OrgLogin, inv.RepoName, inv.Number, name)
+		}); err != nil {
+			return "", err
+		}
+	}
+
+	return "", nil
+}
+
+func (c *Commander) assign(context context.Context, inv *Invocation) (string, error) {
+	_, _, err := c.gc.ThrottledCall(func(client *github.Client) (interface{}, *github.Response, error) {
+		return client.Issues.AddAssignees(context, inv.OrgLogin, inv.RepoName, inv.Number, users(inv))
+	})
+
+	return "", err
+}
+
+func (c *Commander) unassign(context context.Context, inv *Invocation) (string, error) {
+	_, _, err := c.gc.ThrottledCall(func(client *github.Client) (interface{}, *github.Response, error) {
+		return client.Issues.RemoveAssignees(context, inv.OrgLogin, inv.RepoName, inv.Number, users(inv))
+	})
+
+	return "", err
+}
+
+func (c *Commander) milestone(context context.Context, inv *Invocation) (string, error) {
+	if len(inv.Args) == 0 {
+		return "", errors.New("no milestone specified")
+	}
+
+	name := strings.Join(inv.Args, " ")
+	num, err := milestonemgr.FindMilestone(context, c.gc, gh.NewRepoDesc(inv.OrgLogin+"/"+inv.RepoName), name)
+	if err != nil {
+		return "", err
+	} else if num < 0 {
+		return "", fmt.Errorf("milestone `%s` doesn't exist in this repo", name)
+	}
+
+	_, _, err = c.gc.ThrottledCall(func(client *github.Client) (interface{}, *github.Response, error) {
+		return client.Issues.Edit(context, inv.OrgLogin, inv.RepoName, inv.Number, &github.IssueRequest{Milestone: &num})
+	})
+
+	return "", err
+}
+
+func (c *Commander) close(context context.Context, inv *Invocation) (string, error) {
+	state := "closed"
+	_, _, err := c.gc.ThrottledCall(func(client *github.Client) (interface{}, *github.Response, error) {
+		return client.Issues.Edit(context, inv.OrgLogin, inv.RepoName, inv.Number, &github.IssueRequest{State: &state})
+	})
+
+	return "", err
+}
+
+// users returns the users named in a command's arguments, or the commenter if there are none
+func users(inv *Invocation) []string {
+	if len(inv.Args) == 0 {
+		return []string{inv.Commenter}
+	}
+
+	result := make([]string, 0, len(inv.Args))
+	for _, arg := range inv.Args {
+		result = append(result, strings.TrimPrefix(arg, "@"))
+	}
+
+	return result
+}
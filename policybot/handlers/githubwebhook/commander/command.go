@@ -0,0 +1,129 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commander
+
+import (
+	"context"
+	"strings"
+)
+
+// Permission determines who may run a command.
+type Permission int
+
+const (
+	// Anyone can run the command.
+	Anyone Permission = iota
+
+	// The author of the issue or PR, as well as any org member, can run the command.
+	Author
+
+	// Any org member can run the command.
+	Member
+
+	// Only maintainers can run the command.
+	Maintainer
+)
+
+// Command describes a slash command that can be issued in issue or PR comments.
+type Command struct {
+	// Name of the command, without the leading slash
+	Name string
+
+	// Usage shows how to invoke the command, e.g. "/label <label>..."
+	Usage string
+
+	// Description is a one-line summary of what the command does
+	Description string
+
+	// Permission determines who is allowed to run the command
+	Permission Permission
+
+	// SubcommandPermissions overrides Permission for the subcommands named by the command's first argument
+	SubcommandPermissions map[string]Permission
+
+	// Handler executes the command, returning an optional message to reply with
+	Handler func(context context.Context, inv *Invocation) (string, error)
+}
+
+// permission returns who is allowed to run the command with the given arguments
+func (cmd *Command) permission(args []string) Permission {
+	if len(args) > 0 {
+		if perm, ok := cmd.SubcommandPermissions[strings.ToLower(args[0])]; ok {
+			return perm
+		}
+	}
+
+	return cmd.Permission
+}
+
+// Invocation holds the details of a single command being run.
+type Invocation struct {
+	OrgLogin      string
+	RepoName      string
+	Number        int      // the issue or PR number
+	IsPullRequest bool     // whether Number refers to a PR
+	Author        string   // the author of the issue or PR
+	Labels        []string // the labels currently on the issue or PR
+	Commenter     string   // the user who issued the command
+	Args          []string // the command's arguments
+}
+
+// HasLabel returns whether the issue or PR currently has the given label.
+func (inv *Invocation) HasLabel(label string) bool {
+	for _, l := range inv.Labels {
+		if strings.EqualFold(l, label) {
+			return true
+		}
+	}
+
+	return false
+}
+
+type parsedCommand struct {
+	name string
+	args []string
+}
+
+// parseCommands extracts the slash commands from a comment body. Each command must
+// be on a line of its own, and commands within fenced code blocks are ignored.
+func parseCommands(body string) []parsedCommand {
+	var result []parsedCommand
+
+	inCode := false
+	for _, line := range strings.Split(strings.ReplaceAll(body, "\r\n", "\n"), "\n") {
+		line = strings.TrimSpace(line)
+
+		if strings.HasPrefix(line, "```") {
+			inCode = !inCode
+			continue
+		}
+
+		if inCode || !strings.HasPrefix(line, "/") {
+			continue
+		}
+
+		fields := strings.Fields(line[1:])
+		if len(fields) == 0 {
+			continue
+		}
+
+		result = append(result, parsedCommand{
+			name: strings.ToLower(fields[0]),
+			args: fields[1:],
+		})
+	}
+
+	return result
+}
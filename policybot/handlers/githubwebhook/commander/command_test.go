@@ -0,0 +1,92 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commander
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseCommands(t *testing.T) {
+	cases := []struct {
+		name     string
+		body     string
+		expected []parsedCommand
+	}{
+		{"empty", "", nil},
+		{"no commands", "LGTM, thanks!", nil},
+		{"single", "/hold", []parsedCommand{{name: "hold", args: []string{}}}},
+		{"args", "/label area/networking  kind/bug", []parsedCommand{{name: "label", args: []string{"area/networking", "kind/bug"}}}},
+		{"case", "/Assign @foo", []parsedCommand{{name: "assign", args: []string{"@foo"}}}},
+		{"multiple", "Some text\r\n  /assign\r\n/milestone 1.31\r\nmore text", []parsedCommand{
+			{name: "assign", args: []string{}},
+			{name: "milestone", args: []string{"1.31"}},
+		}},
+		{"mid-line", "please run /close", nil},
+		{"bare slash", "/", nil},
+		{"code block", "```\n/close\n```\n/hold cancel", []parsedCommand{{name: "hold", args: []string{"cancel"}}}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := parseCommands(c.body)
+			if diff := cmp.Diff(c.expected, got, cmp.AllowUnexported(parsedCommand{})); diff != "" {
+				t.Errorf("unexpected result (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestEnabled(t *testing.T) {
+	all := &commandsRecord{}
+	if !all.enabled("label") {
+		t.Error("expected all commands to be enabled by default")
+	}
+
+	some := &commandsRecord{Commands: []string{"label", "Help"}, DisabledCommands: []string{"label"}}
+	if some.enabled("label") {
+		t.Error("expected disabled command to be disabled")
+	}
+	if !some.enabled("help") {
+		t.Error("expected listed command to be enabled")
+	}
+	if some.enabled("close") {
+		t.Error("expected unlisted command to be disabled")
+	}
+}
+
+func TestPermission(t *testing.T) {
+	cmd := &Command{Permission: Member, SubcommandPermissions: map[string]Permission{"explain": Anyone}}
+
+	cases := []struct {
+		name     string
+		args     []string
+		expected Permission
+	}{
+		{"no args", nil, Member},
+		{"other subcommand", []string{"staleproof"}, Member},
+		{"subcommand", []string{"explain"}, Anyone},
+		{"case", []string{"Explain"}, Anyone},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := cmd.permission(c.args); got != c.expected {
+				t.Errorf("expected permission %d, got %d", c.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,242 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commander
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/go-github/v26/github"
+
+	"istio.io/bots/policybot/handlers/githubwebhook"
+	"istio.io/bots/policybot/pkg/config"
+	"istio.io/bots/policybot/pkg/gh"
+	"istio.io/bots/policybot/pkg/storage/cache"
+	"istio.io/istio/pkg/log"
+)
+
+// Runs slash commands found in issue and PR comments.
+type Commander struct {
+	gc       *gh.ThrottledClient
+	cache    *cache.Cache
+	reg      *config.Registry
+	commands map[string]*Command
+}
+
+const commandSignature = "\n\n_Courtesy of your friendly command processor_."
+
+var scope = log.RegisterScope("commander", "Issue and PR slash command processor")
+
+// New creates a commander which knows about the built-in commands. Other subsystems can add
+// their own commands using Register.
+func New(gc *gh.ThrottledClient, cache *cache.Cache, reg *config.Registry) *Commander {
+	c := &Commander{
+		gc:       gc,
+		cache:    cache,
+		reg:      reg,
+		commands: make(map[string]*Command),
+	}
+
+	c.Register(c.builtins()...)
+	return c
+}
+
+// Register makes additional commands available. A command replaces any previously registered command of the same name.
+func (c *Commander) Register(commands ...*Command) {
+	for _, cmd := range commands {
+		c.commands[strings.ToLower(cmd.Name)] = cmd
+	}
+}
+
+var _ githubwebhook.Filter = &Commander{}
+
+// process an event arriving from GitHub
+func (c *Commander) Handle(context context.Context, event interface{}) {
+	ice, ok := event.(*github.IssueCommentEvent)
+	if !ok {
+		// not what we're looking for
+		scope.Debugf("Unknown event received: %T %+v", event, event)
+		return
+	}
+
+	scope.Infof("Received IssueCommentEvent: %s, %d, %s", ice.GetRepo().GetFullName(), ice.GetIssue().GetNumber(), ice.GetAction())
+
+	repo := ice.GetRepo().GetFullName()
+	number := ice.GetIssue().GetNumber()
+
+	if ice.GetAction() != "created" {
+		scope.Infof("Ignoring event for issue/PR %d from repo %s since it doesn't have a supported action: %s", number, repo, ice.GetAction())
+		return
+	}
+
+	r, ok := c.reg.SingleRecord(recordType, repo)
+	if !ok {
+		scope.Infof("Ignoring event for issue/PR %d from repo %s since commands aren't enabled for it", number, repo)
+		return
+	}
+	cr := r.(*commandsRecord)
+
+	commenter := ice.GetComment().GetUser().GetLogin()
+	if c.isRobot(commenter) {
+		scope.Infof("Ignoring event for issue/PR %d from repo %s since it comes from robot %s", number, repo, commenter)
+		return
+	}
+
+	parsed := parseCommands(ice.GetComment().GetBody())
+	if len(parsed) == 0 {
+		return
+	}
+
+	var labels []string
+	for _, l := range ice.GetIssue().Labels {
+		labels = append(labels, l.GetName())
+	}
+
+	var replies []string
+	for _, pc := range parsed {
+		inv := &Invocation{
+			OrgLogin:      ice.GetRepo().GetOwner().GetLogin(),
+			RepoName:      ice.GetRepo().GetName(),
+			Number:        number,
+			IsPullRequest: ice.GetIssue().IsPullRequest(),
+			Author:        ice.GetIssue().GetUser().GetLogin(),
+			Labels:        labels,
+			Commenter:     commenter,
+			Args:          pc.args,
+		}
+
+		reply, err := c.run(context, cr, pc.name, inv)
+		if err != nil {
+			scope.Errorf("Unable to run command /%s on issue/PR %d in repo %s: %v", pc.name, number, repo, err)
+			reply = fmt.Sprintf("`/%s` failed: %v", pc.name, err)
+		}
+
+		if reply != "" {
+			replies = append(replies, reply)
+		}
+	}
+
+	if len(replies) > 0 {
+		c.reply(context, ice.GetRepo().GetOwner().GetLogin(), ice.GetRepo().GetName(), number, commenter, replies)
+	}
+}
+
+func (c *Commander) run(context context.Context, cr *commandsRecord, name string, inv *Invocation) (string, error) {
+	cmd, ok := c.commands[name]
+	if !ok || !cr.enabled(name) {
+		// comments are full of things that look like commands, such as paths and commands meant for other bots
+		scope.Debugf("Ignoring unknown command /%s on issue/PR %d in repo %s/%s", name, inv.Number, inv.OrgLogin, inv.RepoName)
+		return "", nil
+	}
+
+	allowed, err := c.allowed(context, cmd.permission(inv.Args), inv)
+	if err != nil {
+		return "", err
+	} else if !allowed {
+		return fmt.Sprintf("You don't have permission to use `/%s`.", name), nil
+	}
+
+	scope.Infof("Running command /%s %s for %s on issue/PR %d in repo %s/%s",
+		name, strings.Join(inv.Args, " "), inv.Commenter, inv.Number, inv.OrgLogin, inv.RepoName)
+
+	return cmd.Handler(context, inv)
+}
+
+// allowed determines whether the commenter can run a command with the given permission
+func (c *Commander) allowed(context context.Context, perm Permission, inv *Invocation) (bool, error) {
+	switch perm {
+	case Anyone:
+		return true, nil
+
+	case Author:
+		if strings.EqualFold(inv.Commenter, inv.Author) {
+			return true, nil
+		}
+		return c.isMember(context, inv.OrgLogin, inv.Commenter)
+
+	case Member:
+		return c.isMember(context, inv.OrgLogin, inv.Commenter)
+
+	case Maintainer:
+		return c.isMaintainer(context, inv.OrgLogin, inv.Commenter)
+	}
+
+	return false, nil
+}
+
+func (c *Commander) isMember(context context.Context, orgLogin string, userLogin string) (bool, error) {
+	member, err := c.cache.ReadMember(context, orgLogin, userLogin)
+	if err != nil {
+		return false, fmt.Errorf("unable to read member %s from storage: %v", userLogin, err)
+	} else if member != nil {
+		return true, nil
+	}
+
+	// maintainers are usually also members, but be forgiving in case the member data is stale
+	return c.isMaintainer(context, orgLogin, userLogin)
+}
+
+func (c *Commander) isMaintainer(context context.Context, orgLogin string, userLogin string) (bool, error) {
+	maintainer, err := c.cache.ReadMaintainer(context, orgLogin, userLogin)
+	if err != nil {
+		return false, fmt.Errorf("unable to read maintainer %s from storage: %v", userLogin, err)
+	}
+
+	return maintainer != nil && !maintainer.Emeritus, nil
+}
+
+func (c *Commander) isRobot(login string) bool {
+	for _, r := range c.reg.Core().Robots {
+		if strings.EqualFold(r, login) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// available returns the commands available in a repo, sorted by name
+func (c *Commander) available(orgAndRepo string) []*Command {
+	r, ok := c.reg.SingleRecord(recordType, orgAndRepo)
+	if !ok {
+		return nil
+	}
+	cr := r.(*commandsRecord)
+
+	var result []*Command
+	for name, cmd := range c.commands {
+		if cr.enabled(name) {
+			result = append(result, cmd)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+
+	return result
+}
+
+func (c *Commander) reply(context context.Context, orgLogin string, repoName string, number int, commenter string, replies []string) {
+	body := "@" + commenter + ": " + strings.Join(replies, "\n\n") + commandSignature
+
+	if _, _, err := c.gc.ThrottledCall(func(client *github.Client) (interface{}, *github.Response, error) {
+		return client.Issues.CreateComment(context, orgLogin, repoName, number, &github.IssueComment{Body: &body})
+	}); err != nil {
+		scope.Errorf("Unable to reply to commands on issue/PR %d in repo %s/%s: %v", number, orgLogin, repoName, err)
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commander
+
+import (
+	"strings"
+
+	"istio.io/bots/policybot/pkg/config"
+)
+
+const recordType = "commands"
+
+type commandsRecord struct {
+	config.RecordBase
+
+	// Commands lists the names of the commands available in the repo. When empty, all known commands are available.
+	Commands []string
+
+	// DisabledCommands lists the names of commands that are not available in the repo.
+	DisabledCommands []string
+}
+
+func init() {
+	config.RegisterType(recordType, config.OnePerRepo, func() config.Record {
+		return new(commandsRecord)
+	})
+}
+
+// enabled returns whether the named command is available in the repos governed by this record.
+func (cr *commandsRecord) enabled(name string) bool {
+	for _, c := range cr.DisabledCommands {
+		if strings.EqualFold(c, name) {
+			return false
+		}
+	}
+
+	if len(cr.Commands) == 0 {
+		return true
+	}
+
+	for _, c := range cr.Commands {
+		if strings.EqualFold(c, name) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,74 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package holder
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/google/go-github/v26/github"
+
+	"istio.io/bots/policybot/handlers/githubwebhook/commander"
+	"istio.io/bots/policybot/pkg/config"
+	"istio.io/bots/policybot/pkg/gh"
+)
+
+// Commands returns the slash commands that control the hold label.
+func Commands(gc *gh.ThrottledClient, reg *config.Registry) []*commander.Command {
+	return []*commander.Command{
+		{
+			Name:        "hold",
+			Usage:       "/hold [cancel]",
+			Description: "Prevents the PR from being merged, or allows it again with `cancel`.",
+			Permission:  commander.Author,
+			Handler: func(context context.Context, inv *commander.Invocation) (string, error) {
+				if !inv.IsPullRequest {
+					return "", errors.New("only pull requests can be held")
+				}
+
+				r, ok := reg.SingleRecord(recordType, inv.OrgLogin+"/"+inv.RepoName)
+				if !ok || r.(*holdRecord).HoldLabel == "" {
+					return "", errors.New("holds aren't supported in this repo")
+				}
+				label := r.(*holdRecord).HoldLabel
+
+				if len(inv.Args) > 0 {
+					if len(inv.Args) != 1 || !strings.EqualFold(inv.Args[0], "cancel") {
+						return "", errors.New("expecting either `/hold` or `/hold cancel`")
+					}
+
+					if !inv.HasLabel(label) {
+						return "", nil
+					}
+
+					_, err := gc.ThrottledCallNoResult(func(client *github.Client) (*github.Response, error) {
+						return client.Issues.RemoveLabelForIssue(context, inv.OrgLogin, inv.RepoName, inv.Number, label)
+					})
+					return "", err
+				}
+
+				if inv.HasLabel(label) {
+					return "", nil
+				}
+
+				_, _, err := gc.ThrottledCall(func(client *github.Client) (interface{}, *github.Response, error) {
+					return client.Issues.AddLabelsToIssue(context, inv.OrgLogin, inv.RepoName, inv.Number, []string{label})
+				})
+				return "", err
+			},
+		},
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package holder
+
+import (
+	"context"
+	"strings"
+	"unicode"
+
+	"github.com/google/go-github/v26/github"
+
+	"istio.io/bots/policybot/handlers/githubwebhook"
+	"istio.io/bots/policybot/pkg/config"
+	"istio.io/bots/policybot/pkg/gh"
+	"istio.io/istio/pkg/log"
+)
+
+// Keeps work-in-progress PRs from being merged.
+type Holder struct {
+	gc  *gh.ThrottledClient
+	reg *config.Registry
+}
+
+var scope = log.RegisterScope("holder", "Applies the work-in-progress and hold labels to PRs")
+
+func New(gc *gh.ThrottledClient, reg *config.Registry) githubwebhook.Filter {
+	return &Holder{
+		gc:  gc,
+		reg: reg,
+	}
+}
+
+// process an event arriving from GitHub
+func (h *Holder) Handle(context context.Context, event interface{}) {
+	prp, ok := event.(*github.PullRequestEvent)
+	if !ok {
+		// not what we're looking for
+		scope.Debugf("Unknown event received: %T %+v", event, event)
+		return
+	}
+
+	scope.Infof("Received PullRequestEvent: %s, %d, %s", prp.GetRepo().GetFullName(), prp.GetPullRequest().GetNumber(), prp.GetAction())
+
+	repo := prp.GetRepo().GetFullName()
+	pr := prp.GetPullRequest()
+
+	action := prp.GetAction()
+	if action != "opened" && action != "reopened" && action != "edited" && action != "ready_for_review" && action != "converted_to_draft" {
+		scope.Infof("Ignoring event for PR %d from repo %s since it doesn't have a supported action: %s", pr.GetNumber(), repo, action)
+		return
+	}
+
+	r, ok := h.reg.SingleRecord(recordType, repo)
+	if !ok {
+		scope.Infof("Ignoring event for PR %d from repo %s since holds aren't managed for it", pr.GetNumber(), repo)
+		return
+	}
+	hr := r.(*holdRecord)
+
+	if hr.WIPLabel == "" {
+		return
+	}
+
+	hasLabel := false
+	for _, label := range pr.Labels {
+		if strings.EqualFold(label.GetName(), hr.WIPLabel) {
+			hasLabel = true
+			break
+		}
+	}
+
+	orgLogin := prp.GetRepo().GetOwner().GetLogin()
+	repoName := prp.GetRepo().GetName()
+
+	wip := pr.GetDraft() || isWIPTitle(pr.GetTitle(), hr.WIPMarkers)
+	if wip && !hasLabel {
+		scope.Infof("Marking PR %d in repo %s as a work in progress", pr.GetNumber(), repo)
+		if _, _, err := h.gc.ThrottledCall(func(client *github.Client) (interface{}, *github.Response, error) {
+			return client.Issues.AddLabelsToIssue(context, orgLogin, repoName, pr.GetNumber(), []string{hr.WIPLabel})
+		}); err != nil {
+			scope.Errorf("Unable to add label %s to PR %d in repo %s: %v", hr.WIPLabel, pr.GetNumber(), repo, err)
+		}
+	} else if !wip && hasLabel {
+		scope.Infof("PR %d in repo %s is no longer a work in progress", pr.GetNumber(), repo)
+		if _, err := h.gc.ThrottledCallNoResult(func(client *github.Client) (*github.Response, error) {
+			return client.Issues.RemoveLabelForIssue(context, orgLogin, repoName, pr.GetNumber(), hr.WIPLabel)
+		}); err != nil {
+			scope.Errorf("Unable to remove label %s from PR %d in repo %s: %v", hr.WIPLabel, pr.GetNumber(), repo, err)
+		}
+	}
+}
+
+// isWIPTitle returns whether a title starts with one of the markers as a distinct word
+func isWIPTitle(title string, markers []string) bool {
+	title = strings.TrimSpace(title)
+	for _, marker := range markers {
+		if marker == "" || len(title) < len(marker) || !strings.EqualFold(title[:len(marker)], marker) {
+			continue
+		}
+
+		rest := title[len(marker):]
+		if rest == "" {
+			return true
+		}
+
+		// avoid matching titles such as "Wipe the cache" for a WIP marker
+		last := rune(marker[len(marker)-1])
+		next := rune(rest[0])
+		if !isWordChar(last) || !isWordChar(next) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isWordChar(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
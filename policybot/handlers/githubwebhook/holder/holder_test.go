@@ -0,0 +1,45 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package holder
+
+import (
+	"testing"
+)
+
+func TestIsWIPTitle(t *testing.T) {
+	markers := []string{"WIP", "[WIP]"}
+
+	cases := []struct {
+		title    string
+		expected bool
+	}{
+		{"WIP", true},
+		{"WIP: add a feature", true},
+		{"wip add a feature", true},
+		{"[WIP] add a feature", true},
+		{"  [wip]add a feature", true},
+		{"Wipe the cache", false},
+		{"Add a feature (WIP)", false},
+		{"", false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			if got := isWIPTitle(c.title, markers); got != c.expected {
+				t.Errorf("Got %v, expected %v", got, c.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package holder
+
+import (
+	"istio.io/bots/policybot/pkg/config"
+)
+
+const recordType = "hold"
+
+type holdRecord struct {
+	config.RecordBase
+
+	// WIPLabel is applied to draft PRs and to PRs whose title starts with one of the WIP markers
+	WIPLabel string
+
+	// WIPMarkers are the title prefixes that indicate a PR is a work in progress, compared case-insensitively
+	WIPMarkers []string
+
+	// HoldLabel is applied and removed with the /hold and /hold cancel commands
+	HoldLabel string
+}
+
+func init() {
+	config.RegisterType(recordType, config.OnePerRepo, func() config.Record {
+		return &holdRecord{
+			WIPLabel:   "do-not-merge/work-in-progress",
+			WIPMarkers: []string{"WIP", "[WIP]"},
+			HoldLabel:  "do-not-merge/hold",
+		}
+	})
+}
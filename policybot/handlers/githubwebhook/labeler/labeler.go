@@ -17,100 +17,72 @@ package labeler
 import (
 	"context"
 	"fmt"
-	"regexp"
+	"strings"
+	"time"
 
 	"github.com/google/go-github/v26/github"
 
 	"istio.io/bots/policybot/handlers/githubwebhook"
 	"istio.io/bots/policybot/pkg/config"
 	"istio.io/bots/policybot/pkg/gh"
+	"istio.io/bots/policybot/pkg/rules"
 	"istio.io/bots/policybot/pkg/storage"
 	"istio.io/bots/policybot/pkg/storage/cache"
 	"istio.io/istio/pkg/log"
 )
 
-// Attaches labels to issues and PRs based on regex matches on the title, body, etc.
+// Attaches labels to issues and PRs based on rules matching their title, body, affected files, etc.
 type Labeler struct {
-	cache             *cache.Cache
-	gc                *gh.ThrottledClient
-	singleLineRegexes map[string]*regexp.Regexp
-	multiLineRegexes  map[string]*regexp.Regexp
-	reg               *config.Registry
+	cache *cache.Cache
+	store storage.Store
+	gc    *gh.ThrottledClient
+	exprs map[*autoLabelRecord]*rules.Expr
+	reg   *config.Registry
+}
+
+// An issue or PR that auto labels are evaluated against
+type target struct {
+	orgLogin string
+	repoName string
+	number   int64
+	subject  *rules.Subject
 }
 
 var scope = log.RegisterScope("labeler", "Issue and PR auto-labeler")
 
-func NewLabeler(gc *gh.ThrottledClient, cache *cache.Cache, reg *config.Registry) (githubwebhook.Filter, error) {
+func NewLabeler(gc *gh.ThrottledClient, store storage.Store, cache *cache.Cache, reg *config.Registry) (githubwebhook.Filter, error) {
 	l := &Labeler{
-		cache:             cache,
-		gc:                gc,
-		singleLineRegexes: make(map[string]*regexp.Regexp),
-		multiLineRegexes:  make(map[string]*regexp.Regexp),
-		reg:               reg,
+		cache: cache,
+		store: store,
+		gc:    gc,
+		exprs: make(map[*autoLabelRecord]*rules.Expr),
+		reg:   reg,
 	}
 
 	for _, r := range reg.Records(recordType, "*") {
 		al := r.(*autoLabelRecord)
-		if err := l.processAutoLabelRegexes(al); err != nil {
-			return nil, err
-		}
-	}
-
-	return l, nil
-}
-
-// Precompile all the regexes
-func (l *Labeler) processAutoLabelRegexes(al *autoLabelRecord) error {
-	for _, expr := range al.MatchAuthor {
-		r, err := regexp.Compile("(?i)" + expr)
-		if err != nil {
-			return fmt.Errorf("invalid regular expression %s: %v", expr, err)
-		}
-		l.singleLineRegexes[expr] = r
-	}
-
-	for _, expr := range al.MatchTitle {
-		r, err := regexp.Compile("(?i)" + expr)
-		if err != nil {
-			return fmt.Errorf("invalid regular expression %s: %v", expr, err)
-		}
-		l.singleLineRegexes[expr] = r
-	}
-
-	for _, expr := range al.MatchBody {
-		r, err := regexp.Compile("(?mi)" + expr)
-		if err != nil {
-			return fmt.Errorf("invalid regular expression %s: %v", expr, err)
-		}
-		l.multiLineRegexes[expr] = r
-	}
-
-	for _, expr := range al.AbsentLabels {
-		r, err := regexp.Compile("(?i)" + expr)
-		if err != nil {
-			return fmt.Errorf("invalid regular expression %s: %v", expr, err)
-		}
-		l.singleLineRegexes[expr] = r
-	}
-
-	for _, expr := range al.PresentLabels {
-		r, err := regexp.Compile("(?i)" + expr)
+		expr, err := rules.Compile(al.rule())
 		if err != nil {
-			return fmt.Errorf("invalid regular expression %s: %v", expr, err)
+			return nil, fmt.Errorf("invalid auto label %s: %v", al.Name, err)
 		}
-		l.singleLineRegexes[expr] = r
+		l.exprs[al] = expr
 	}
 
-	return nil
+	return l, nil
 }
 
 // process an event arriving from GitHub
 func (l *Labeler) Handle(context context.Context, event interface{}) {
 	action := ""
 	repo := ""
+	orgLogin := ""
+	repoName := ""
+	sender := ""
 	number := 0
+	label := ""
 	var issue *storage.Issue
 	var pr *storage.PullRequest
+	var ghpr *github.PullRequest
 
 	switch p := event.(type) {
 	case *github.IssuesEvent:
@@ -118,7 +90,11 @@ func (l *Labeler) Handle(context context.Context, event interface{}) {
 
 		action = p.GetAction()
 		repo = p.GetRepo().GetFullName()
+		orgLogin = p.GetRepo().GetOwner().GetLogin()
+		repoName = p.GetRepo().GetName()
+		sender = p.GetSender().GetLogin()
 		number = p.GetIssue().GetNumber()
+		label = p.GetLabel().GetName()
 		issue = gh.ConvertIssue(
 			p.GetRepo().GetOwner().GetLogin(),
 			p.GetRepo().GetName(),
@@ -129,7 +105,12 @@ func (l *Labeler) Handle(context context.Context, event interface{}) {
 
 		action = p.GetAction()
 		repo = p.GetRepo().GetFullName()
+		orgLogin = p.GetRepo().GetOwner().GetLogin()
+		repoName = p.GetRepo().GetName()
+		sender = p.GetSender().GetLogin()
 		number = p.GetPullRequest().GetNumber()
+		label = p.GetLabel().GetName()
+		ghpr = p.GetPullRequest()
 		pr = gh.ConvertPullRequest(
 			p.GetRepo().GetOwner().GetLogin(),
 			p.GetRepo().GetName(),
@@ -142,12 +123,25 @@ func (l *Labeler) Handle(context context.Context, event interface{}) {
 		return
 	}
 
-	if action != "opened" {
+	if action == "labeled" {
+		if !l.isRobot(sender) {
+			l.recordReapplied(context, orgLogin, repoName, int64(number), label, sender)
+		}
+		return
+	}
+
+	if action != "opened" && action != "edited" && action != "reopened" && action != "synchronize" {
 		// not what we care about
 		scope.Infof("Ignoring event for issue/PR %d from repo %s since it doesn't have a supported action: %s", number, repo, action)
 		return
 	}
 
+	if action == "edited" && l.isRobot(sender) {
+		// edits done by robots, such as the boilerplate cleaner, are no reason to reconsider labels
+		scope.Infof("Ignoring event for issue/PR %d from repo %s since it was edited by robot %s", number, repo, sender)
+		return
+	}
+
 	// see if the event is in a repo we're monitoring
 	autoLabels := l.reg.Records(recordType, repo)
 	if len(autoLabels) == 0 {
@@ -158,150 +152,209 @@ func (l *Labeler) Handle(context context.Context, event interface{}) {
 	scope.Infof("Processing event for issue/PR %d from repo %s, %s", number, repo, action)
 
 	if issue != nil {
-		l.processIssue(context, issue, autoLabels)
-	} else {
-		l.processPullRequest(context, pr, autoLabels)
+		l.process(context, &target{
+			orgLogin: issue.OrgLogin,
+			repoName: issue.RepoName,
+			number:   issue.IssueNumber,
+			subject:  rules.IssueSubject(issue),
+		}, autoLabels)
+		return
 	}
-}
 
-func (l *Labeler) processIssue(context context.Context, issue *storage.Issue, als []config.Record) {
-	// get all the issue's labels
-	var labels []*storage.Label
-	for _, labelName := range issue.Labels {
-		label, err := l.cache.ReadLabel(context, issue.OrgLogin, issue.RepoName, labelName)
-		if err != nil {
-			scope.Errorf("Unable to get labels for issue/pr %d in repo %s/%s: %v", issue.IssueNumber, issue.OrgLogin, issue.RepoName, err)
-			return
-		} else if label != nil {
-			labels = append(labels, label)
-		}
+	files, err := l.getFiles(context, pr, autoLabels)
+	if err != nil {
+		scope.Errorf("Unable to get files for PR %d in repo %s: %v", number, repo, err)
+		return
 	}
 
-	// find any matching global auto labels
-	var toApply []string
-	var toRemove []string
+	subject := rules.PullRequestSubject(pr)
+	subject.Files = files
+	subject.AuthorAssociation = ghpr.GetAuthorAssociation()
+	subject.Changes = ghpr.GetAdditions() + ghpr.GetDeletions()
+
+	l.process(context, &target{
+		orgLogin: pr.OrgLogin,
+		repoName: pr.RepoName,
+		number:   pr.PullRequestNumber,
+		subject:  subject,
+	}, autoLabels)
+}
 
-	// find any matching auto labels
+// getFiles returns the set of files affected by a PR, if any of the given auto labels need them.
+func (l *Labeler) getFiles(context context.Context, pr *storage.PullRequest, als []config.Record) ([]string, error) {
+	needFiles := false
 	for _, r := range als {
-		al := r.(*autoLabelRecord)
-
-		if l.matchAutoLabel(al, issue.Author, issue.Title, issue.Body, labels) {
-			toApply = append(toApply, al.LabelsToApply...)
-			toRemove = append(toRemove, al.LabelsToRemove...)
+		if l.exprs[r.(*autoLabelRecord)].NeedsFiles() {
+			needFiles = true
+			break
 		}
 	}
 
-	if len(toApply) > 0 {
-		if _, _, err := l.gc.ThrottledCall(func(client *github.Client) (interface{}, *github.Response, error) {
-			return client.Issues.AddLabelsToIssue(context, issue.OrgLogin, issue.RepoName, int(issue.IssueNumber), toApply)
-		}); err != nil {
-			scope.Errorf("Unable to set labels on issue/PR %d in repo %s/%s: %v", issue.IssueNumber, issue.OrgLogin, issue.RepoName, err)
-			return
-		}
+	if !needFiles {
+		return nil, nil
 	}
 
-	scope.Infof("Applied %d label(s) to issue/PR %d from repo %s/%s", len(toApply), issue.IssueNumber, issue.OrgLogin, issue.RepoName)
+	// NOTE: the refresher filter normally stores the PR along with its files before we get here
+	stored, err := l.cache.ReadPullRequest(context, pr.OrgLogin, pr.RepoName, int(pr.PullRequestNumber))
+	if err != nil {
+		return nil, err
+	}
 
-	if len(toRemove) > 0 {
-		for _, label := range toRemove {
-			if _, err := l.gc.ThrottledCallNoResult(func(client *github.Client) (*github.Response, error) {
-				return client.Issues.RemoveLabelForIssue(context, issue.OrgLogin, issue.RepoName, int(issue.IssueNumber), label)
-			}); err != nil {
-				scope.Errorf("Unable to remove labels on issue/PR %d in repo %s/%s: %v", issue.IssueNumber, issue.OrgLogin, issue.RepoName, err)
-				return
-			}
-		}
+	if stored != nil && len(stored.Files) > 0 && stored.HeadCommit == pr.HeadCommit {
+		return stored.Files, nil
 	}
 
-	scope.Infof("Removed %d label(s) from issue/PR %d from repo %s/%s", len(toRemove), issue.IssueNumber, issue.OrgLogin, issue.RepoName)
+	var files []string
+	if err := l.gc.FetchFiles(context, pr.OrgLogin, pr.RepoName, int(pr.PullRequestNumber), func(f []string) error {
+		files = append(files, f...)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return files, nil
 }
 
-func (l *Labeler) processPullRequest(context context.Context, pr *storage.PullRequest, als []config.Record) {
-	// get all the pr's labels
-	var labels []*storage.Label
-	for _, labelName := range pr.Labels {
-		label, err := l.cache.ReadLabel(context, pr.OrgLogin, pr.RepoName, labelName)
-		if err != nil {
-			scope.Errorf("Unable to get labels for pr %d in repo %s/%s: %v", pr.PullRequestNumber, pr.OrgLogin, pr.RepoName, err)
-			return
-		} else if label != nil {
-			labels = append(labels, label)
-		}
+func (l *Labeler) process(context context.Context, t *target, als []config.Record) {
+	// get the labels we applied in the past
+	botLabels := make(map[string]*storage.BotLabel)
+	if err := l.store.QueryBotLabelsByIssue(context, t.orgLogin, t.repoName, int(t.number), func(bl *storage.BotLabel) error {
+		botLabels[strings.ToLower(bl.LabelName)] = bl
+		return nil
+	}); err != nil {
+		scope.Errorf("Unable to get bot-applied labels for issue/PR %d in repo %s/%s: %v", t.number, t.orgLogin, t.repoName, err)
+		return
 	}
 
-	// find any matching auto labels
 	var toApply []string
+	var toRemove []string
+	var unmatched []string
+	matched := make(map[string]bool)
+
+	// find any matching auto labels
 	for _, r := range als {
 		al := r.(*autoLabelRecord)
 
-		if l.matchAutoLabel(al, pr.Author, pr.Title, pr.Body, labels) {
-			toApply = append(toApply, al.LabelsToApply...)
+		if l.exprs[al].Eval(t.subject) {
+			for _, label := range al.LabelsToApply {
+				matched[strings.ToLower(label)] = true
+
+				if hasLabel(t.subject.Labels, label) || hasLabel(toApply, label) {
+					continue
+				}
+
+				if _, ok := botLabels[strings.ToLower(label)]; ok {
+					// we applied this label before and someone has since removed it, so respect that
+					scope.Infof("Not reapplying label %s to issue/PR %d in repo %s/%s since it was removed", label, t.number, t.orgLogin, t.repoName)
+					continue
+				}
+
+				toApply = append(toApply, label)
+			}
+
+			for _, label := range al.LabelsToRemove {
+				if hasLabel(t.subject.Labels, label) && !hasLabel(toRemove, label) {
+					toRemove = append(toRemove, label)
+				}
+			}
+		} else if al.removesWhenUnmatched(l.exprs[al].NeedsFiles()) {
+			// only consider the labels we applied ourselves, never those added or added back by humans
+			for _, label := range al.LabelsToApply {
+				if bl, ok := botLabels[strings.ToLower(label)]; ok && bl.ReappliedBy == nil && hasLabel(t.subject.Labels, label) {
+					unmatched = append(unmatched, label)
+				}
+			}
+		}
+	}
+
+	// a label is only stale if no other matching auto label calls for it
+	for _, label := range unmatched {
+		if !matched[strings.ToLower(label)] && !hasLabel(toRemove, label) {
+			toRemove = append(toRemove, label)
 		}
 	}
 
 	if len(toApply) > 0 {
 		if _, _, err := l.gc.ThrottledCall(func(client *github.Client) (interface{}, *github.Response, error) {
-			return client.Issues.AddLabelsToIssue(context, pr.OrgLogin, pr.RepoName, int(pr.PullRequestNumber), toApply)
+			return client.Issues.AddLabelsToIssue(context, t.orgLogin, t.repoName, int(t.number), toApply)
 		}); err != nil {
-			scope.Errorf("Unable to set labels on PR %d in repo %s/%s: %v", pr.PullRequestNumber, pr.OrgLogin, pr.RepoName, err)
+			scope.Errorf("Unable to set labels on issue/PR %d in repo %s/%s: %v", t.number, t.orgLogin, t.repoName, err)
 			return
 		}
-	}
 
-	scope.Infof("Applied %d label(s) to PR %d from repo %s/%s", len(toApply), pr.PullRequestNumber, pr.OrgLogin, pr.RepoName)
-}
+		now := time.Now()
+		applied := make([]*storage.BotLabel, 0, len(toApply))
+		for _, label := range toApply {
+			applied = append(applied, &storage.BotLabel{
+				OrgLogin:    t.orgLogin,
+				RepoName:    t.repoName,
+				IssueNumber: t.number,
+				LabelName:   label,
+				AppliedAt:   now,
+			})
+		}
 
-func (l *Labeler) matchAutoLabel(al *autoLabelRecord, author string, title string, body string, labels []*storage.Label) bool {
-	// if the author, title, and body don't match, we're done
-	if !l.authorMatch(al, author) && !l.titleMatch(al, title) && !l.bodyMatch(al, body) {
-		return false
+		if err := l.store.WriteBotLabels(context, applied); err != nil {
+			scope.Errorf("Unable to record labels applied to issue/PR %d in repo %s/%s: %v", t.number, t.orgLogin, t.repoName, err)
+		}
 	}
 
-	// if any of the 'must be absent' labels match, we bail
-	for _, expr := range al.AbsentLabels {
-		r := l.singleLineRegexes[expr]
-		for _, label := range labels {
-			if r.MatchString(label.LabelName) {
-				return false
-			}
+	scope.Infof("Applied %d label(s) to issue/PR %d from repo %s/%s", len(toApply), t.number, t.orgLogin, t.repoName)
+
+	var removed []*storage.BotLabel
+	for _, label := range toRemove {
+		if _, err := l.gc.ThrottledCallNoResult(func(client *github.Client) (*github.Response, error) {
+			return client.Issues.RemoveLabelForIssue(context, t.orgLogin, t.repoName, int(t.number), label)
+		}); err != nil {
+			scope.Errorf("Unable to remove labels on issue/PR %d in repo %s/%s: %v", t.number, t.orgLogin, t.repoName, err)
+			break
 		}
-	}
 
-	// if any of the 'must be present' labels don't match, we bail
-	for _, expr := range al.PresentLabels {
-		r := l.singleLineRegexes[expr]
-		found := false
-		for _, label := range labels {
-			if r.MatchString(label.LabelName) {
-				found = true
-				break
-			}
+		if bl, ok := botLabels[strings.ToLower(label)]; ok {
+			removed = append(removed, bl)
 		}
+	}
 
-		if !found {
-			return false
+	if len(removed) > 0 {
+		if err := l.store.DeleteBotLabels(context, removed); err != nil {
+			scope.Errorf("Unable to record labels removed from issue/PR %d in repo %s/%s: %v", t.number, t.orgLogin, t.repoName, err)
 		}
 	}
 
-	// we found a suitable candidate
-	return true
+	scope.Infof("Removed %d label(s) from issue/PR %d from repo %s/%s", len(toRemove), t.number, t.orgLogin, t.repoName)
 }
 
-func (l *Labeler) authorMatch(al *autoLabelRecord, author string) bool {
-	for _, expr := range al.MatchAuthor {
-		r := l.singleLineRegexes[expr]
-		if r.MatchString(author) {
-			return true
+// recordReapplied notes that a person added a label by hand that the bot applied before, so the label is no
+// longer the bot's to remove.
+func (l *Labeler) recordReapplied(context context.Context, orgLogin string, repoName string, number int64, label string, sender string) {
+	var reapplied *storage.BotLabel
+	if err := l.store.QueryBotLabelsByIssue(context, orgLogin, repoName, int(number), func(bl *storage.BotLabel) error {
+		if strings.EqualFold(bl.LabelName, label) {
+			reapplied = bl
 		}
+		return nil
+	}); err != nil {
+		scope.Errorf("Unable to get bot-applied labels for issue/PR %d in repo %s/%s: %v", number, orgLogin, repoName, err)
+		return
 	}
 
-	return false
+	if reapplied == nil || reapplied.ReappliedBy != nil {
+		// not one of ours, or already handed over
+		return
+	}
+
+	reapplied.ReappliedBy = &sender
+	if err := l.store.WriteBotLabels(context, []*storage.BotLabel{reapplied}); err != nil {
+		scope.Errorf("Unable to record label %s reapplied to issue/PR %d in repo %s/%s: %v", label, number, orgLogin, repoName, err)
+		return
+	}
+
+	scope.Infof("Label %s on issue/PR %d in repo %s/%s was added back by %s", label, number, orgLogin, repoName, sender)
 }
 
-func (l *Labeler) titleMatch(al *autoLabelRecord, title string) bool {
-	for _, expr := range al.MatchTitle {
-		r := l.singleLineRegexes[expr]
-		if r.MatchString(title) {
+func (l *Labeler) isRobot(login string) bool {
+	for _, r := range l.reg.Core().Robots {
+		if strings.EqualFold(r, login) {
 			return true
 		}
 	}
@@ -309,10 +362,9 @@ func (l *Labeler) titleMatch(al *autoLabelRecord, title string) bool {
 	return false
 }
 
-func (l *Labeler) bodyMatch(al *autoLabelRecord, body string) bool {
-	for _, expr := range al.MatchBody {
-		r := l.multiLineRegexes[expr]
-		if r.MatchString(body) {
+func hasLabel(labels []string, label string) bool {
+	for _, l := range labels {
+		if strings.EqualFold(l, label) {
 			return true
 		}
 	}
@@ -16,6 +16,7 @@ package labeler
 
 import (
 	"istio.io/bots/policybot/pkg/config"
+	"istio.io/bots/policybot/pkg/rules"
 )
 
 const recordType = "autolabel"
@@ -38,15 +39,85 @@ type autoLabelRecord struct {
 	// PresentLabels represents labels that must be on the PR or issue
 	PresentLabels []string // regexes
 
+	// MatchFiles represents files that must be affected by the PR. This is ignored for issues.
+	MatchFiles []string // globs
+
+	// AbsentFiles represents files that must not be affected by the PR. This is ignored for issues.
+	AbsentFiles []string // globs
+
+	// Rule expresses additional conditions that must hold, beyond the ones above
+	Rule *rules.Rule
+
 	// The labels to apply when any of the Match* expressions match and none of the Absent* expressions do.
 	LabelsToApply []string
 
 	// The labels to remove when any of the Match* expressions match and none of the Absent* expressions do.
 	LabelsToRemove []string
+
+	// RemoveWhenUnmatched controls whether labels previously applied by this record get removed once the
+	// issue or PR no longer matches. Only labels the bot applied itself are removed, never those added by humans.
+	// When unset, labels that depend on a PR's files are removed so they follow the diff, and others are kept.
+	RemoveWhenUnmatched *bool
 }
 
 func init() {
 	config.RegisterType(recordType, config.MultiplePerRepo, func() config.Record {
 		return new(autoLabelRecord)
 	})
+
+	rules.RegisterSource(recordType, func(r config.Record) (string, *rules.Rule) {
+		al := r.(*autoLabelRecord)
+		return al.Name, al.rule()
+	})
+}
+
+// removesWhenUnmatched tells whether the labels applied by the record should be removed once the issue or PR
+// no longer matches, given whether the record's conditions look at the files affected by a PR.
+func (al *autoLabelRecord) removesWhenUnmatched(needsFiles bool) bool {
+	if al.RemoveWhenUnmatched != nil {
+		return *al.RemoveWhenUnmatched
+	}
+
+	return needsFiles
+}
+
+// rule returns the conditions expressed by the record as a single rule
+func (al *autoLabelRecord) rule() *rules.Rule {
+	var result []*rules.Rule
+
+	match := []*rules.Rule{}
+	for _, expr := range al.MatchAuthor {
+		match = append(match, &rules.Rule{Author: expr})
+	}
+
+	for _, expr := range al.MatchTitle {
+		match = append(match, &rules.Rule{Title: expr})
+	}
+
+	for _, expr := range al.MatchBody {
+		match = append(match, &rules.Rule{Body: expr})
+	}
+
+	for _, glob := range al.MatchFiles {
+		match = append(match, &rules.Rule{Glob: glob})
+	}
+
+	// without any of the Match* fields, the record only matches through its rule
+	if len(match) > 0 || al.Rule == nil {
+		result = append(result, &rules.Rule{Any: match})
+	}
+
+	for _, glob := range al.AbsentFiles {
+		result = append(result, &rules.Rule{Not: &rules.Rule{Glob: glob}})
+	}
+
+	for _, expr := range al.AbsentLabels {
+		result = append(result, &rules.Rule{Not: &rules.Rule{Label: expr}})
+	}
+
+	for _, expr := range al.PresentLabels {
+		result = append(result, &rules.Rule{Label: expr})
+	}
+
+	return rules.All(append(result, al.Rule)...)
 }
@@ -0,0 +1,121 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package labeler
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"sigs.k8s.io/yaml"
+
+	"istio.io/bots/policybot/pkg/rules"
+)
+
+func TestConfiguredAutoLabelsCompile(t *testing.T) {
+	configPath := "../../../config/autolabels"
+	configDir, err := os.ReadDir(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, f := range configDir {
+		t.Run(f.Name(), func(t *testing.T) {
+			b, err := os.ReadFile(filepath.Join(configPath, f.Name()))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var al autoLabelRecord
+			if err := yaml.Unmarshal(b, &al); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := rules.Compile(al.rule()); err != nil {
+				t.Errorf("Unable to compile auto label: %v", err)
+			}
+		})
+	}
+}
+
+func TestRule(t *testing.T) {
+	al := &autoLabelRecord{
+		MatchBody:     []string{`\[ ?x ?\] ?Ambient`},
+		MatchFiles:    []string{"ambient/**"},
+		AbsentLabels:  []string{"area/.?"},
+		PresentLabels: []string{"kind/"},
+	}
+
+	expr, err := rules.Compile(al.rule())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name     string
+		subject  *rules.Subject
+		expected bool
+	}{
+		{"body", &rules.Subject{Body: "- [x] Ambient", Labels: []string{"kind/bug"}}, true},
+		{"files", &rules.Subject{Files: []string{"ambient/foo.go"}, Labels: []string{"kind/bug"}}, true},
+		{"no match", &rules.Subject{Body: "- [ ] Ambient", Labels: []string{"kind/bug"}}, false},
+		{"absent label", &rules.Subject{Body: "- [x] Ambient", Labels: []string{"kind/bug", "area/networking"}}, false},
+		{"missing present label", &rules.Subject{Body: "- [x] Ambient"}, false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := expr.Eval(c.subject); got != c.expected {
+				t.Errorf("Got %v, expected %v", got, c.expected)
+			}
+		})
+	}
+
+	// a record with nothing to match on never matches
+	expr, err = rules.Compile((&autoLabelRecord{}).rule())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expr.Eval(&rules.Subject{Title: "anything"}) {
+		t.Errorf("Expected an empty auto label not to match")
+	}
+}
+
+func TestRemovesWhenUnmatched(t *testing.T) {
+	yes := true
+	no := false
+
+	cases := []struct {
+		name       string
+		remove     *bool
+		needsFiles bool
+		expected   bool
+	}{
+		{"path labels follow the diff", nil, true, true},
+		{"content labels stay", nil, false, false},
+		{"path labels kept on request", &no, true, false},
+		{"content labels removed on request", &yes, false, true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			al := &autoLabelRecord{RemoveWhenUnmatched: c.remove}
+			if got := al.removesWhenUnmatched(c.needsFiles); got != c.expected {
+				t.Errorf("Got %v, expected %v", got, c.expected)
+			}
+		})
+	}
+}
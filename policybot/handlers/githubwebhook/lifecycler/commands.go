@@ -0,0 +1,70 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lifecycler
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"istio.io/bots/policybot/handlers/githubwebhook/commander"
+	"istio.io/bots/policybot/mgrs/lifecyclemgr"
+	"istio.io/bots/policybot/pkg/storage"
+)
+
+var errUsage = errors.New("expecting `/lifecycle staleproof`, `/lifecycle waiting-on-author`, or `/lifecycle explain`")
+
+// Commands returns the slash commands that drive the lifecycle manager.
+func Commands(lm *lifecyclemgr.LifecycleMgr) []*commander.Command {
+	return []*commander.Command{
+		{
+			Name:        "lifecycle",
+			Usage:       "/lifecycle staleproof|waiting-on-author|explain",
+			Description: "Prevents the issue or PR from going stale, waits for its author to respond, or explains its recent lifecycle changes.",
+			Permission:  commander.Member,
+			SubcommandPermissions: map[string]commander.Permission{
+				"explain": commander.Anyone,
+			},
+			Handler: func(context context.Context, inv *commander.Invocation) (string, error) {
+				if len(inv.Args) != 1 {
+					return "", errUsage
+				}
+
+				issue := invocationIssue(inv)
+				switch strings.ToLower(inv.Args[0]) {
+				case "staleproof":
+					return "", lm.MakeStaleproof(context, issue)
+				case "waiting-on-author":
+					return "", lm.WaitOnAuthor(context, issue)
+				case "explain":
+					return lm.Explain(context, issue)
+				}
+
+				return "", errUsage
+			},
+		},
+	}
+}
+
+// invocationIssue returns the issue or PR a command was invoked on
+func invocationIssue(inv *commander.Invocation) *storage.Issue {
+	return &storage.Issue{
+		OrgLogin:    inv.OrgLogin,
+		RepoName:    inv.RepoName,
+		IssueNumber: int64(inv.Number),
+		Author:      inv.Author,
+		Labels:      inv.Labels,
+	}
+}
@@ -16,6 +16,7 @@ package lifecycler
 
 import (
 	"context"
+	"strings"
 
 	"github.com/google/go-github/v26/github"
 
@@ -57,6 +58,7 @@ func (l *Lifecycler) Handle(context context.Context, event interface{}) {
 	var issue *storage.Issue
 	var pr *storage.PullRequest
 	var sender *github.User
+	comment := false
 
 	switch p := event.(type) {
 	case *github.IssuesEvent:
@@ -74,6 +76,7 @@ func (l *Lifecycler) Handle(context context.Context, event interface{}) {
 	case *github.IssueCommentEvent:
 		scope.Infof("Received IssueCommentEvent: %s, %d, %s", p.GetRepo().GetFullName(), p.GetIssue().GetNumber(), p.GetAction())
 
+		comment = p.GetAction() == "created"
 		sender = p.GetSender()
 		action = p.GetAction()
 		repo = p.GetRepo().GetFullName()
@@ -162,7 +165,10 @@ func (l *Lifecycler) Handle(context context.Context, event interface{}) {
 			return
 		}
 
-		if member == nil {
+		// the author commenting may end a wait on them, even if they're not a member
+		authorReply := comment && strings.EqualFold(user, issue.Author)
+
+		if member == nil && !authorReply {
 			// if event is not from a member, it won't affect the lifecycle so return promptly
 			scope.Infof("Ignoring event for issue/PR %d from repo %s since it wasn't caused by an org member", number, repo)
 			return
@@ -17,85 +17,69 @@ package nagger
 import (
 	"context"
 	"fmt"
-	"regexp"
+	"strings"
 
 	"github.com/google/go-github/v26/github"
 
 	"istio.io/bots/policybot/handlers/githubwebhook"
 	"istio.io/bots/policybot/pkg/config"
 	"istio.io/bots/policybot/pkg/gh"
+	"istio.io/bots/policybot/pkg/rules"
 	"istio.io/bots/policybot/pkg/storage"
 	"istio.io/bots/policybot/pkg/storage/cache"
 	"istio.io/istio/pkg/log"
 )
 
-// Generates nagging messages in PRs based on regex matches on the title, body, and affected files
+// Generates nagging messages in PRs based on their title, body, affected files, labels, author, size, and base branch
 type Nagger struct {
-	cache             *cache.Cache
-	gc                *gh.ThrottledClient
-	multiLineRegexes  map[string]*regexp.Regexp
-	singleLineRegexes map[string]*regexp.Regexp
-	reg               *config.Registry
+	cache *cache.Cache
+	gc    *gh.ThrottledClient
+	nags  map[*nagRecord]*compiledNag
+	reg   *config.Registry
+}
+
+type compiledNag struct {
+	// decides whether to nag
+	expr *rules.Expr
 }
 
 const nagSignature = "\n\n_Courtesy of your friendly test nag_."
 
+// the context of the commit status used for nags that are reported as such
+const nagStatusContext = "nags"
+
+// GitHub truncates status descriptions beyond this many characters
+const maxStatusDescription = 140
+
 var scope = log.RegisterScope("nagger", "The GitHub test nagger")
 
 func NewNagger(gc *gh.ThrottledClient, cache *cache.Cache, reg *config.Registry) (githubwebhook.Filter, error) {
 	n := &Nagger{
-		cache:             cache,
-		gc:                gc,
-		multiLineRegexes:  make(map[string]*regexp.Regexp),
-		singleLineRegexes: make(map[string]*regexp.Regexp),
-		reg:               reg,
+		cache: cache,
+		gc:    gc,
+		nags:  make(map[*nagRecord]*compiledNag),
+		reg:   reg,
 	}
 
 	for _, r := range reg.Records(recordType, "*") {
 		nag := r.(*nagRecord)
-		if err := n.processNagRegexes(nag); err != nil {
+		cn, err := compileNag(nag)
+		if err != nil {
 			return nil, err
 		}
+		n.nags[nag] = cn
 	}
 
 	return n, nil
 }
 
-// Precompile all the regexes
-func (n *Nagger) processNagRegexes(nag *nagRecord) error {
-	for _, expr := range nag.MatchTitle {
-		r, err := regexp.Compile("(?i)" + expr)
-		if err != nil {
-			return fmt.Errorf("invalid regular expression %s: %v", expr, err)
-		}
-		n.singleLineRegexes[expr] = r
-	}
-
-	for _, expr := range nag.MatchBody {
-		r, err := regexp.Compile("(?mi)" + expr)
-		if err != nil {
-			return fmt.Errorf("invalid regular expression %s: %v", expr, err)
-		}
-		n.multiLineRegexes[expr] = r
-	}
-
-	for _, expr := range nag.MatchFiles {
-		r, err := regexp.Compile("(?i)" + expr)
-		if err != nil {
-			return fmt.Errorf("invalid regular expression %s: %v", expr, err)
-		}
-		n.singleLineRegexes[expr] = r
-	}
-
-	for _, expr := range nag.AbsentFiles {
-		r, err := regexp.Compile("(?i)" + expr)
-		if err != nil {
-			return fmt.Errorf("invalid regular expression %s: %v", expr, err)
-		}
-		n.singleLineRegexes[expr] = r
+func compileNag(nag *nagRecord) (*compiledNag, error) {
+	expr, err := rules.Compile(nag.rule())
+	if err != nil {
+		return nil, fmt.Errorf("invalid nag %s: %v", nag.Name, err)
 	}
 
-	return nil
+	return &compiledNag{expr: expr}, nil
 }
 
 // process an event arriving from GitHub
@@ -110,7 +94,7 @@ func (n *Nagger) Handle(context context.Context, event interface{}) {
 	scope.Infof("Received PullRequestEvent: %s, %d, %s", prp.GetRepo().GetFullName(), prp.GetPullRequest().GetNumber(), prp.GetAction())
 
 	action := prp.GetAction()
-	if action != "opened" && action != "edited" && action != "synchronize" {
+	if action != "opened" && action != "reopened" && action != "edited" && action != "synchronize" && action != "labeled" && action != "unlabeled" {
 		scope.Infof("Ignoring event for PR %d from repo %s since it doesn't have a supported action: %s", prp.GetNumber(), prp.GetRepo().GetFullName(), action)
 		return
 	}
@@ -131,101 +115,100 @@ func (n *Nagger) Handle(context context.Context, event interface{}) {
 
 	scope.Infof("Processing PR %d from repo %s", prp.GetNumber(), prp.GetRepo().GetFullName())
 
-	n.processPR(context, pr, nags)
+	n.processPR(context, pr, prp.GetPullRequest(), nags)
 }
 
 // process a PR
-func (n *Nagger) processPR(context context.Context, pr *storage.PullRequest, nags []config.Record) {
-	body := pr.Body
-	title := pr.Title
+func (n *Nagger) processPR(context context.Context, pr *storage.PullRequest, ghpr *github.PullRequest, nags []config.Record) {
+	var matched []*nagRecord
+	var statusNags []*nagRecord
+	useStatus := false
 
-	contentMatches := make([]*nagRecord, 0)
+	subject := prSubject(pr, ghpr)
 	for _, r := range nags {
 		nag := r.(*nagRecord)
-		if n.titleMatch(nag, title) || n.bodyMatch(nag, body) {
-			contentMatches = append(contentMatches, nag)
-		}
-	}
-
-	if len(contentMatches) == 0 {
-		scope.Infof("Nothing to nag about for PR %d from repo %s/%s since its title and body don't match any nags",
-			pr.PullRequestNumber, pr.OrgLogin, pr.RepoName)
+		useStatus = useStatus || nag.Status
 
-		if err := n.gc.RemoveBotComment(context, pr.OrgLogin, pr.RepoName, int(pr.PullRequestNumber), nagSignature); err != nil {
-			scope.Error(err.Error())
+		if !n.nags[nag].expr.Eval(subject) {
+			continue
 		}
-		return
-	}
 
-	fileMatches := make([]*nagRecord, 0)
-	for _, nag := range contentMatches {
-		if n.fileMatch(nag.MatchFiles, pr.Files) {
-			fileMatches = append(fileMatches, nag)
+		scope.Infof("Nagging PR %d from repo %s/%s (nag: %s)", pr.PullRequestNumber, pr.OrgLogin, pr.RepoName, nag.Name)
+		matched = append(matched, nag)
+		if nag.Status {
+			statusNags = append(statusNags, nag)
 		}
 	}
 
-	if len(fileMatches) == 0 {
-		scope.Infof("Nothing to nag about for PR %d from repo %s/%s since its affected files don't match any nags",
-			pr.PullRequestNumber, pr.OrgLogin, pr.RepoName)
+	if len(matched) == 0 {
+		scope.Infof("Nothing to nag about for PR %d from repo %s/%s", pr.PullRequestNumber, pr.OrgLogin, pr.RepoName)
 		if err := n.gc.RemoveBotComment(context, pr.OrgLogin, pr.RepoName, int(pr.PullRequestNumber), nagSignature); err != nil {
 			scope.Error(err.Error())
 		}
-		return
+	} else {
+		if err := n.gc.AddOrReplaceBotComment(context, pr.OrgLogin, pr.RepoName, int(pr.PullRequestNumber), pr.Author,
+			combineMessages(matched), nagSignature); err != nil {
+			scope.Error(err.Error())
+		}
 	}
 
-	// at this point, fileMatches contains any nags whose MatchFile and (MatchTitle|MatchBody) regexes matched
-
-	// now see if the required files are present in order to avoid the nag comment
-	for _, nag := range fileMatches {
-		if !n.fileMatch(nag.AbsentFiles, pr.Files) {
-			scope.Infof("Nagging PR %d from repo %s/%s (nag: %s)", pr.PullRequestNumber, pr.OrgLogin, pr.RepoName, nag.Name)
-			if err := n.gc.AddOrReplaceBotComment(context, pr.OrgLogin, pr.RepoName, int(pr.PullRequestNumber), pr.Author, nag.Message, nagSignature); err != nil {
-				scope.Error(err.Error())
-			}
-
-			// only post a single nag comment per PR even if it's got multiple hits
-			return
+	if useStatus {
+		if err := n.reportStatus(context, pr, ghpr, statusNags); err != nil {
+			scope.Errorf("Unable to report nags as a commit status on PR %d from repo %s/%s: %v", pr.PullRequestNumber, pr.OrgLogin, pr.RepoName, err)
 		}
 	}
+}
 
-	scope.Infof("Nothing to nag about for PR %d from repo %s/%s since it contains required files", pr.PullRequestNumber, pr.OrgLogin, pr.RepoName)
+// prSubject returns what nags are evaluated against
+func prSubject(pr *storage.PullRequest, ghpr *github.PullRequest) *rules.Subject {
+	subject := rules.PullRequestSubject(pr)
+	subject.AuthorAssociation = ghpr.GetAuthorAssociation()
+	subject.Changes = ghpr.GetAdditions() + ghpr.GetDeletions()
+	return subject
+}
 
-	if err := n.gc.RemoveBotComment(context, pr.OrgLogin, pr.RepoName, int(pr.PullRequestNumber), nagSignature); err != nil {
-		scope.Error(err.Error())
+// combineMessages produces a single message out of several nags, as a checklist
+func combineMessages(nags []*nagRecord) string {
+	if len(nags) == 1 {
+		return nags[0].Message
 	}
-}
 
-func (n *Nagger) titleMatch(nag *nagRecord, title string) bool {
-	for _, expr := range nag.MatchTitle {
-		r := n.singleLineRegexes[expr]
-		if r.MatchString(title) {
-			return true
-		}
+	var sb strings.Builder
+	sb.WriteString("There are a few things to look at in this PR:\n\n")
+	for _, nag := range nags {
+		sb.WriteString("- [ ] " + strings.ReplaceAll(strings.TrimSpace(nag.Message), "\n", "\n  ") + "\n")
 	}
 
-	return false
+	return sb.String()
 }
 
-func (n *Nagger) bodyMatch(nag *nagRecord, body string) bool {
-	for _, expr := range nag.MatchBody {
-		r := n.multiLineRegexes[expr]
-		if r.MatchString(body) {
-			return true
+// reportStatus summarizes nags through a commit status on the PR's head commit, next to the comment that carries
+// their messages. Statuses work with the token the bot authenticates with, unlike check runs which are reserved to
+// GitHub Apps. Nags never block merging, so the status is always successful and its description names the nags that matched.
+func (n *Nagger) reportStatus(context context.Context, pr *storage.PullRequest, ghpr *github.PullRequest, nags []*nagRecord) error {
+	state := "success"
+	description := "Nothing to nag about"
+
+	if len(nags) > 0 {
+		names := make([]string, 0, len(nags))
+		for _, nag := range nags {
+			names = append(names, nag.Name)
 		}
-	}
 
-	return false
-}
-
-func (n *Nagger) fileMatch(expressions []string, files []string) bool {
-	for _, expr := range expressions {
-		r := n.singleLineRegexes[expr]
-		for _, f := range files {
-			if r.MatchString(f) {
-				return true
-			}
+		description = fmt.Sprintf("%d nag(s): %s", len(nags), strings.Join(names, ", "))
+		if len(description) > maxStatusDescription {
+			description = description[:maxStatusDescription-3] + "..."
 		}
 	}
 
-	return false
+	statusContext := nagStatusContext
+	_, _, err := n.gc.ThrottledCall(func(client *github.Client) (interface{}, *github.Response, error) {
+		return client.Repositories.CreateStatus(context, pr.OrgLogin, pr.RepoName, ghpr.GetHead().GetSHA(), &github.RepoStatus{
+			State:       &state,
+			Description: &description,
+			Context:     &statusContext,
+		})
+	})
+
+	return err
 }
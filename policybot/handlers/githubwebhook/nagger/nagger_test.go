@@ -0,0 +1,89 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nagger
+
+import (
+	"testing"
+
+	"github.com/google/go-github/v26/github"
+
+	"istio.io/bots/policybot/pkg/rules"
+	"istio.io/bots/policybot/pkg/storage"
+)
+
+func TestMatches(t *testing.T) {
+	pr := &storage.PullRequest{
+		Title:      "Fix a crash",
+		Body:       "This fixes things",
+		Files:      []string{"pkg/foo.go", "pkg/foo_test.go"},
+		Labels:     []string{"area/networking"},
+		BranchName: "release-1.4",
+	}
+
+	ghpr := &github.PullRequest{
+		AuthorAssociation: github.String("FIRST_TIME_CONTRIBUTOR"),
+		Additions:         github.Int(400),
+		Deletions:         github.Int(200),
+	}
+
+	cases := []struct {
+		name     string
+		nag      *nagRecord
+		expected bool
+	}{
+		{"title", &nagRecord{MatchTitle: []string{"fix"}, MatchFiles: []string{`\.go$`}}, true},
+		{"title mismatch", &nagRecord{MatchTitle: []string{"feature"}}, false},
+		{"absent files", &nagRecord{MatchFiles: []string{`\.go$`}, AbsentFiles: []string{`_test\.go$`}}, false},
+		{"labels", &nagRecord{MatchLabels: []string{"Area/Networking"}}, true},
+		{"labels mismatch", &nagRecord{MatchLabels: []string{"area/security"}}, false},
+		{"absent labels", &nagRecord{AbsentLabels: []string{"area/networking"}}, false},
+		{"author", &nagRecord{AuthorAssociations: []string{"FIRST_TIMER", "FIRST_TIME_CONTRIBUTOR"}}, true},
+		{"author mismatch", &nagRecord{AuthorAssociations: []string{"MEMBER"}}, false},
+		{"min changes", &nagRecord{MinChanges: 500}, true},
+		{"min changes mismatch", &nagRecord{MinChanges: 1000}, false},
+		{"max changes mismatch", &nagRecord{MaxChanges: 500}, false},
+		{"branch", &nagRecord{BaseBranches: []string{"^release-"}}, true},
+		{"branch mismatch", &nagRecord{BaseBranches: []string{"^master$"}}, false},
+		{"rule", &nagRecord{MatchTitle: []string{"fix"}, Rule: &rules.Rule{Not: &rules.Rule{Glob: "pkg/**"}}}, false},
+		{"combined", &nagRecord{MatchBody: []string{"fixes"}, MatchLabels: []string{"area/networking"}, MinChanges: 100}, true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cn, err := compileNag(c.nag)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if got := cn.expr.Eval(prSubject(pr, ghpr)); got != c.expected {
+				t.Errorf("Got %v, expected %v", got, c.expected)
+			}
+		})
+	}
+}
+
+func TestCombineMessages(t *testing.T) {
+	one := &nagRecord{Message: "Add a test."}
+	two := &nagRecord{Message: "Update the docs.\nThey're stale."}
+
+	if got := combineMessages([]*nagRecord{one}); got != "Add a test." {
+		t.Errorf("Got %q for a single nag", got)
+	}
+
+	expected := "There are a few things to look at in this PR:\n\n- [ ] Add a test.\n- [ ] Update the docs.\n  They're stale.\n"
+	if got := combineMessages([]*nagRecord{one, two}); got != expected {
+		t.Errorf("Got %q, expected %q", got, expected)
+	}
+}
@@ -15,7 +15,10 @@
 package nagger
 
 import (
+	"regexp"
+
 	"istio.io/bots/policybot/pkg/config"
+	"istio.io/bots/policybot/pkg/rules"
 )
 
 const recordType = "nag"
@@ -25,13 +28,18 @@ const recordType = "nag"
 //
 // The model is for a given PR:
 //
-//	if (PR title matches any of NatchTitle) || (PR body matches any of MatchBody) {
+//	if (PR title matches any of MatchTitle) || (PR body matches any of MatchBody) {
 //		if (PR files match any of MatchFiles) {
 //			if (PR does not match any of AbsentFiles) {
-//				produce a nag message in the PR
+//				if (PR satisfies the label, author, size, and branch conditions) {
+//					produce a nag message in the PR
+//				}
 //			}
 //		}
 //	}
+//
+// Conditions that aren't specified are ignored. When several nags match a PR, their messages
+// are combined into a single checklist.
 type nagRecord struct {
 	config.RecordBase
 
@@ -47,12 +55,109 @@ type nagRecord struct {
 	// AbsentFiles represents files that must not be in the PR
 	AbsentFiles []string // regexes
 
+	// MatchLabels represents labels of which the PR must have at least one
+	MatchLabels []string
+
+	// AbsentLabels represents labels the PR must not have
+	AbsentLabels []string
+
+	// AuthorAssociations represents the relationships to the repo the PR's author must have one of, using GitHub's
+	// values such as FIRST_TIME_CONTRIBUTOR, FIRST_TIMER, CONTRIBUTOR, MEMBER, COLLABORATOR, or OWNER
+	AuthorAssociations []string
+
+	// MinChanges and MaxChanges bound the number of lines added and deleted by the PR, zero means no bound
+	MinChanges int
+	MaxChanges int
+
+	// BaseBranches represents the branches the PR must target one of
+	BaseBranches []string // regexes
+
+	// Rule expresses additional conditions that must hold, beyond the ones above
+	Rule *rules.Rule
+
 	// The message to inject when any of the Match* expressions match and none of the Absent* expressions do.
 	Message string
+
+	// Status also reports the nag through a commit status naming it on the PR's head commit.
+	Status bool
 }
 
 func init() {
 	config.RegisterType(recordType, config.MultiplePerRepo, func() config.Record {
 		return new(nagRecord)
 	})
+
+	rules.RegisterSource(recordType, func(r config.Record) (string, *rules.Rule) {
+		nag := r.(*nagRecord)
+		return nag.Name, nag.rule()
+	})
+}
+
+// rule returns the conditions expressed by the record as a single rule
+func (nag *nagRecord) rule() *rules.Rule {
+	var result []*rules.Rule
+
+	if len(nag.MatchTitle) > 0 || len(nag.MatchBody) > 0 {
+		content := &rules.Rule{}
+		for _, expr := range nag.MatchTitle {
+			content.Any = append(content.Any, &rules.Rule{Title: expr})
+		}
+		for _, expr := range nag.MatchBody {
+			content.Any = append(content.Any, &rules.Rule{Body: expr})
+		}
+		result = append(result, content)
+	}
+
+	if len(nag.MatchFiles) > 0 {
+		result = append(result, nag.filesRule())
+	}
+
+	for _, expr := range nag.AbsentFiles {
+		result = append(result, &rules.Rule{Not: &rules.Rule{File: expr}})
+	}
+
+	if len(nag.MatchLabels) > 0 {
+		labels := &rules.Rule{}
+		for _, label := range nag.MatchLabels {
+			labels.Any = append(labels.Any, &rules.Rule{Label: exactly(label)})
+		}
+		result = append(result, labels)
+	}
+
+	for _, label := range nag.AbsentLabels {
+		result = append(result, &rules.Rule{Not: &rules.Rule{Label: exactly(label)}})
+	}
+
+	if len(nag.AuthorAssociations) > 0 {
+		result = append(result, &rules.Rule{Association: nag.AuthorAssociations})
+	}
+
+	if nag.MinChanges > 0 || nag.MaxChanges > 0 {
+		result = append(result, &rules.Rule{Size: &rules.SizeRange{Min: nag.MinChanges, Max: nag.MaxChanges}})
+	}
+
+	if len(nag.BaseBranches) > 0 {
+		branches := &rules.Rule{}
+		for _, expr := range nag.BaseBranches {
+			branches.Any = append(branches.Any, &rules.Rule{Branch: expr})
+		}
+		result = append(result, branches)
+	}
+
+	return rules.All(append(result, nag.Rule)...)
+}
+
+// filesRule returns a rule matching the files that trigger the nag
+func (nag *nagRecord) filesRule() *rules.Rule {
+	files := &rules.Rule{Any: []*rules.Rule{}}
+	for _, expr := range nag.MatchFiles {
+		files.Any = append(files.Any, &rules.Rule{File: expr})
+	}
+
+	return files
+}
+
+// exactly returns a regex matching the given string and nothing else
+func exactly(s string) string {
+	return "^" + regexp.QuoteMeta(s) + "$"
 }
@@ -0,0 +1,208 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rebaser
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v26/github"
+
+	"istio.io/bots/policybot/handlers/githubwebhook"
+	"istio.io/bots/policybot/mgrs/rebasemgr"
+	"istio.io/bots/policybot/pkg/config"
+	"istio.io/istio/pkg/log"
+)
+
+// Flags PRs that need a rebase whenever their base branch or the PRs themselves change.
+//
+// GitHub computes mergeability lazily so scans can take a while. Rather than holding up the webhook, scans run
+// in the background, one at a time per base branch. Events arriving while a scan runs are folded into a single
+// follow-up scan, and a push to the branch cancels a running scan of the whole branch, since it's now stale.
+type Rebaser struct {
+	reg *config.Registry
+	mgr *rebasemgr.RebaseMgr
+
+	// scans the given work and tells how long that may take, swapped out by tests
+	scan    func(ctx context.Context, key scanKey, w *work) error
+	timeout func(orgAndRepo string) time.Duration
+
+	mu     sync.Mutex
+	queues map[scanKey]*queue
+}
+
+// A base branch of a repo, which scans are serialized on
+type scanKey struct {
+	orgLogin string
+	repoName string
+	branch   string
+}
+
+// The PRs waiting to be scanned
+type work struct {
+	branch bool         // all open PRs targeting the branch
+	prs    map[int]bool // individual PRs, when not scanning the whole branch
+}
+
+// The scans of a base branch
+type queue struct {
+	pending       *work
+	running       bool
+	runningBranch bool
+	cancel        context.CancelFunc // cancels the running scan
+}
+
+var scope = log.RegisterScope("rebaser", "Flags PRs that conflict with their base branch")
+
+func New(reg *config.Registry, mgr *rebasemgr.RebaseMgr) githubwebhook.Filter {
+	r := &Rebaser{
+		reg:    reg,
+		mgr:    mgr,
+		queues: make(map[scanKey]*queue),
+	}
+	r.scan = r.manage
+	r.timeout = mgr.Timeout
+
+	return r
+}
+
+// process an event arriving from GitHub
+func (r *Rebaser) Handle(_ context.Context, event interface{}) {
+	switch p := event.(type) {
+	case *github.PushEvent:
+		repo := p.GetRepo().GetFullName()
+		if !strings.HasPrefix(p.GetRef(), "refs/heads/") {
+			scope.Debugf("Ignoring push to %s in repo %s since it's not a branch", p.GetRef(), repo)
+			return
+		}
+		branch := strings.TrimPrefix(p.GetRef(), "refs/heads/")
+
+		if _, ok := r.reg.SingleRecord(rebasemgr.RecordType, repo); !ok {
+			scope.Debugf("Ignoring push to branch %s in repo %s since rebases aren't tracked for it", branch, repo)
+			return
+		}
+
+		scope.Infof("Received push to branch %s in repo %s", branch, repo)
+
+		r.enqueue(scanKey{p.GetRepo().GetOwner().GetLogin(), p.GetRepo().GetName(), branch}, 0)
+
+	case *github.PullRequestEvent:
+		scope.Infof("Received PullRequestEvent: %s, %d, %s", p.GetRepo().GetFullName(), p.GetPullRequest().GetNumber(), p.GetAction())
+
+		repo := p.GetRepo().GetFullName()
+		pr := p.GetPullRequest()
+
+		action := p.GetAction()
+		if action != "opened" && action != "reopened" && action != "synchronize" {
+			scope.Infof("Ignoring event for PR %d from repo %s since it doesn't have a supported action: %s", pr.GetNumber(), repo, action)
+			return
+		}
+
+		if _, ok := r.reg.SingleRecord(rebasemgr.RecordType, repo); !ok {
+			scope.Infof("Ignoring event for PR %d from repo %s since rebases aren't tracked for it", pr.GetNumber(), repo)
+			return
+		}
+
+		r.enqueue(scanKey{p.GetRepo().GetOwner().GetLogin(), p.GetRepo().GetName(), pr.GetBase().GetRef()}, pr.GetNumber())
+
+	default:
+		// not what we're looking for
+		scope.Debugf("Unknown event received: %T %+v", p, p)
+	}
+}
+
+// enqueue adds a PR, or the whole branch when prNumber is 0, to the work waiting on a base branch. A scan is
+// started unless one is already running, in which case the work is picked up once it's done.
+func (r *Rebaser) enqueue(key scanKey, prNumber int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	q := r.queues[key]
+	if q == nil {
+		q = &queue{}
+		r.queues[key] = q
+	}
+
+	if q.pending == nil {
+		q.pending = &work{prs: make(map[int]bool)}
+	}
+
+	if prNumber == 0 {
+		// the whole branch covers any individual PRs
+		q.pending.branch = true
+		q.pending.prs = make(map[int]bool)
+
+		if q.running && q.runningBranch {
+			scope.Infof("Cancelling stale scan of branch %s in repo %s/%s", key.branch, key.orgLogin, key.repoName)
+			q.cancel()
+		}
+	} else if !q.pending.branch {
+		q.pending.prs[prNumber] = true
+	}
+
+	if !q.running {
+		q.running = true
+		go r.drain(key, q)
+	}
+}
+
+// drain runs the scans of a base branch until there's no more work waiting
+func (r *Rebaser) drain(key scanKey, q *queue) {
+	for {
+		r.mu.Lock()
+		w := q.pending
+		q.pending = nil
+		if w == nil {
+			q.running = false
+			delete(r.queues, key)
+			r.mu.Unlock()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), r.timeout(key.orgLogin+"/"+key.repoName))
+		q.cancel = cancel
+		q.runningBranch = w.branch
+		r.mu.Unlock()
+
+		if err := r.scan(ctx, key, w); errors.Is(err, context.Canceled) {
+			scope.Infof("Scan of branch %s in repo %s/%s was superseded", key.branch, key.orgLogin, key.repoName)
+		} else if err != nil {
+			scope.Errorf("Unable to check PRs targeting branch %s in repo %s/%s: %v", key.branch, key.orgLogin, key.repoName, err)
+		}
+		cancel()
+	}
+}
+
+// manage checks the PRs of a unit of work
+func (r *Rebaser) manage(ctx context.Context, key scanKey, w *work) error {
+	if w.branch {
+		return r.mgr.ManageBranch(ctx, key.orgLogin, key.repoName, key.branch)
+	}
+
+	for num := range w.prs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if err := r.mgr.ManagePR(ctx, key.orgLogin, key.repoName, num); err != nil {
+			scope.Errorf("Unable to check PR %d in repo %s/%s: %v", num, key.orgLogin, key.repoName, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,146 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rebaser
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// a fake scanner that blocks each scan until released, recording what it was asked to do
+type fakeScanner struct {
+	mu      sync.Mutex
+	scans   []*work
+	release chan struct{}
+	started chan struct{}
+	errs    []error
+}
+
+func (f *fakeScanner) scan(ctx context.Context, _ scanKey, w *work) error {
+	f.started <- struct{}{}
+
+	var err error
+	select {
+	case <-f.release:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	f.mu.Lock()
+	f.scans = append(f.scans, w)
+	f.errs = append(f.errs, err)
+	f.mu.Unlock()
+
+	return err
+}
+
+func newTestRebaser(f *fakeScanner) *Rebaser {
+	return &Rebaser{
+		queues:  make(map[scanKey]*queue),
+		scan:    f.scan,
+		timeout: func(string) time.Duration { return time.Minute },
+	}
+}
+
+func waitIdle(t *testing.T, r *Rebaser) {
+	t.Helper()
+	for i := 0; i < 1000; i++ {
+		r.mu.Lock()
+		idle := len(r.queues) == 0
+		r.mu.Unlock()
+		if idle {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatal("scans never finished")
+}
+
+func TestQueueDedupsPendingScans(t *testing.T) {
+	f := &fakeScanner{release: make(chan struct{}), started: make(chan struct{}, 10)}
+	r := newTestRebaser(f)
+	key := scanKey{"istio", "istio", "master"}
+
+	r.enqueue(key, 1)
+	<-f.started
+
+	// while the first scan runs, the same PR shows up repeatedly along with another one
+	r.enqueue(key, 2)
+	r.enqueue(key, 2)
+	r.enqueue(key, 3)
+
+	f.release <- struct{}{}
+	<-f.started
+	f.release <- struct{}{}
+	waitIdle(t, r)
+
+	if len(f.scans) != 2 {
+		t.Fatalf("Got %d scans, expected 2", len(f.scans))
+	}
+
+	if second := f.scans[1]; second.branch || len(second.prs) != 2 || !second.prs[2] || !second.prs[3] {
+		t.Errorf("Expected the follow-up scan to cover PRs 2 and 3 once, got %+v", second)
+	}
+}
+
+func TestQueuePushSupersedesBranchScan(t *testing.T) {
+	f := &fakeScanner{release: make(chan struct{}), started: make(chan struct{}, 10)}
+	r := newTestRebaser(f)
+	key := scanKey{"istio", "istio", "master"}
+
+	r.enqueue(key, 0)
+	<-f.started
+
+	// a PR event is covered by the pending branch scan, and the new push makes the running scan stale
+	r.enqueue(key, 5)
+	r.enqueue(key, 0)
+
+	<-f.started
+	f.release <- struct{}{}
+	waitIdle(t, r)
+
+	if len(f.scans) != 2 {
+		t.Fatalf("Got %d scans, expected 2", len(f.scans))
+	}
+
+	if f.errs[0] != context.Canceled {
+		t.Errorf("Expected the stale scan to be cancelled, got %v", f.errs[0])
+	}
+
+	if second := f.scans[1]; !second.branch || len(second.prs) != 0 {
+		t.Errorf("Expected a single follow-up scan of the whole branch, got %+v", second)
+	}
+}
+
+func TestQueueSeparatesBranches(t *testing.T) {
+	f := &fakeScanner{release: make(chan struct{}), started: make(chan struct{}, 10)}
+	r := newTestRebaser(f)
+
+	// scans of different branches don't wait on each other
+	r.enqueue(scanKey{"istio", "istio", "master"}, 1)
+	r.enqueue(scanKey{"istio", "istio", "release-1.0"}, 1)
+	<-f.started
+	<-f.started
+
+	f.release <- struct{}{}
+	f.release <- struct{}{}
+	waitIdle(t, r)
+
+	if len(f.scans) != 2 {
+		t.Fatalf("Got %d scans, expected 2", len(f.scans))
+	}
+}
@@ -25,6 +25,7 @@ import (
 	"istio.io/bots/policybot/pkg/config"
 	"istio.io/bots/policybot/pkg/gh"
 	gatherer "istio.io/bots/policybot/pkg/resultgatherer"
+	"istio.io/bots/policybot/pkg/signatures"
 	"istio.io/bots/policybot/pkg/storage"
 	"istio.io/bots/policybot/pkg/storage/cache"
 	"istio.io/istio/pkg/log"
@@ -139,11 +140,17 @@ func (r *Refresher) Handle(context context.Context, event interface{}) {
 			repoName := p.GetRepo().GetName()
 			prNum := p.GetNumber()
 
+			sigs, err := signatures.ForRepo(r.reg, p.GetRepo().GetFullName())
+			if err != nil {
+				scope.Errorf("Unable to load failure signatures for repo %s: %v", p.GetRepo().GetFullName(), err)
+			}
+
 			tg := gatherer.TestResultGatherer{
 				Client:           r.bs,
 				BucketName:       ref.BucketName,
 				PreSubmitPrefix:  ref.PreSubmitTestPath,
 				PostSubmitPrefix: ref.PostSubmitTestPath,
+				Signatures:       sigs,
 			}
 
 			testResults, err := tg.CheckTestResultsForPr(context, orgLogin, repoName, strconv.Itoa(prNum))
@@ -351,11 +358,17 @@ func (r *Refresher) Handle(context context.Context, event interface{}) {
 		prNum := int64(pr.GetNumber())
 		scope.Debugf("Commit %s corresponds to pull request %d", sha, prNum)
 
+		sigs, err := signatures.ForRepo(r.reg, p.GetRepo().GetFullName())
+		if err != nil {
+			scope.Errorf("Unable to load failure signatures for repo %s: %v", p.GetRepo().GetFullName(), err)
+		}
+
 		tg := gatherer.TestResultGatherer{
 			Client:           r.bs,
 			BucketName:       ref.BucketName,
 			PreSubmitPrefix:  ref.PreSubmitTestPath,
 			PostSubmitPrefix: ref.PostSubmitTestPath,
+			Signatures:       sigs,
 		}
 
 		testResults, err := tg.CheckTestResultsForPr(context, orgLogin, repoName, strconv.FormatInt(prNum, 10))
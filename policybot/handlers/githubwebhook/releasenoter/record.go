@@ -0,0 +1,58 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package releasenoter
+
+import (
+	"istio.io/bots/policybot/pkg/config"
+)
+
+const RecordType = "releasenote"
+
+type ReleaseNoteRecord struct {
+	config.RecordBase
+
+	// NotesPath is where PRs are expected to add release note files
+	NotesPath string // glob
+
+	// Kinds and Areas list the allowed values in release notes. When empty, the standard Istio values are used.
+	Kinds []string
+	Areas []string
+
+	// PRs with any of these labels don't need a release note
+	ExemptLabels []string
+
+	// PRs that only affect files matching these paths don't need a release note
+	ExemptPaths []string // globs
+
+	// PRs from these authors don't need a release note
+	ExemptAuthors []string
+
+	// MissingLabel is applied to PRs that lack a valid release note
+	MissingLabel string
+
+	// StatusContext is the name of the commit status reported on PRs
+	StatusContext string
+}
+
+func init() {
+	config.RegisterType(RecordType, config.OnePerRepo, func() config.Record {
+		return &ReleaseNoteRecord{
+			NotesPath:     "releasenotes/notes/*.yaml",
+			ExemptLabels:  []string{"release-notes-none"},
+			MissingLabel:  "flag-needs-release-note",
+			StatusContext: "release-notes",
+		}
+	})
+}
@@ -0,0 +1,331 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package releasenoter
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/google/go-github/v26/github"
+
+	"istio.io/bots/policybot/handlers/githubwebhook"
+	"istio.io/bots/policybot/pkg/config"
+	"istio.io/bots/policybot/pkg/gh"
+	"istio.io/bots/policybot/pkg/releasenotes"
+	"istio.io/bots/policybot/pkg/storage/cache"
+	"istio.io/bots/policybot/pkg/util"
+	"istio.io/istio/pkg/log"
+)
+
+// Ensures PRs carry a valid release note.
+type ReleaseNoter struct {
+	gc    *gh.ThrottledClient
+	cache *cache.Cache
+	reg   *config.Registry
+	globs map[string]*regexp.Regexp
+}
+
+const releaseNoteSignature = "\n\n_Courtesy of your friendly release note checker_."
+
+var scope = log.RegisterScope("releasenoter", "Release note checker")
+
+func New(gc *gh.ThrottledClient, cache *cache.Cache, reg *config.Registry) (githubwebhook.Filter, error) {
+	rn := &ReleaseNoter{
+		gc:    gc,
+		cache: cache,
+		reg:   reg,
+		globs: make(map[string]*regexp.Regexp),
+	}
+
+	for _, r := range reg.Records(RecordType, "*") {
+		if err := CompileGlobs(r.(*ReleaseNoteRecord), rn.globs); err != nil {
+			return nil, err
+		}
+	}
+
+	return rn, nil
+}
+
+// process an event arriving from GitHub
+func (rn *ReleaseNoter) Handle(context context.Context, event interface{}) {
+	prp, ok := event.(*github.PullRequestEvent)
+	if !ok {
+		// not what we're looking for
+		scope.Debugf("Unknown event received: %T %+v", event, event)
+		return
+	}
+
+	scope.Infof("Received PullRequestEvent: %s, %d, %s", prp.GetRepo().GetFullName(), prp.GetPullRequest().GetNumber(), prp.GetAction())
+
+	repo := prp.GetRepo().GetFullName()
+	pr := prp.GetPullRequest()
+
+	action := prp.GetAction()
+	if action != "opened" && action != "reopened" && action != "synchronize" && action != "labeled" && action != "unlabeled" {
+		scope.Infof("Ignoring event for PR %d from repo %s since it doesn't have a supported action: %s", pr.GetNumber(), repo, action)
+		return
+	}
+
+	r, ok := rn.reg.SingleRecord(RecordType, repo)
+	if !ok {
+		scope.Infof("Ignoring event for PR %d from repo %s since release notes aren't checked for it", pr.GetNumber(), repo)
+		return
+	}
+
+	if pr.GetState() != "open" {
+		scope.Infof("Ignoring event for PR %d from repo %s since it isn't open", pr.GetNumber(), repo)
+		return
+	}
+
+	scope.Infof("Processing PR %d from repo %s", pr.GetNumber(), repo)
+
+	rn.processPR(context, prp.GetRepo().GetOwner().GetLogin(), prp.GetRepo().GetName(), pr, r.(*ReleaseNoteRecord))
+}
+
+func (rn *ReleaseNoter) processPR(context context.Context, orgLogin string, repoName string, pr *github.PullRequest, rnr *ReleaseNoteRecord) {
+	files, err := rn.getFiles(context, orgLogin, repoName, pr)
+	if err != nil {
+		scope.Errorf("Unable to get files for PR %d in repo %s/%s: %v", pr.GetNumber(), orgLogin, repoName, err)
+		return
+	}
+
+	labels := make([]string, len(pr.Labels))
+	for i, label := range pr.Labels {
+		labels[i] = label.GetName()
+	}
+
+	if reason := Exemption(rnr, rn.globs, labels, pr.GetUser().GetLogin(), files); reason != "" {
+		scope.Infof("PR %d in repo %s/%s doesn't need a release note: %s", pr.GetNumber(), orgLogin, repoName, reason)
+		rn.report(context, orgLogin, repoName, pr, rnr, "success", "Not needed: "+reason, "")
+		return
+	}
+
+	var notes []string
+	for _, f := range files {
+		if rn.globs[rnr.NotesPath].MatchString(f) {
+			notes = append(notes, f)
+		}
+	}
+
+	// read the notes, skipping any the PR deletes
+	contents := make(map[string][]byte)
+	var present []string
+	for _, f := range notes {
+		content, found, err := rn.getContent(context, pr, f)
+		if err != nil {
+			scope.Errorf("Unable to read release note %s for PR %d in repo %s/%s: %v", f, pr.GetNumber(), orgLogin, repoName, err)
+			return
+		} else if found {
+			contents[f] = content
+			present = append(present, f)
+		}
+	}
+
+	if len(present) == 0 {
+		scope.Infof("PR %d in repo %s/%s is missing a release note", pr.GetNumber(), orgLogin, repoName)
+		msg := fmt.Sprintf("👋 @{{ .Author }}, this PR doesn't include a release note. Please add a file matching `%s` "+
+			"describing the user-facing impact of your change", rnr.NotesPath)
+		if len(rnr.ExemptLabels) > 0 {
+			msg += fmt.Sprintf(", or ask a maintainer to apply the `%s` label if it doesn't need one", strings.Join(rnr.ExemptLabels, "` or `"))
+		}
+
+		rn.report(context, orgLogin, repoName, pr, rnr, "failure", "Missing release note", msg+".")
+		return
+	}
+
+	var problems []string
+	for _, f := range present {
+		note, err := releasenotes.Parse(contents[f])
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("`%s`: unable to parse: %v", f, err))
+			continue
+		}
+
+		for _, p := range releasenotes.Validate(note, rnr.Kinds, rnr.Areas) {
+			problems = append(problems, fmt.Sprintf("`%s`: %s", f, p))
+		}
+	}
+
+	if len(problems) > 0 {
+		scope.Infof("PR %d in repo %s/%s has an invalid release note", pr.GetNumber(), orgLogin, repoName)
+
+		var sb strings.Builder
+		sb.WriteString("🤔 @{{ .Author }}, there are problems with this PR's release notes:\n\n")
+		for _, p := range problems {
+			sb.WriteString("- " + gh.EscapeTemplate(p) + "\n")
+		}
+
+		rn.report(context, orgLogin, repoName, pr, rnr, "failure", "Invalid release note", sb.String())
+		return
+	}
+
+	rn.report(context, orgLogin, repoName, pr, rnr, "success", "Release note is valid", "")
+}
+
+// CompileGlobs compiles the paths used by a record, adding them to the given map
+func CompileGlobs(rnr *ReleaseNoteRecord, globs map[string]*regexp.Regexp) error {
+	for _, glob := range append([]string{rnr.NotesPath}, rnr.ExemptPaths...) {
+		r, err := util.CompileGlob(glob)
+		if err != nil {
+			return fmt.Errorf("invalid glob %s: %v", glob, err)
+		}
+		globs[glob] = r
+	}
+
+	return nil
+}
+
+// Exemption returns why a PR doesn't need a release note, or "" if it does need one. The globs
+// must have been populated with CompileGlobs.
+func Exemption(rnr *ReleaseNoteRecord, globs map[string]*regexp.Regexp, labels []string, author string, files []string) string {
+	for _, label := range labels {
+		for _, exempt := range rnr.ExemptLabels {
+			if strings.EqualFold(label, exempt) {
+				return fmt.Sprintf("has the %s label", label)
+			}
+		}
+	}
+
+	for _, exempt := range rnr.ExemptAuthors {
+		if strings.EqualFold(author, exempt) {
+			return fmt.Sprintf("authored by %s", exempt)
+		}
+	}
+
+	if len(rnr.ExemptPaths) > 0 && len(files) > 0 {
+		for _, f := range files {
+			exempt := false
+			for _, glob := range rnr.ExemptPaths {
+				if globs[glob].MatchString(f) {
+					exempt = true
+					break
+				}
+			}
+
+			if !exempt {
+				return ""
+			}
+		}
+
+		return "only affects exempt paths"
+	}
+
+	return ""
+}
+
+// report records the outcome of the check as a commit status, label, and comment. An empty message removes the comment.
+func (rn *ReleaseNoter) report(context context.Context, orgLogin string, repoName string, pr *github.PullRequest,
+	rnr *ReleaseNoteRecord, state string, description string, message string) {
+	sha := pr.GetHead().GetSHA()
+	if _, _, err := rn.gc.ThrottledCall(func(client *github.Client) (interface{}, *github.Response, error) {
+		return client.Repositories.CreateStatus(context, orgLogin, repoName, sha, &github.RepoStatus{
+			State:       &state,
+			Description: &description,
+			Context:     &rnr.StatusContext,
+		})
+	}); err != nil {
+		scope.Errorf("Unable to set status on PR %d in repo %s/%s: %v", pr.GetNumber(), orgLogin, repoName, err)
+	}
+
+	hasLabel := false
+	for _, label := range pr.Labels {
+		if strings.EqualFold(label.GetName(), rnr.MissingLabel) {
+			hasLabel = true
+			break
+		}
+	}
+
+	if rnr.MissingLabel != "" {
+		if state == "failure" && !hasLabel {
+			if _, _, err := rn.gc.ThrottledCall(func(client *github.Client) (interface{}, *github.Response, error) {
+				return client.Issues.AddLabelsToIssue(context, orgLogin, repoName, pr.GetNumber(), []string{rnr.MissingLabel})
+			}); err != nil {
+				scope.Errorf("Unable to add label %s to PR %d in repo %s/%s: %v", rnr.MissingLabel, pr.GetNumber(), orgLogin, repoName, err)
+			}
+		} else if state == "success" && hasLabel {
+			if _, err := rn.gc.ThrottledCallNoResult(func(client *github.Client) (*github.Response, error) {
+				return client.Issues.RemoveLabelForIssue(context, orgLogin, repoName, pr.GetNumber(), rnr.MissingLabel)
+			}); err != nil {
+				scope.Errorf("Unable to remove label %s from PR %d in repo %s/%s: %v", rnr.MissingLabel, pr.GetNumber(), orgLogin, repoName, err)
+			}
+		}
+	}
+
+	var err error
+	if message != "" {
+		err = rn.gc.AddOrReplaceBotComment(context, orgLogin, repoName, pr.GetNumber(), pr.GetUser().GetLogin(), message, releaseNoteSignature)
+	} else {
+		err = rn.gc.RemoveBotComment(context, orgLogin, repoName, pr.GetNumber(), releaseNoteSignature)
+	}
+
+	if err != nil {
+		scope.Errorf("Unable to update comment on PR %d in repo %s/%s: %v", pr.GetNumber(), orgLogin, repoName, err)
+	}
+}
+
+// getFiles returns the files affected by a PR
+func (rn *ReleaseNoter) getFiles(context context.Context, orgLogin string, repoName string, pr *github.PullRequest) ([]string, error) {
+	// NOTE: the refresher filter normally stores the PR along with its files before we get here
+	stored, err := rn.cache.ReadPullRequest(context, orgLogin, repoName, pr.GetNumber())
+	if err != nil {
+		return nil, err
+	}
+
+	if stored != nil && len(stored.Files) > 0 && stored.HeadCommit == pr.GetHead().GetSHA() {
+		return stored.Files, nil
+	}
+
+	var files []string
+	if err := rn.gc.FetchFiles(context, orgLogin, repoName, pr.GetNumber(), func(f []string) error {
+		files = append(files, f...)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return files, nil
+}
+
+// getContent returns the content of a file at the head of a PR
+func (rn *ReleaseNoter) getContent(context context.Context, pr *github.PullRequest, path string) ([]byte, bool, error) {
+	head := pr.GetHead()
+	opt := &github.RepositoryContentGetOptions{Ref: head.GetSHA()}
+
+	fc, _, resp, err := rn.gc.ThrottledCallTwoResult(func(client *github.Client) (interface{}, interface{}, *github.Response, error) {
+		return client.Repositories.GetContents(context, head.GetRepo().GetOwner().GetLogin(), head.GetRepo().GetName(), path, opt)
+	})
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+
+	rc := fc.(*github.RepositoryContent)
+	if rc == nil {
+		// not a file
+		return nil, false, nil
+	}
+
+	content, err := rc.GetContent()
+	if err != nil {
+		return nil, false, err
+	}
+
+	return []byte(content), true, nil
+}
@@ -0,0 +1,68 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reportchecker
+
+import (
+	"istio.io/bots/policybot/pkg/config"
+	"istio.io/bots/policybot/pkg/rules"
+)
+
+const recordType = "reportcheck"
+
+type reportCheckRecord struct {
+	config.RecordBase
+
+	// Rule selects the issues that are checked, such as bug reports. All issues are checked when there is no rule.
+	Rule *rules.Rule
+
+	// Fields lists the information that issues must provide
+	Fields []requiredField
+
+	// Label is applied to issues that lack some of the required fields, and removed once they are filled in
+	Label string
+
+	// Message introduces the list of missing fields in the comment posted to the issue
+	Message string
+}
+
+type requiredField struct {
+	// Name is how the field is referred to in the comment
+	Name string
+
+	// Description explains to the author what to provide
+	Description string
+
+	// Headings are regular expressions matched against the headings of the issue body to locate the field. When empty,
+	// the field is looked for throughout the body.
+	Headings []string
+
+	// Patterns are regular expressions of which at least one must match the field's content. When empty, any
+	// content will do.
+	Patterns []string
+}
+
+func init() {
+	config.RegisterType(recordType, config.OnePerRepo, func() config.Record {
+		return &reportCheckRecord{
+			Label:   "needs-more-info",
+			Message: "Hey @{{ .Author }}, thanks for the report! We need a bit more information before we can look into this issue:",
+		}
+	})
+
+	rules.RegisterSource(recordType, func(r config.Record) (string, *rules.Rule) {
+		rc := r.(*reportCheckRecord)
+		return rc.Name, rc.Rule
+	})
+}
@@ -0,0 +1,290 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reportchecker
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/google/go-github/v26/github"
+
+	"istio.io/bots/policybot/handlers/githubwebhook"
+	"istio.io/bots/policybot/pkg/config"
+	"istio.io/bots/policybot/pkg/gh"
+	"istio.io/bots/policybot/pkg/issueform"
+	"istio.io/bots/policybot/pkg/rules"
+	"istio.io/istio/pkg/log"
+)
+
+// Asks for the information missing from issue reports.
+type ReportChecker struct {
+	gc     *gh.ThrottledClient
+	reg    *config.Registry
+	checks map[*reportCheckRecord]*compiledCheck
+}
+
+type compiledCheck struct {
+	expr   *rules.Expr
+	fields []*compiledField
+}
+
+type compiledField struct {
+	field    *requiredField
+	headings []*regexp.Regexp
+	patterns []*regexp.Regexp
+}
+
+var scope = log.RegisterScope("reportchecker", "Checks issue reports for missing information")
+
+const reportCheckSignature = "\n\n_Courtesy of your friendly issue report checker_."
+
+func New(gc *gh.ThrottledClient, reg *config.Registry) (githubwebhook.Filter, error) {
+	rc := &ReportChecker{
+		gc:     gc,
+		reg:    reg,
+		checks: make(map[*reportCheckRecord]*compiledCheck),
+	}
+
+	for _, r := range reg.Records(recordType, "*") {
+		rec := r.(*reportCheckRecord)
+		c, err := compileCheck(rec)
+		if err != nil {
+			return nil, err
+		}
+		rc.checks[rec] = c
+	}
+
+	return rc, nil
+}
+
+func compileCheck(rec *reportCheckRecord) (*compiledCheck, error) {
+	expr, err := rules.Compile(rec.Rule)
+	if err != nil {
+		return nil, fmt.Errorf("invalid rule for report check %s: %v", rec.Name, err)
+	}
+
+	c := &compiledCheck{expr: expr}
+	for i := range rec.Fields {
+		f := &rec.Fields[i]
+		cf := &compiledField{field: f}
+
+		for _, h := range f.Headings {
+			r, err := regexp.Compile("(?i)" + h)
+			if err != nil {
+				return nil, fmt.Errorf("invalid heading expression %s for field %s in report check %s: %v", h, f.Name, rec.Name, err)
+			}
+			cf.headings = append(cf.headings, r)
+		}
+
+		for _, p := range f.Patterns {
+			r, err := regexp.Compile("(?mi)" + p)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern %s for field %s in report check %s: %v", p, f.Name, rec.Name, err)
+			}
+			cf.patterns = append(cf.patterns, r)
+		}
+
+		c.fields = append(c.fields, cf)
+	}
+
+	return c, nil
+}
+
+// process an event arriving from GitHub
+func (rc *ReportChecker) Handle(context context.Context, event interface{}) {
+	ip, ok := event.(*github.IssuesEvent)
+	if !ok {
+		// not what we're looking for
+		scope.Debugf("Unknown event received: %T %+v", event, event)
+		return
+	}
+
+	scope.Infof("Received IssuesEvent: %s, %d, %s", ip.GetRepo().GetFullName(), ip.GetIssue().GetNumber(), ip.GetAction())
+
+	repo := ip.GetRepo().GetFullName()
+	number := ip.GetIssue().GetNumber()
+
+	action := ip.GetAction()
+	if action != "opened" && action != "edited" && action != "reopened" {
+		scope.Infof("Ignoring event for issue %d from repo %s since it doesn't have a supported action: %s", number, repo, action)
+		return
+	}
+
+	if ip.GetIssue().IsPullRequest() || ip.GetIssue().GetState() == "closed" {
+		scope.Infof("Ignoring event for issue %d from repo %s since it isn't an open issue", number, repo)
+		return
+	}
+
+	if action == "edited" && rc.isRobot(ip.GetSender().GetLogin()) {
+		// edits done by robots, such as the boilerplate cleaner, don't fill in anything
+		scope.Infof("Ignoring event for issue %d from repo %s since it was edited by robot %s", number, repo, ip.GetSender().GetLogin())
+		return
+	}
+
+	r, ok := rc.reg.SingleRecord(recordType, repo)
+	if !ok {
+		scope.Infof("Ignoring event for issue %d from repo %s since reports aren't checked for it", number, repo)
+		return
+	}
+	rec := r.(*reportCheckRecord)
+	check := rc.checks[rec]
+
+	orgLogin := ip.GetRepo().GetOwner().GetLogin()
+	repoName := ip.GetRepo().GetName()
+	issue := gh.ConvertIssue(orgLogin, repoName, ip.GetIssue())
+
+	hasLabel := false
+	for _, l := range issue.Labels {
+		if strings.EqualFold(l, rec.Label) {
+			hasLabel = true
+			break
+		}
+	}
+
+	matches := check.expr.Eval(rules.IssueSubject(issue))
+	if !matches && !hasLabel {
+		scope.Infof("Ignoring event for issue %d from repo %s since its report isn't checked", number, repo)
+		return
+	}
+
+	var missing []*requiredField
+	if matches {
+		missing = check.missing(issue.Body)
+	}
+
+	if len(missing) > 0 {
+		scope.Infof("Issue %d in repo %s is missing %d required fields", number, repo, len(missing))
+
+		if rec.Label != "" && !hasLabel {
+			if _, _, err := rc.gc.ThrottledCall(func(client *github.Client) (interface{}, *github.Response, error) {
+				return client.Issues.AddLabelsToIssue(context, orgLogin, repoName, number, []string{rec.Label})
+			}); err != nil {
+				scope.Errorf("Unable to add label %s to issue %d in repo %s: %v", rec.Label, number, repo, err)
+			}
+		}
+
+		if err := rc.gc.AddOrReplaceBotComment(context, orgLogin, repoName, number, issue.Author,
+			formatMessage(rec.Message, missing), reportCheckSignature); err != nil {
+			scope.Errorf("Unable to comment on issue %d in repo %s: %v", number, repo, err)
+		}
+
+		return
+	}
+
+	// only undo what the checker did itself, since the label may also have been added by hand
+	existing, id, err := rc.gc.FindBotComment(context, orgLogin, repoName, number, reportCheckSignature)
+	if err != nil {
+		scope.Errorf("Unable to look for the report check comment on issue %d in repo %s: %v", number, repo, err)
+		return
+	} else if existing == "" {
+		scope.Infof("Ignoring event for issue %d from repo %s since its report wasn't flagged by the checker", number, repo)
+		return
+	}
+
+	if matches {
+		scope.Infof("Issue %d in repo %s has all the required information", number, repo)
+	} else {
+		scope.Infof("Issue %d in repo %s no longer needs its report checked", number, repo)
+	}
+
+	if hasLabel {
+		if _, err := rc.gc.ThrottledCallNoResult(func(client *github.Client) (*github.Response, error) {
+			return client.Issues.RemoveLabelForIssue(context, orgLogin, repoName, number, rec.Label)
+		}); err != nil {
+			scope.Errorf("Unable to remove label %s from issue %d in repo %s: %v", rec.Label, number, repo, err)
+		}
+	}
+
+	if _, err := rc.gc.ThrottledCallNoResult(func(client *github.Client) (*github.Response, error) {
+		return client.Issues.DeleteComment(context, orgLogin, repoName, id)
+	}); err != nil {
+		scope.Errorf("Unable to remove comment from issue %d in repo %s: %v", number, repo, err)
+	}
+}
+
+// missing returns the required fields that an issue body doesn't provide
+func (c *compiledCheck) missing(body string) []*requiredField {
+	fields := issueform.Parse(body)
+
+	var result []*requiredField
+	for _, cf := range c.fields {
+		if !cf.present(fields, body) {
+			result = append(result, cf.field)
+		}
+	}
+
+	return result
+}
+
+func (cf *compiledField) present(fields []issueform.Field, body string) bool {
+	var content []string
+	if len(cf.headings) == 0 {
+		content = append(content, body)
+	} else {
+		for _, h := range cf.headings {
+			if f, ok := issueform.Find(fields, h); ok && f.Value != "" {
+				content = append(content, f.Value)
+			}
+		}
+	}
+
+	for _, c := range content {
+		if strings.TrimSpace(c) == "" {
+			continue
+		}
+
+		if len(cf.patterns) == 0 {
+			return true
+		}
+
+		for _, p := range cf.patterns {
+			if p.MatchString(c) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// formatMessage produces the comment that lists the missing fields
+func formatMessage(intro string, missing []*requiredField) string {
+	var sb strings.Builder
+	sb.WriteString(intro)
+	sb.WriteString("\n")
+
+	for _, f := range missing {
+		sb.WriteString("\n- **" + f.Name + "**")
+		if f.Description != "" {
+			sb.WriteString(": " + f.Description)
+		}
+	}
+
+	sb.WriteString("\n\nPlease edit the issue to add this information. This comment will go away once everything is in place.")
+
+	return sb.String()
+}
+
+func (rc *ReportChecker) isRobot(login string) bool {
+	for _, r := range rc.reg.Core().Robots {
+		if strings.EqualFold(r, login) {
+			return true
+		}
+	}
+
+	return false
+}
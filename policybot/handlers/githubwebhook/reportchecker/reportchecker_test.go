@@ -0,0 +1,121 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reportchecker
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/yaml"
+
+	"istio.io/bots/policybot/pkg/rules"
+)
+
+func loadDefault(t *testing.T) (*reportCheckRecord, *compiledCheck) {
+	b, err := os.ReadFile("../../../config/reportchecks/default.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec := &reportCheckRecord{}
+	if err := yaml.Unmarshal(b, rec); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := compileCheck(rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return rec, c
+}
+
+func TestMissing(t *testing.T) {
+	_, c := loadDefault(t)
+
+	complete, err := os.ReadFile("../cleaner/testdata/issue-default.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name     string
+		body     string
+		expected []string
+	}{
+		{"complete", string(complete), nil},
+		{
+			"no version",
+			strings.Replace(string(complete), "### Version", "### Other", 1),
+			[]string{"Version"},
+		},
+		{
+			"empty form",
+			"### Bug Description\n\n_No response_\n\n### Version\n\n_No response_\n\n### Affected product area\n\n- [ ] Docs\n- [ ] Networking\n",
+			[]string{"Bug description", "Version", "Affected product area"},
+		},
+		{
+			"no areas",
+			"### Bug Description\n\nBroken\n\n### Version\n\n1.5.0\n\n### Affected product area\n\n- [ ] Docs\n",
+			[]string{"Affected product area"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var got []string
+			for _, f := range c.missing(tc.body) {
+				got = append(got, f.Name)
+			}
+
+			if strings.Join(got, ",") != strings.Join(tc.expected, ",") {
+				t.Errorf("Got missing fields %v, expected %v", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestRule(t *testing.T) {
+	_, c := loadDefault(t)
+
+	cases := []struct {
+		subject  rules.Subject
+		expected bool
+	}{
+		{rules.Subject{Body: "### Bug Description\n\nBroken"}, true},
+		{rules.Subject{Body: "### Bug Description\n\nBroken", Labels: []string{"lifecycle/staleproof"}}, false},
+		{rules.Subject{Body: "### Describe the feature request\n\nMore"}, false},
+	}
+
+	for _, tc := range cases {
+		if got := c.expr.Eval(&tc.subject); got != tc.expected {
+			t.Errorf("Eval(%+v) = %v, expected %v", tc.subject, got, tc.expected)
+		}
+	}
+}
+
+func TestFormatMessage(t *testing.T) {
+	msg := formatMessage("Intro:", []*requiredField{
+		{Name: "Version", Description: "the version"},
+		{Name: "Steps"},
+	})
+
+	expected := "Intro:\n\n- **Version**: the version\n- **Steps**\n\n" +
+		"Please edit the issue to add this information. This comment will go away once everything is in place."
+	if msg != expected {
+		t.Errorf("Got %q, expected %q", msg, expected)
+	}
+}
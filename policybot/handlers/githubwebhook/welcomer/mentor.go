@@ -0,0 +1,219 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package welcomer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v26/github"
+
+	"istio.io/bots/policybot/pkg/storage"
+)
+
+const mentorNote = "\n\n@%s will be your mentor for this PR, and will help you get it reviewed."
+
+// assignMentor picks a mentor for a new contributor's PR and asks them to review it. It returns the chosen mentor,
+// or an empty string when there is no roster for the PR.
+func (w *Welcomer) assignMentor(context context.Context, c *contribution) string {
+	roster := w.findRoster(c, func() []string { return w.currentLabels(context, c) })
+	if roster == nil {
+		return ""
+	}
+
+	lastAssigned := make(map[string]time.Time)
+	if err := w.store.QueryMentorAssignments(context, c.orgLogin, func(ma *storage.MentorAssignment) error {
+		if ma.AssignedAt.After(lastAssigned[ma.Mentor]) {
+			lastAssigned[ma.Mentor] = ma.AssignedAt
+		}
+		return nil
+	}); err != nil {
+		scope.Errorf("Unable to query storage for mentor assignments in org %s: %v", c.orgLogin, err)
+		return ""
+	}
+
+	active := make(map[string]bool)
+	cutoff := time.Now().Add(-time.Hour * 24 * time.Duration(roster.ActiveDays))
+	for _, m := range roster.Mentors {
+		active[m] = w.activeSince(context, c.orgLogin, m, cutoff)
+	}
+
+	mentor := pickMentor(roster.Mentors, c.author, lastAssigned, active)
+	if mentor == "" {
+		scope.Infof("No mentor available in roster %s for PR %d in repo %s/%s", roster.Name, c.number, c.orgLogin, c.repoName)
+		return ""
+	}
+
+	if _, _, err := w.gc.ThrottledCall(func(client *github.Client) (interface{}, *github.Response, error) {
+		return client.PullRequests.RequestReviewers(context, c.orgLogin, c.repoName, int(c.number), github.ReviewersRequest{Reviewers: []string{mentor}})
+	}); err != nil {
+		scope.Errorf("Unable to request a review from mentor %s for PR %d in repo %s/%s: %v", mentor, c.number, c.orgLogin, c.repoName, err)
+		return ""
+	}
+
+	if err := w.store.WriteMentorAssignments(context, []*storage.MentorAssignment{{
+		OrgLogin:          c.orgLogin,
+		RepoName:          c.repoName,
+		PullRequestNumber: c.number,
+		Mentor:            mentor,
+		Author:            c.author,
+		AssignedAt:        time.Now(),
+	}}); err != nil {
+		scope.Errorf("Unable to record mentor %s for PR %d in repo %s/%s: %v", mentor, c.number, c.orgLogin, c.repoName, err)
+	}
+
+	scope.Infof("Assigned mentor %s to PR %d in repo %s/%s", mentor, c.number, c.orgLogin, c.repoName)
+	return mentor
+}
+
+// findRoster returns the roster that applies to a PR, preferring rosters that match one of the PR's labels. The
+// labels are only fetched when some roster needs them.
+func (w *Welcomer) findRoster(c *contribution, getLabels func() []string) *mentorRecord {
+	var fallback *mentorRecord
+	var labels []string
+	for _, r := range w.reg.Records(mentorRecordType, c.orgLogin+"/"+c.repoName) {
+		mr := r.(*mentorRecord)
+		if len(mr.Labels) == 0 {
+			if fallback == nil {
+				fallback = mr
+			}
+			continue
+		}
+
+		if labels == nil {
+			labels = getLabels()
+		}
+
+		for _, l := range mr.Labels {
+			if containsFold(labels, l) {
+				return mr
+			}
+		}
+	}
+
+	return fallback
+}
+
+// currentLabels returns the labels of a PR as they are now, which includes those applied by the labeler while
+// processing the same event
+func (w *Welcomer) currentLabels(context context.Context, c *contribution) []string {
+	result := make([]string, 0)
+	opt := &github.ListOptions{PerPage: 100}
+	for {
+		labels, resp, err := w.gc.ThrottledCall(func(client *github.Client) (interface{}, *github.Response, error) {
+			return client.Issues.ListLabelsByIssue(context, c.orgLogin, c.repoName, int(c.number), opt)
+		})
+		if err != nil {
+			scope.Errorf("Unable to list the labels of PR %d in repo %s/%s: %v", c.number, c.orgLogin, c.repoName, err)
+			return result
+		}
+
+		for _, l := range labels.([]*github.Label) {
+			result = append(result, l.GetName())
+		}
+
+		if resp.NextPage == 0 {
+			return result
+		}
+		opt.Page = resp.NextPage
+	}
+}
+
+// activeSince returns whether a mentor was active as a maintainer after the cutoff
+func (w *Welcomer) activeSince(context context.Context, orgLogin string, mentor string, cutoff time.Time) bool {
+	maintainer, err := w.store.ReadMaintainer(context, orgLogin, mentor)
+	if err != nil {
+		scope.Errorf("Unable to read maintainer %s in org %s: %v", mentor, orgLogin, err)
+		return false
+	} else if maintainer == nil || maintainer.Emeritus {
+		return false
+	}
+
+	info, err := w.store.QueryMaintainerActivity(context, maintainer)
+	if err != nil {
+		scope.Errorf("Unable to get the activity of maintainer %s in org %s: %v", mentor, orgLogin, err)
+		return false
+	}
+
+	return info.LastActivity.After(cutoff)
+}
+
+// pickMentor chooses the mentor who was assigned least recently, preferring active mentors and never picking the
+// author of the PR.
+func pickMentor(mentors []string, author string, lastAssigned map[string]time.Time, active map[string]bool) string {
+	best := ""
+	for _, m := range mentors {
+		if strings.EqualFold(m, author) {
+			continue
+		}
+
+		if best == "" {
+			best = m
+			continue
+		}
+
+		if active[m] != active[best] {
+			if active[m] {
+				best = m
+			}
+			continue
+		}
+
+		if lastAssigned[m].Before(lastAssigned[best]) {
+			best = m
+		}
+	}
+
+	return best
+}
+
+// recordResponse notes when a mentor first reviews or comments on the PR they were assigned
+func (w *Welcomer) recordResponse(context context.Context, orgLogin string, repoName string, number int, user string, at time.Time) {
+	var updated []*storage.MentorAssignment
+	if err := w.store.QueryMentorAssignmentsByPullRequest(context, orgLogin, repoName, number, func(ma *storage.MentorAssignment) error {
+		if ma.RespondedAt == nil && strings.EqualFold(ma.Mentor, user) {
+			ma.RespondedAt = &at
+			updated = append(updated, ma)
+		}
+		return nil
+	}); err != nil {
+		scope.Errorf("Unable to query storage for mentor assignments of PR %d in repo %s/%s: %v", number, orgLogin, repoName, err)
+		return
+	}
+
+	if len(updated) == 0 {
+		return
+	}
+
+	if err := w.store.WriteMentorAssignments(context, updated); err != nil {
+		scope.Errorf("Unable to record the response of mentor %s on PR %d in repo %s/%s: %v", user, number, orgLogin, repoName, err)
+	}
+}
+
+func mentorMessage(mentor string) string {
+	return fmt.Sprintf(mentorNote, mentor)
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+
+	return false
+}
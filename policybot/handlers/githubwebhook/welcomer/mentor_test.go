@@ -0,0 +1,54 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package welcomer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPickMentor(t *testing.T) {
+	now := time.Now()
+
+	cases := []struct {
+		name         string
+		mentors      []string
+		author       string
+		lastAssigned map[string]time.Time
+		active       map[string]bool
+		expected     string
+	}{
+		{"empty roster", nil, "alice", nil, nil, ""},
+		{"only the author", []string{"alice"}, "Alice", nil, nil, ""},
+		{"never assigned first", []string{"bob", "carol"}, "alice",
+			map[string]time.Time{"bob": now}, nil, "carol"},
+		{"least recently assigned", []string{"bob", "carol", "dave"}, "alice",
+			map[string]time.Time{"bob": now, "carol": now.Add(-time.Hour), "dave": now.Add(-time.Minute)}, nil, "carol"},
+		{"active preferred", []string{"bob", "carol"}, "alice",
+			map[string]time.Time{"bob": now}, map[string]bool{"bob": true}, "bob"},
+		{"round robin among active", []string{"bob", "carol", "dave"}, "alice",
+			map[string]time.Time{"bob": now, "carol": now.Add(-time.Hour)}, map[string]bool{"bob": true, "carol": true}, "carol"},
+		{"author skipped", []string{"bob", "carol"}, "carol",
+			map[string]time.Time{"bob": now}, map[string]bool{"carol": true}, "bob"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := pickMentor(c.mentors, c.author, c.lastAssigned, c.active); got != c.expected {
+				t.Errorf("Got mentor %q, expected %q", got, c.expected)
+			}
+		})
+	}
+}
@@ -26,9 +26,20 @@ type welcomeRecord struct {
 	// The message to inject as a welcome message for new contributors to a repo.
 	Message string
 
-	// The message is posted if the user has never contributed to the repo, or if the last contribution
+	// The message to inject as a welcome message for new issue authors. Issues aren't welcomed when this is empty.
+	IssueMessage string
+
+	// The message is posted if the user has never contributed, or if the last contribution
 	// is older than the resend interval
 	ResendDays int
+
+	// Only look at contributions to this repo to decide whether someone is new, rather than at all the repos
+	// the bot manages in the org.
+	PerRepo bool
+
+	// Trust the author association GitHub reports for PRs: members and collaborators are never welcomed, while
+	// people new to GitHub always are. Issue authors are checked against the org members in storage instead.
+	UseAuthorAssociation bool
 }
 
 func init() {
@@ -36,3 +47,28 @@ func init() {
 		return new(welcomeRecord)
 	})
 }
+
+const mentorRecordType = "mentor"
+
+// mentorRecord describes a roster of people who take turns looking after the PRs of new contributors.
+type mentorRecord struct {
+	config.RecordBase
+
+	// Labels restricts the roster to PRs carrying one of these labels, such as area labels. Rosters with matching
+	// labels are preferred over those without labels, which apply to any PR in their repos.
+	Labels []string
+
+	// Mentors are the GitHub logins of the people on the roster
+	Mentors []string
+
+	// Mentors who were active as maintainers within this many days are picked before the others
+	ActiveDays int
+}
+
+func init() {
+	config.RegisterType(mentorRecordType, config.MultiplePerRepo, func() config.Record {
+		return &mentorRecord{
+			ActiveDays: 30,
+		}
+	})
+}
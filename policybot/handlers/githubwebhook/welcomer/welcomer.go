@@ -16,6 +16,7 @@ package welcomer
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	"github.com/google/go-github/v26/github"
@@ -24,26 +25,39 @@ import (
 	"istio.io/bots/policybot/pkg/config"
 	"istio.io/bots/policybot/pkg/gh"
 	"istio.io/bots/policybot/pkg/storage"
-	"istio.io/bots/policybot/pkg/storage/cache"
 	"istio.io/istio/pkg/log"
 )
 
-// Inserts comments into PRs for new or infrequently seen contributors.
+// Inserts comments into issues and PRs for new or infrequently seen contributors, and finds mentors for their PRs.
 type Welcomer struct {
 	store storage.Store
-	cache *cache.Cache
 	gc    *gh.ThrottledClient
 	reg   *config.Registry
 }
 
 const welcomeSignature = "\n\n_Courtesy of your friendly welcome wagon_."
 
+// The kinds of contributions that get welcomed
+const (
+	issueKind = "issue"
+	prKind    = "pr"
+)
+
 var scope = log.RegisterScope("welcomer", "The Istio welcome wagon")
 
-func NewWelcomer(gc *gh.ThrottledClient, store storage.Store, cache *cache.Cache, reg *config.Registry) githubwebhook.Filter {
+// contribution is an issue or PR that may deserve a welcome
+type contribution struct {
+	kind        string
+	orgLogin    string
+	repoName    string
+	number      int64
+	author      string
+	association string
+}
+
+func NewWelcomer(gc *gh.ThrottledClient, store storage.Store, reg *config.Registry) githubwebhook.Filter {
 	return &Welcomer{
 		store: store,
-		cache: cache,
 		gc:    gc,
 		reg:   reg,
 	}
@@ -51,58 +65,227 @@ func NewWelcomer(gc *gh.ThrottledClient, store storage.Store, cache *cache.Cache
 
 // process an event arriving from GitHub
 func (w *Welcomer) Handle(context context.Context, event interface{}) {
-	prp, ok := event.(*github.PullRequestEvent)
-	if !ok {
+	var c *contribution
+	action := ""
+	repo := ""
+
+	switch p := event.(type) {
+	case *github.IssuesEvent:
+		scope.Infof("Received IssuesEvent: %s, %d, %s", p.GetRepo().GetFullName(), p.GetIssue().GetNumber(), p.GetAction())
+
+		action = p.GetAction()
+		repo = p.GetRepo().GetFullName()
+		c = &contribution{
+			kind:     issueKind,
+			orgLogin: p.GetRepo().GetOwner().GetLogin(),
+			repoName: p.GetRepo().GetName(),
+			number:   int64(p.GetIssue().GetNumber()),
+			author:   p.GetIssue().GetUser().GetLogin(),
+		}
+
+	case *github.PullRequestEvent:
+		scope.Infof("Received PullRequestEvent: %s, %d, %s", p.GetRepo().GetFullName(), p.GetPullRequest().GetNumber(), p.GetAction())
+
+		action = p.GetAction()
+		repo = p.GetRepo().GetFullName()
+		c = &contribution{
+			kind:        prKind,
+			orgLogin:    p.GetRepo().GetOwner().GetLogin(),
+			repoName:    p.GetRepo().GetName(),
+			number:      int64(p.GetPullRequest().GetNumber()),
+			author:      p.GetPullRequest().GetUser().GetLogin(),
+			association: p.GetPullRequest().GetAuthorAssociation(),
+		}
+
+	case *github.PullRequestReviewEvent:
+		if p.GetAction() == "submitted" {
+			w.recordResponse(context, p.GetRepo().GetOwner().GetLogin(), p.GetRepo().GetName(), p.GetPullRequest().GetNumber(),
+				p.GetReview().GetUser().GetLogin(), p.GetReview().GetSubmittedAt())
+		}
+		return
+
+	case *github.IssueCommentEvent:
+		if p.GetAction() == "created" && p.GetIssue().IsPullRequest() {
+			w.recordResponse(context, p.GetRepo().GetOwner().GetLogin(), p.GetRepo().GetName(), p.GetIssue().GetNumber(),
+				p.GetComment().GetUser().GetLogin(), p.GetComment().GetCreatedAt())
+		}
+		return
+
+	default:
 		// not what we're looking for
-		scope.Debugf("Unknown event received: %T %+v", event, event)
+		scope.Debugf("Unknown event received: %T %+v", p, p)
 		return
 	}
 
-	scope.Infof("Received PullRequestEvent: %s, %d, %s", prp.GetRepo().GetFullName(), prp.GetPullRequest().GetNumber(), prp.GetAction())
-
-	action := prp.GetAction()
 	if action != "opened" {
-		scope.Infof("Ignoring event for PR %d from repo %s since it doesn't have a supported action: %s", prp.GetNumber(), prp.GetRepo().GetFullName(), action)
+		scope.Infof("Ignoring event for issue/PR %d from repo %s since it doesn't have a supported action: %s", c.number, repo, action)
 		return
 	}
 
-	// see if the PR is in a repo we're monitoring
-	welcome, ok := w.reg.SingleRecord(recordType, prp.GetRepo().GetFullName())
+	// see if the issue or PR is in a repo we're monitoring
+	r, ok := w.reg.SingleRecord(recordType, repo)
 	if !ok {
-		scope.Errorf("Ignoring event for PR %d from repo %s since there are no matching welcome message", prp.GetNumber(), prp.GetRepo().GetFullName())
+		scope.Infof("Ignoring event for issue/PR %d from repo %s since there are no matching welcome message", c.number, repo)
 		return
 	}
+	welcome := r.(*welcomeRecord)
 
-	// NOTE: this assumes the PR state has already been stored by the refresher filter
-	pr, err := w.cache.ReadPullRequest(context, prp.GetRepo().GetOwner().GetLogin(), prp.GetRepo().GetName(), prp.GetPullRequest().GetNumber())
-	if err != nil {
-		scope.Errorf("Unable to retrieve data from storage for PR %d from repo %s: %v", prp.GetNumber(), prp.GetRepo().GetFullName(), err)
+	message := welcome.Message
+	if c.kind == issueKind {
+		message = welcome.IssueMessage
+	}
+
+	if message == "" {
+		scope.Infof("Ignoring event for issue/PR %d from repo %s since there is no welcome message for a %s", c.number, repo, c.kind)
+		return
+	}
+
+	scope.Infof("Processing %s %d from repo %s", c.kind, c.number, repo)
+
+	w.process(context, c, welcome, message)
+}
+
+// process an issue or PR
+func (w *Welcomer) process(context context.Context, c *contribution, welcome *welcomeRecord, message string) {
+	force := false
+	if welcome.UseAuthorAssociation {
+		switch association := w.authorAssociation(context, c); association {
+		case "OWNER", "MEMBER", "COLLABORATOR":
+			scope.Infof("Not welcoming %s to repo %s/%s since they are a %s", c.author, c.orgLogin, c.repoName, strings.ToLower(association))
+			return
+
+		case "FIRST_TIMER":
+			force = true
+
+		case "FIRST_TIME_CONTRIBUTOR":
+			// this only speaks for the repo at hand
+			force = welcome.PerRepo
+		}
+	}
+
+	// whether this is the author's first contribution, rather than one after a long absence
+	first := force
+	if !force {
+		latest := w.latestContribution(context, c, welcome)
+		if time.Since(latest) <= time.Hour*24*time.Duration(welcome.ResendDays) {
+			scope.Infof("Not welcoming %s to repo %s/%s since they contributed on %v", c.author, c.orgLogin, c.repoName, latest)
+			return
+		}
+		first = latest.IsZero()
+	}
+
+	if c.kind == prKind && first {
+		if mentor := w.assignMentor(context, c); mentor != "" {
+			message += mentorMessage(mentor)
+		}
+	}
+
+	if err := w.gc.AddOrReplaceBotComment(context, c.orgLogin, c.repoName, int(c.number), c.author, message, welcomeSignature); err != nil {
+		scope.Errorf("Unable to add comment to %s %d in repo %s/%s: %v", c.kind, c.number, c.orgLogin, c.repoName, err)
 		return
 	}
 
-	scope.Infof("Processing PR %d from repo %s", prp.GetNumber(), prp.GetRepo().GetFullName())
+	if err := w.store.WriteWelcomes(context, []*storage.Welcome{{
+		OrgLogin:   c.orgLogin,
+		UserLogin:  c.author,
+		RepoName:   c.repoName,
+		Kind:       c.kind,
+		Number:     c.number,
+		WelcomedAt: time.Now(),
+	}}); err != nil {
+		scope.Errorf("Unable to record the welcome of %s in repo %s/%s: %v", c.author, c.orgLogin, c.repoName, err)
+	}
+}
 
-	w.processPR(context, pr, welcome.(*welcomeRecord))
+// authorAssociation returns how the author relates to the repo. GitHub reports this for PRs, but the issues we
+// receive don't carry it, so issue authors known to storage as members of the org are treated as such.
+func (w *Welcomer) authorAssociation(context context.Context, c *contribution) string {
+	if c.association != "" {
+		return c.association
+	}
+
+	member, err := w.store.ReadMember(context, c.orgLogin, c.author)
+	if err != nil {
+		scope.Errorf("Unable to read member %s of org %s from storage: %v", c.author, c.orgLogin, err)
+		return ""
+	}
+
+	if member == nil {
+		return ""
+	}
+
+	return "MEMBER"
 }
 
-// process a PR
-func (w *Welcomer) processPR(context context.Context, pr *storage.PullRequest, welcome *welcomeRecord) {
+// latestContribution returns when the author last opened an issue or PR, or was welcomed, in any of the repos
+// that count towards newness. Opening issues doesn't make someone any less of a new PR author.
+func (w *Welcomer) latestContribution(context context.Context, c *contribution, welcome *welcomeRecord) time.Time {
 	latest := time.Time{}
+	track := func(t time.Time) {
+		if t.After(latest) {
+			latest = t
+		}
+	}
 
-	if err := w.store.QueryPullRequestsByUser(context, pr.OrgLogin, pr.RepoName, pr.Author, func(prResult *storage.PullRequest) error {
-		if pr.PullRequestNumber != prResult.PullRequestNumber && prResult.CreatedAt.After(latest) {
-			latest = prResult.CreatedAt
+	repos := w.scopeRepos(c, welcome)
+	for _, repoName := range repos {
+		if err := w.store.QueryPullRequestsByUser(context, c.orgLogin, repoName, c.author, func(pr *storage.PullRequest) error {
+			if repoName != c.repoName || pr.PullRequestNumber != c.number {
+				track(pr.CreatedAt)
+			}
+			return nil
+		}); err != nil {
+			scope.Errorf("Unable to query storage for PRs in repo %s/%s: %v", c.orgLogin, repoName, err)
 		}
 
+		if c.kind != issueKind {
+			continue
+		}
+
+		if err := w.store.QueryIssuesByUser(context, c.orgLogin, repoName, c.author, func(issue *storage.Issue) error {
+			if repoName != c.repoName || issue.IssueNumber != c.number {
+				track(issue.CreatedAt)
+			}
+			return nil
+		}); err != nil {
+			scope.Errorf("Unable to query storage for issues in repo %s/%s: %v", c.orgLogin, repoName, err)
+		}
+	}
+
+	if err := w.store.QueryWelcomesByUser(context, c.orgLogin, c.author, func(wl *storage.Welcome) error {
+		if contains(repos, wl.RepoName) && (c.kind == issueKind || wl.Kind == prKind) {
+			track(wl.WelcomedAt)
+		}
 		return nil
 	}); err != nil {
-		scope.Errorf("Unable to query storage for PRs in repo %s/%s: %v", pr.OrgLogin, pr.RepoName, err)
+		scope.Errorf("Unable to query storage for welcomes of %s in org %s: %v", c.author, c.orgLogin, err)
+	}
+
+	return latest
+}
+
+// scopeRepos returns the repos whose contributions count towards newness
+func (w *Welcomer) scopeRepos(c *contribution, welcome *welcomeRecord) []string {
+	if welcome.PerRepo {
+		return []string{c.repoName}
+	}
+
+	repos := []string{c.repoName}
+	for _, rd := range w.reg.Repos() {
+		if rd.OrgLogin == c.orgLogin && !contains(repos, rd.RepoName) {
+			repos = append(repos, rd.RepoName)
+		}
 	}
 
-	if time.Since(latest) > time.Hour*24*time.Duration(welcome.ResendDays) {
-		if err := w.gc.AddOrReplaceBotComment(context, pr.OrgLogin, pr.RepoName, int(pr.PullRequestNumber), pr.Author, welcome.Message,
-			welcomeSignature); err != nil {
-			scope.Errorf("Unable to add comment to PR %d in repo %s/%s: %v", pr.PullRequestNumber, pr.OrgLogin, pr.RepoName, err)
+	return repos
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
 		}
 	}
+
+	return false
 }
@@ -0,0 +1,480 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flakemgr
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v26/github"
+	"github.com/hashicorp/go-multierror"
+
+	"istio.io/bots/policybot/pkg/gh"
+	"istio.io/bots/policybot/pkg/pipeline"
+	"istio.io/bots/policybot/pkg/report"
+	"istio.io/bots/policybot/pkg/storage"
+)
+
+const issueSignature = "\n\n_Courtesy of your friendly test flake manager_."
+
+// the number of old flakes marked as seen at once
+const backfillBatchSize = 1000
+
+// owner is a compiled flakeOwner
+type owner struct {
+	tests []*regexp.Regexp
+	area  string
+	team  string
+}
+
+func compileOwners(fir *flakeIssueRecord) ([]owner, error) {
+	owners := make([]owner, 0, len(fir.Owners))
+	for _, fo := range fir.Owners {
+		o := owner{area: fo.Area, team: fo.Team}
+		for _, t := range fo.Tests {
+			re, err := regexp.Compile(t)
+			if err != nil {
+				return nil, fmt.Errorf("invalid test pattern %s in flake issue record %s: %v", t, fir.Name, err)
+			}
+			o.tests = append(o.tests, re)
+		}
+		owners = append(owners, o)
+	}
+
+	return owners, nil
+}
+
+// issueLabels returns the labels for the issue of a flaky test
+func issueLabels(fir *flakeIssueRecord, owners []owner, testName string) []string {
+	var labels []string
+	if fir.Label != "" {
+		labels = append(labels, fir.Label)
+	}
+
+	for _, o := range owners {
+		for _, re := range o.tests {
+			if re.MatchString(testName) {
+				if o.area != "" {
+					labels = append(labels, o.area)
+				}
+				if o.team != "" {
+					labels = append(labels, o.team)
+				}
+				return labels
+			}
+		}
+	}
+
+	return labels
+}
+
+// describeOccurrences renders a markdown list of flaky runs, one line per failed run, along with the
+// number of failed runs listed.
+func describeOccurrences(fir *flakeIssueRecord, occurrences []*storage.FlakeOccurrence) (string, int) {
+	type run struct {
+		pr     int64
+		number int64
+	}
+
+	seen := make(map[run]bool)
+	var sb strings.Builder
+	for _, o := range occurrences {
+		r := run{o.PullRequestNumber, o.RunNumber}
+		if seen[r] {
+			// a failed run can be matched with more than one passing run, the first is enough
+			continue
+		}
+		seen[r] = true
+
+		fmt.Fprintf(&sb, "- %s/%s#%d: [run %d](%s%s) failed on %s",
+			o.OrgLogin, o.RepoName, o.PullRequestNumber, o.RunNumber, fir.RunURLPrefix, o.FailedRunPath, o.FinishTime.UTC().Format("2006-01-02"))
+		if o.PassingRunPath != nil {
+			fmt.Fprintf(&sb, ", then [run %d](%s%s) passed", o.PassingRunNumber, fir.RunURLPrefix, *o.PassingRunPath)
+		} else {
+			fmt.Fprintf(&sb, ", then run %d passed", o.PassingRunNumber)
+		}
+		sb.WriteString("\n")
+	}
+
+	return sb.String(), len(seen)
+}
+
+// FileIssues files, updates, and closes the issues that track the tests confirmed to be flaky.
+func (fm *FlakeManager) FileIssues(context context.Context, dryRun bool) (*report.Report, error) {
+	core := fm.reg.Core()
+	rep := report.New("flakemgr")
+
+	var repos []interface{}
+	for _, repo := range fm.reg.Repos() {
+		repos = append(repos, repo)
+	}
+
+	err := pipeline.ForEach(context, repos, core.RepoParallelism, func(item interface{}) error {
+		repo := item.(gh.RepoDesc)
+		r, ok := fm.reg.SingleRecord(issueRecordType, repo.OrgAndRepo)
+		if !ok {
+			return nil
+		}
+
+		fir := r.(*flakeIssueRecord)
+		rr := rep.Repo(repo.OrgAndRepo)
+
+		owners, err := compileOwners(fir)
+		if err != nil {
+			rr.Fail(err)
+			return err
+		}
+
+		var result *multierror.Error
+		if err := fm.fileRepoIssues(context, repo, fir, owners, core.ItemParallelism, dryRun, rr); err != nil {
+			result = multierror.Append(result, err)
+		}
+
+		if err := fm.closeQuietIssues(context, repo, fir, core.ItemParallelism, dryRun, rr); err != nil {
+			result = multierror.Append(result, err)
+		}
+
+		return result.ErrorOrNil()
+	})
+
+	rep.Finish()
+	return rep, err
+}
+
+func (fm *FlakeManager) fileRepoIssues(context context.Context, repo gh.RepoDesc, fir *flakeIssueRecord, owners []owner,
+	parallelism int, dryRun bool, rr *report.RepoReport) error {
+	// flakes older than the quiet period would have their issue closed right away, and those around when issues
+	// started being filed would flood the repo, so they're only recorded as seen
+	now := time.Now()
+	cutoff := now.Add(-time.Duration(fir.QuietPeriod))
+	if err := fm.backfillFlakes(context, repo, cutoff, dryRun, rr); err != nil {
+		err = fmt.Errorf("unable to backfill old flakes for repo %s: %v", repo, err)
+		rr.Fail(err)
+		return err
+	}
+
+	byTest := make(map[string][]*storage.FlakeOccurrence)
+	if err := fm.store.QueryUnfiledFlakes(context, repo.OrgLogin, repo.RepoName, cutoff, now, func(o *storage.FlakeOccurrence) error {
+		byTest[o.TestName] = append(byTest[o.TestName], o)
+		return nil
+	}); err != nil {
+		err = fmt.Errorf("unable to read unfiled flakes for repo %s: %v", repo, err)
+		rr.Fail(err)
+		return err
+	}
+
+	var tests []interface{}
+	for testName := range byTest {
+		tests = append(tests, testName)
+	}
+	sort.Slice(tests, func(i, j int) bool { return tests[i].(string) < tests[j].(string) })
+
+	scope.Infof("Found %d tests with unfiled flakes in repo %s", len(tests), repo)
+
+	return pipeline.ForEach(context, tests, parallelism, func(item interface{}) error {
+		testName := item.(string)
+		if err := fm.fileIssue(context, repo, fir, owners, testName, byTest[testName], dryRun, rr); err != nil {
+			err = fmt.Errorf("unable to file flake issue for test %s in repo %s: %v", testName, repo, err)
+			rr.Fail(err)
+			return err
+		}
+		return nil
+	})
+}
+
+// backfillFlakes marks the unfiled flakes that happened before the cutoff as predating issue filing
+func (fm *FlakeManager) backfillFlakes(context context.Context, repo gh.RepoDesc, cutoff time.Time, dryRun bool, rr *report.RepoReport) error {
+	var flakes []*storage.ConfirmedFlake
+	if err := fm.store.QueryUnfiledFlakes(context, repo.OrgLogin, repo.RepoName, time.Time{}, cutoff, func(o *storage.FlakeOccurrence) error {
+		flakes = append(flakes, confirmedFlake(o, 0))
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	if len(flakes) == 0 {
+		return nil
+	}
+
+	if dryRun {
+		scope.Infof("Would have backfilled %d flakes older than %s in repo %s", len(flakes), cutoff.Format("2006-01-02"), repo)
+		return nil
+	}
+
+	// stay well within the number of mutations a single commit allows
+	for start := 0; start < len(flakes); start += backfillBatchSize {
+		end := start + backfillBatchSize
+		if end > len(flakes) {
+			end = len(flakes)
+		}
+
+		if err := fm.store.WriteConfirmedFlakes(context, flakes[start:end]); err != nil {
+			return err
+		}
+	}
+
+	scope.Infof("Backfilled %d flakes older than %s in repo %s", len(flakes), cutoff.Format("2006-01-02"), repo)
+	rr.Add("backfilled", len(flakes))
+	return nil
+}
+
+// confirmedFlake returns the confirmed flake behind an occurrence, linked to the given issue
+func confirmedFlake(o *storage.FlakeOccurrence, issueNumber int64) *storage.ConfirmedFlake {
+	return &storage.ConfirmedFlake{
+		OrgLogin:          o.OrgLogin,
+		RepoName:          o.RepoName,
+		PullRequestNumber: o.PullRequestNumber,
+		RunNumber:         o.RunNumber,
+		TestName:          o.TestName,
+		Done:              o.Done,
+		PassingRunNumber:  o.PassingRunNumber,
+		IssueNum:          &issueNumber,
+	}
+}
+
+func (fm *FlakeManager) fileIssue(context context.Context, repo gh.RepoDesc, fir *flakeIssueRecord, owners []owner,
+	testName string, occurrences []*storage.FlakeOccurrence, dryRun bool, rr *report.RepoReport) error {
+	fi, err := fm.store.ReadFlakeIssue(context, repo.OrgLogin, repo.RepoName, testName)
+	if err != nil {
+		return err
+	}
+
+	runs, count := describeOccurrences(fir, occurrences)
+
+	if dryRun {
+		if fi == nil {
+			scope.Infof("Would have filed an issue for %d flakes of test %s in repo %s", count, testName, repo)
+		} else {
+			scope.Infof("Would have added %d flakes of test %s to issue %d in repo %s", count, testName, fi.IssueNumber, repo)
+		}
+		rr.Add("skipped", 1)
+		return nil
+	}
+
+	lastSeen := time.Time{}
+	for _, o := range occurrences {
+		if o.FinishTime.After(lastSeen) {
+			lastSeen = o.FinishTime
+		}
+	}
+
+	if fi == nil {
+		title := fmt.Sprintf("Flaky test: %s", testName)
+
+		// an earlier run may have filed the issue without getting to record it
+		number, err := fm.findIssue(context, repo, fir, title)
+		if err != nil {
+			return err
+		}
+
+		if number == 0 {
+			body := fmt.Sprintf("The %s test failed and then passed on a retry at the same commit:\n\n%s%s", testName, runs, issueSignature)
+			labels := issueLabels(fir, owners, testName)
+
+			issue, _, err := fm.gc.ThrottledCall(func(client *github.Client) (interface{}, *github.Response, error) {
+				return client.Issues.Create(context, repo.OrgLogin, repo.RepoName, &github.IssueRequest{
+					Title:  &title,
+					Body:   &body,
+					Labels: &labels,
+				})
+			})
+			if err != nil {
+				return err
+			}
+			number = int64(issue.(*github.Issue).GetNumber())
+
+			scope.Infof("Filed issue %d for flaky test %s in repo %s", number, testName, repo)
+			rr.Add("filed", 1)
+		} else {
+			scope.Infof("Found issue %d for flaky test %s in repo %s", number, testName, repo)
+			rr.Add("found", 1)
+		}
+
+		fi = &storage.FlakeIssue{
+			OrgLogin:    repo.OrgLogin,
+			RepoName:    repo.RepoName,
+			TestName:    testName,
+			IssueNumber: number,
+			OpenedAt:    time.Now(),
+			LastSeenAt:  lastSeen,
+			Occurrences: int64(count),
+		}
+
+		// record the issue before anything else can fail, so it isn't filed again
+		if err := fm.store.WriteFlakeIssues(context, []*storage.FlakeIssue{fi}); err != nil {
+			return err
+		}
+	} else {
+		// the issue may have been closed for being quiet, or by someone who thought the flake was fixed
+		closed := fi.ClosedAt != nil
+		if !closed {
+			issue, err := fm.store.ReadIssue(context, repo.OrgLogin, repo.RepoName, int(fi.IssueNumber))
+			if err != nil {
+				return err
+			}
+			closed = issue != nil && issue.State == "closed"
+		}
+
+		if closed {
+			if err := fm.setIssueState(context, repo, fi, "open"); err != nil {
+				return err
+			}
+
+			fi.ClosedAt = nil
+			scope.Infof("Reopened issue %d for flaky test %s in repo %s", fi.IssueNumber, testName, repo)
+			rr.Add("reopened", 1)
+		}
+
+		body := fmt.Sprintf("The %s test flaked again:\n\n%s%s", testName, runs, issueSignature)
+		if err := fm.addIssueComment(context, repo, fi, body); err != nil {
+			return err
+		}
+
+		fi.Occurrences += int64(count)
+		if lastSeen.After(fi.LastSeenAt) {
+			fi.LastSeenAt = lastSeen
+		}
+
+		if err := fm.store.WriteFlakeIssues(context, []*storage.FlakeIssue{fi}); err != nil {
+			return err
+		}
+
+		scope.Infof("Added %d flakes of test %s to issue %d in repo %s", count, testName, fi.IssueNumber, repo)
+		rr.Add("updated", 1)
+	}
+
+	// link the flakes to the issue, so they're not reported again
+	flakes := make([]*storage.ConfirmedFlake, len(occurrences))
+	for i, o := range occurrences {
+		flakes[i] = confirmedFlake(o, fi.IssueNumber)
+	}
+
+	return fm.store.WriteConfirmedFlakes(context, flakes)
+}
+
+// findIssue returns the number of the open issue with the given title and the record's label, or 0 if there's none
+func (fm *FlakeManager) findIssue(context context.Context, repo gh.RepoDesc, fir *flakeIssueRecord, title string) (int64, error) {
+	query := fmt.Sprintf("repo:%s is:issue is:open in:title %q", repo, title)
+	if fir.Label != "" {
+		query += fmt.Sprintf(" label:%q", fir.Label)
+	}
+
+	result, _, err := fm.gc.ThrottledCall(func(client *github.Client) (interface{}, *github.Response, error) {
+		return client.Search.Issues(context, query, nil)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	// the search matches words, so make sure the title is the same
+	for _, issue := range result.(*github.IssuesSearchResult).Issues {
+		if issue.GetTitle() == title {
+			return int64(issue.GetNumber()), nil
+		}
+	}
+
+	return 0, nil
+}
+
+func (fm *FlakeManager) closeQuietIssues(context context.Context, repo gh.RepoDesc, fir *flakeIssueRecord, parallelism int,
+	dryRun bool, rr *report.RepoReport) error {
+	now := time.Now()
+
+	var quiet []interface{}
+	if err := fm.store.QueryOpenFlakeIssues(context, repo.OrgLogin, repo.RepoName, func(fi *storage.FlakeIssue) error {
+		if isQuiet(fi, fir, now) {
+			quiet = append(quiet, fi)
+		}
+		return nil
+	}); err != nil {
+		err = fmt.Errorf("unable to read flake issues for repo %s: %v", repo, err)
+		rr.Fail(err)
+		return err
+	}
+
+	return pipeline.ForEach(context, quiet, parallelism, func(item interface{}) error {
+		fi := item.(*storage.FlakeIssue)
+		if err := fm.closeIssue(context, repo, fir, fi, now, dryRun, rr); err != nil {
+			err = fmt.Errorf("unable to close flake issue %d in repo %s: %v", fi.IssueNumber, repo, err)
+			rr.Fail(err)
+			return err
+		}
+		return nil
+	})
+}
+
+// isQuiet returns whether a flake issue's test has gone without flaking for the record's quiet period
+func isQuiet(fi *storage.FlakeIssue, fir *flakeIssueRecord, now time.Time) bool {
+	return fi.ClosedAt == nil && now.Sub(fi.LastSeenAt) >= time.Duration(fir.QuietPeriod)
+}
+
+func (fm *FlakeManager) closeIssue(context context.Context, repo gh.RepoDesc, fir *flakeIssueRecord, fi *storage.FlakeIssue, now time.Time,
+	dryRun bool, rr *report.RepoReport) error {
+	if dryRun {
+		scope.Infof("Would have closed quiet issue %d for flaky test %s in repo %s", fi.IssueNumber, fi.TestName, repo)
+		rr.Add("skipped", 1)
+		return nil
+	}
+
+	issue, err := fm.store.ReadIssue(context, repo.OrgLogin, repo.RepoName, int(fi.IssueNumber))
+	if err != nil {
+		return err
+	}
+
+	// leave issues someone already closed alone, they'll be reopened if the test flakes again
+	if issue == nil || issue.State != "closed" {
+		days := int(time.Duration(fir.QuietPeriod).Hours() / 24)
+		body := fmt.Sprintf("The %s test hasn't flaked in %d days, so this issue is being closed. "+
+			"It will be reopened if the test flakes again.%s", fi.TestName, days, issueSignature)
+		if err := fm.addIssueComment(context, repo, fi, body); err != nil {
+			return err
+		}
+
+		if err := fm.setIssueState(context, repo, fi, "closed"); err != nil {
+			return err
+		}
+	}
+
+	fi.ClosedAt = &now
+	if err := fm.store.WriteFlakeIssues(context, []*storage.FlakeIssue{fi}); err != nil {
+		return err
+	}
+
+	scope.Infof("Closed quiet issue %d for flaky test %s in repo %s", fi.IssueNumber, fi.TestName, repo)
+	rr.Add("closed", 1)
+	return nil
+}
+
+func (fm *FlakeManager) addIssueComment(context context.Context, repo gh.RepoDesc, fi *storage.FlakeIssue, body string) error {
+	_, _, err := fm.gc.ThrottledCall(func(client *github.Client) (interface{}, *github.Response, error) {
+		return client.Issues.CreateComment(context, repo.OrgLogin, repo.RepoName, int(fi.IssueNumber), &github.IssueComment{
+			Body: &body,
+		})
+	})
+	return err
+}
+
+func (fm *FlakeManager) setIssueState(context context.Context, repo gh.RepoDesc, fi *storage.FlakeIssue, state string) error {
+	_, _, err := fm.gc.ThrottledCall(func(client *github.Client) (interface{}, *github.Response, error) {
+		return client.Issues.Edit(context, repo.OrgLogin, repo.RepoName, int(fi.IssueNumber), &github.IssueRequest{
+			State: &state,
+		})
+	})
+	return err
+}
@@ -0,0 +1,108 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flakemgr
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"istio.io/bots/policybot/pkg/config"
+	"istio.io/bots/policybot/pkg/storage"
+)
+
+func TestIssueLabels(t *testing.T) {
+	fir := &flakeIssueRecord{
+		Label: "kind/flake",
+		Owners: []flakeOwner{
+			{Tests: []string{"^integ-pilot", "^integ-ambient"}, Area: "area/networking", Team: "team/networking"},
+			{Tests: []string{"^integ-"}, Area: "area/test and release"},
+		},
+	}
+
+	owners, err := compileOwners(fir)
+	if err != nil {
+		t.Fatalf("unable to compile owners: %v", err)
+	}
+
+	cases := []struct {
+		test string
+		want []string
+	}{
+		{"integ-ambient-k8s-tests", []string{"kind/flake", "area/networking", "team/networking"}},
+		{"integ-security-k8s-tests", []string{"kind/flake", "area/test and release"}},
+		{"unit-tests", []string{"kind/flake"}},
+	}
+
+	for _, c := range cases {
+		if got := issueLabels(fir, owners, c.test); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s: got labels %v, expected %v", c.test, got, c.want)
+		}
+	}
+
+	if _, err := compileOwners(&flakeIssueRecord{Owners: []flakeOwner{{Tests: []string{"("}}}}); err == nil {
+		t.Error("expected an error for an invalid test pattern")
+	}
+}
+
+func TestDescribeOccurrences(t *testing.T) {
+	fir := &flakeIssueRecord{RunURLPrefix: "https://prow.example.com/"}
+	passed := "pr-logs/pull/istio_istio/10/unit/101/"
+	finished := time.Date(2026, 10, 17, 8, 0, 0, 0, time.UTC)
+
+	occurrences := []*storage.FlakeOccurrence{
+		{OrgLogin: "istio", RepoName: "istio", PullRequestNumber: 10, RunNumber: 100, PassingRunNumber: 101,
+			FinishTime: finished, FailedRunPath: "pr-logs/pull/istio_istio/10/unit/100/", PassingRunPath: &passed},
+		// the same failed run matched with a second passing run
+		{OrgLogin: "istio", RepoName: "istio", PullRequestNumber: 10, RunNumber: 100, PassingRunNumber: 102,
+			FinishTime: finished, FailedRunPath: "pr-logs/pull/istio_istio/10/unit/100/"},
+		{OrgLogin: "istio", RepoName: "istio", PullRequestNumber: 11, RunNumber: 200, PassingRunNumber: 201,
+			FinishTime: finished, FailedRunPath: "pr-logs/pull/istio_istio/11/unit/200/"},
+	}
+
+	got, count := describeOccurrences(fir, occurrences)
+	if count != 2 {
+		t.Errorf("got %d runs, expected 2", count)
+	}
+
+	want := "- istio/istio#10: [run 100](https://prow.example.com/pr-logs/pull/istio_istio/10/unit/100/) failed on 2026-10-17, " +
+		"then [run 101](https://prow.example.com/pr-logs/pull/istio_istio/10/unit/101/) passed\n" +
+		"- istio/istio#11: [run 200](https://prow.example.com/pr-logs/pull/istio_istio/11/unit/200/) failed on 2026-10-17, then run 201 passed\n"
+	if got != want {
+		t.Errorf("got:\n%s\nexpected:\n%s", got, want)
+	}
+}
+
+func TestIsQuiet(t *testing.T) {
+	fir := &flakeIssueRecord{QuietPeriod: config.Duration(14 * 24 * time.Hour)}
+	now := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+	closed := now.AddDate(0, 0, -1)
+
+	cases := []struct {
+		name string
+		fi   *storage.FlakeIssue
+		want bool
+	}{
+		{"recent", &storage.FlakeIssue{LastSeenAt: now.AddDate(0, 0, -3)}, false},
+		{"quiet", &storage.FlakeIssue{LastSeenAt: now.AddDate(0, 0, -14)}, true},
+		{"already closed", &storage.FlakeIssue{LastSeenAt: now.AddDate(0, 0, -30), ClosedAt: &closed}, false},
+	}
+
+	for _, c := range cases {
+		if got := isQuiet(c.fi, fir, now); got != c.want {
+			t.Errorf("%s: got %v, expected %v", c.name, got, c.want)
+		}
+	}
+}
@@ -18,8 +18,12 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/hashicorp/go-multierror"
+
 	"istio.io/bots/policybot/pkg/config"
 	"istio.io/bots/policybot/pkg/gh"
+	"istio.io/bots/policybot/pkg/pipeline"
+	"istio.io/bots/policybot/pkg/report"
 	"istio.io/bots/policybot/pkg/storage"
 	"istio.io/bots/policybot/pkg/storage/cache"
 	"istio.io/istio/pkg/log"
@@ -47,41 +51,70 @@ func New(gc *gh.ThrottledClient, store storage.Store, cache *cache.Cache, reg *c
 	}
 }
 
-// Nag does the nagging
-func (fm *FlakeManager) Nag(context context.Context, dryRun bool) error {
+// Nag does the nagging.
+func (fm *FlakeManager) Nag(context context.Context, dryRun bool) (*report.Report, error) {
+	core := fm.reg.Core()
+	rep := report.New("flakemgr")
+
+	var repos []interface{}
 	for _, repo := range fm.reg.Repos() {
+		repos = append(repos, repo)
+	}
+
+	err := pipeline.ForEach(context, repos, core.RepoParallelism, func(item interface{}) error {
+		repo := item.(gh.RepoDesc)
+		rr := rep.Repo(repo.OrgAndRepo)
+
+		var result *multierror.Error
 		for _, r := range fm.reg.Records(recordType, repo.OrgAndRepo) {
 			nag := r.(*flakeNagRecord)
 
-			if err := fm.handleNag(context, repo, nag, dryRun); err != nil {
-				return err
+			if err := fm.handleNag(context, repo, nag, dryRun, core.ItemParallelism, rr); err != nil {
+				result = multierror.Append(result, err)
 			}
 		}
-	}
 
-	return nil
+		return result.ErrorOrNil()
+	})
+
+	rep.Finish()
+	return rep, err
 }
 
-func (fm *FlakeManager) handleNag(context context.Context, repo gh.RepoDesc, nag *flakeNagRecord, dryRun bool) error {
+func (fm *FlakeManager) handleNag(context context.Context, repo gh.RepoDesc, nag *flakeNagRecord, dryRun bool,
+	parallelism int, rr *report.RepoReport) error {
 	issues, err := fm.store.QueryTestFlakeIssues(context, repo.OrgLogin, repo.RepoName, nag.InactiveDays, nag.CreatedDays)
 	if err != nil {
-		return fmt.Errorf("unable to read test flake issues from storage: %v", err)
+		err = fmt.Errorf("unable to read test flake issues from storage: %v", err)
+		rr.Fail(err)
+		return err
 	}
 
 	scope.Infof("Found %v potential flake issues for repo %v", len(issues), repo)
+	rr.Add("flakeIssues", len(issues))
 
+	var items []interface{}
 	for _, issue := range issues {
+		items = append(items, issue)
+	}
+
+	return pipeline.ForEach(context, items, parallelism, func(item interface{}) error {
+		issue := item.(*storage.Issue)
+
 		if dryRun {
 			scope.Infof("Would have nagged issue %d from repo %v", issue.IssueNumber, repo)
-			continue
+			rr.Add("skipped", 1)
+			return nil
 		}
 
 		if err := fm.gc.AddOrReplaceBotComment(context, repo.OrgLogin, repo.RepoName, int(issue.IssueNumber), issue.Author, nag.Message, nagSignature); err != nil {
-			return fmt.Errorf("unable to create nagging comment for issue %d in repo %v: %v", issue.IssueNumber, repo, err)
+			err = fmt.Errorf("unable to create nagging comment for issue %d in repo %v: %v", issue.IssueNumber, repo, err)
+			rr.Fail(err)
+			return err
 		}
 
 		scope.Infof("Nagged issue %d from repo %v", issue.IssueNumber, repo)
-	}
-
-	return nil
+		rr.Add("nagged", 1)
+		return nil
+	})
 }
@@ -15,10 +15,15 @@
 package flakemgr
 
 import (
+	"time"
+
 	"istio.io/bots/policybot/pkg/config"
 )
 
-const recordType = "flakenag"
+const (
+	recordType      = "flakenag"
+	issueRecordType = "flakeissue"
+)
 
 type flakeNagRecord struct {
 	config.RecordBase
@@ -34,8 +39,44 @@ type flakeNagRecord struct {
 	Message string
 }
 
+// flakeIssueRecord configures the issues filed for confirmed flaky tests.
+type flakeIssueRecord struct {
+	config.RecordBase
+
+	// Label is applied to every flake issue, alongside the owners' labels.
+	Label string
+
+	// QuietPeriod is how long a test must go without flaking before its issue is closed.
+	QuietPeriod config.Duration
+
+	// RunURLPrefix is prepended to the storage path of a test run to link to the run.
+	RunURLPrefix string
+
+	// Owners map tests to the area and team that own them. The first owner matching a test wins.
+	Owners []flakeOwner
+}
+
+type flakeOwner struct {
+	// Tests are regular expressions matched against test names.
+	Tests []string
+
+	// Area is the area label applied to issues for matching tests, such as area/networking.
+	Area string
+
+	// Team is the label of the team applied to issues for matching tests.
+	Team string
+}
+
 func init() {
 	config.RegisterType(recordType, config.OnePerRepo, func() config.Record {
 		return new(flakeNagRecord)
 	})
+
+	config.RegisterType(issueRecordType, config.OnePerRepo, func() config.Record {
+		return &flakeIssueRecord{
+			Label:        "kind/flake",
+			QuietPeriod:  config.Duration(14 * 24 * time.Hour),
+			RunURLPrefix: "https://prow.istio.io/view/gcs/istio-prow/",
+		}
+	})
 }
@@ -22,6 +22,8 @@ import (
 
 	"istio.io/bots/policybot/pkg/config"
 	"istio.io/bots/policybot/pkg/gh"
+	"istio.io/bots/policybot/pkg/pipeline"
+	"istio.io/bots/policybot/pkg/report"
 	"istio.io/istio/pkg/log"
 )
 
@@ -40,23 +42,47 @@ func New(gc *gh.ThrottledClient, reg *config.Registry) *LabelMgr {
 	}
 }
 
-func (lm *LabelMgr) MakeConfiguredLabels(context context.Context, dryRun bool) error {
+// MakeConfiguredLabels creates or updates the configured labels in every repo.
+func (lm *LabelMgr) MakeConfiguredLabels(context context.Context, dryRun bool) (*report.Report, error) {
+	core := lm.reg.Core()
+	rep := report.New("labelmgr")
+
+	var repos []interface{}
 	for _, repo := range lm.reg.Repos() {
+		repos = append(repos, repo)
+	}
+
+	err := pipeline.ForEach(context, repos, core.RepoParallelism, func(item interface{}) error {
+		repo := item.(gh.RepoDesc)
+		rr := rep.Repo(repo.OrgAndRepo)
+
+		var labels []interface{}
 		for _, r := range lm.reg.Records(recordType, repo.OrgAndRepo) {
-			label := r.(*labelRecord)
+			labels = append(labels, r)
+		}
+
+		return pipeline.ForEach(context, labels, core.ItemParallelism, func(item interface{}) error {
+			label := item.(*labelRecord)
 
 			if dryRun {
 				scope.Infof("Would have created or updated label %s in repo %s", label.Name, repo)
-				continue
+				rr.Add("skipped", 1)
+				return nil
 			}
 
 			if err := lm.makeLabel(context, repo, label); err != nil {
-				return fmt.Errorf("unable to create label %s in repo %s: %v", label.Name, repo, err)
+				err = fmt.Errorf("unable to create label %s in repo %s: %v", label.Name, repo, err)
+				rr.Fail(err)
+				return err
 			}
-		}
-	}
 
-	return nil
+			rr.Add("labels", 1)
+			return nil
+		})
+	})
+
+	rep.Finish()
+	return rep, err
 }
 
 func (lm *LabelMgr) makeLabel(context context.Context, repo gh.RepoDesc, label *labelRecord) error {
@@ -0,0 +1,106 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lifecyclemgr
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"istio.io/bots/policybot/pkg/storage"
+)
+
+// The transitions recorded in the audit trail
+const (
+	transitionStale           = "marked-stale"
+	transitionUnstale         = "unstaled"
+	transitionClosed          = "closed"
+	transitionStaleproof      = "staleproof"
+	transitionNeedsTriage     = "needs-triage"
+	transitionTriaged         = "triaged"
+	transitionEscalated       = "escalated"
+	transitionDeescalated     = "escalation-cleared"
+	transitionWaiting         = "waiting-on-author"
+	transitionNudged          = "author-nudged"
+	transitionAuthorClosed    = "closed-waiting-on-author"
+	transitionAuthorResponded = "author-responded"
+)
+
+// maxExplainedEvents is the number of recent transitions described by /lifecycle explain
+const maxExplainedEvents = 5
+
+// audit records a transition of an issue or PR. Failures are logged rather than returned since the change was
+// already made on GitHub by then.
+func (lm *LifecycleMgr) audit(context context.Context, issue *storage.Issue, event *storage.LifecycleEvent, dryRun bool) {
+	if dryRun {
+		return
+	}
+
+	event.OrgLogin = issue.OrgLogin
+	event.RepoName = issue.RepoName
+	event.IssueNumber = issue.IssueNumber
+	if event.RecordedAt.IsZero() {
+		event.RecordedAt = time.Now()
+	}
+
+	if err := lm.store.WriteLifecycleEvents(context, []*storage.LifecycleEvent{event}); err != nil {
+		scope.Errorf("Unable to record the %s transition of issue/PR %d in repo %s/%s: %v", event.Transition, issue.IssueNumber,
+			issue.OrgLogin, issue.RepoName, err)
+	}
+}
+
+// Explain describes the recent lifecycle transitions of an issue or PR, and why they happened.
+func (lm *LifecycleMgr) Explain(context context.Context, issue *storage.Issue) (string, error) {
+	var events []*storage.LifecycleEvent
+	if err := lm.store.QueryLifecycleEventsByIssue(context, issue.OrgLogin, issue.RepoName, int(issue.IssueNumber),
+		func(event *storage.LifecycleEvent) error {
+			if len(events) < maxExplainedEvents {
+				events = append(events, event)
+			}
+			return nil
+		}); err != nil {
+		return "", fmt.Errorf("unable to read the lifecycle events of issue/PR %d in repo %s/%s: %v", issue.IssueNumber,
+			issue.OrgLogin, issue.RepoName, err)
+	}
+
+	return explain(events), nil
+}
+
+// explain formats lifecycle events, given newest first, as a markdown list in chronological order
+func explain(events []*storage.LifecycleEvent) string {
+	if len(events) == 0 {
+		return "The lifecycle manager hasn't made any changes to this issue or pull request."
+	}
+
+	var sb strings.Builder
+	sb.WriteString("Here are the latest changes the lifecycle manager made to this issue or pull request:\n\n")
+	for i := len(events) - 1; i >= 0; i-- {
+		e := events[i]
+		sb.WriteString(fmt.Sprintf("- %s: **%s**", e.RecordedAt.Format("2006-01-02"), e.Transition))
+		if e.Policy != "" {
+			sb.WriteString(fmt.Sprintf(" under the `%s` policy", e.Policy))
+		}
+		if e.Reason != "" {
+			sb.WriteString(", " + e.Reason)
+		}
+		if e.Deadline != nil {
+			sb.WriteString(fmt.Sprintf(" (next step due on %s)", e.Deadline.Format("2006-01-02")))
+		}
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
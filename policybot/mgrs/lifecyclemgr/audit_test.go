@@ -0,0 +1,69 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lifecyclemgr
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"istio.io/bots/policybot/pkg/storage"
+)
+
+func TestExplain(t *testing.T) {
+	if got := explain(nil); !strings.Contains(got, "hasn't made any changes") {
+		t.Errorf("Unexpected explanation without events: %s", got)
+	}
+
+	deadline := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	events := []*storage.LifecycleEvent{
+		{
+			RecordedAt: time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC),
+			Transition: transitionUnstale,
+			Policy:     "flakes",
+			Reason:     "since a member commented on it on 2026-10-01",
+		},
+		{
+			RecordedAt: time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC),
+			Transition: transitionStale,
+			Policy:     "flakes",
+			Reason:     "since no member had commented on it since 2026-08-01",
+			Deadline:   &deadline,
+		},
+		{
+			RecordedAt: time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC),
+			Transition: transitionTriaged,
+			Reason:     "since somebody applied a triage label",
+		},
+	}
+
+	got := explain(events)
+	expected := []string{
+		"- 2026-07-01: **triaged**, since somebody applied a triage label\n",
+		"- 2026-09-01: **marked-stale** under the `flakes` policy, since no member had commented on it since 2026-08-01 (next step due on 2026-11-01)\n",
+		"- 2026-10-02: **unstaled** under the `flakes` policy, since a member commented on it on 2026-10-01\n",
+	}
+
+	last := -1
+	for _, e := range expected {
+		i := strings.Index(got, e)
+		if i < 0 {
+			t.Fatalf("Expected explanation to contain %q, got:\n%s", e, got)
+		} else if i < last {
+			t.Errorf("Expected events in chronological order, got:\n%s", got)
+		}
+		last = i
+	}
+}
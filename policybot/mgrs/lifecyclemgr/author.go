@@ -0,0 +1,276 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lifecyclemgr
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/go-github/v26/github"
+
+	"istio.io/bots/policybot/pkg/storage"
+)
+
+const authorWaitSignature = "\n\n_Waiting on the author, courtesy of the issue and PR lifecycle manager_."
+
+// waitStep is the action manageAuthorWait takes for an issue or PR waiting on its author
+type waitStep int
+
+const (
+	waitNone waitStep = iota
+	waitReplied
+	waitNudge
+	waitClose
+)
+
+// authorWaitStep decides what to do about an issue or PR waiting on its author, given when the author last commented
+func authorWaitStep(wait *storage.AuthorWait, replied time.Time, closed bool, lr *lifecycleRecord, cal *calendar, now time.Time) waitStep {
+	if replied.After(wait.StartedAt) {
+		return waitReplied
+	} else if closed {
+		// either the bot already closed it, or somebody else did
+		return waitNone
+	}
+
+	elapsed := cal.elapsed(wait.StartedAt, now)
+	if elapsed > time.Duration(lr.AuthorCloseDelay) {
+		return waitClose
+	} else if elapsed > time.Duration(lr.AuthorNudgeDelay) && wait.NudgedAt == nil {
+		return waitNudge
+	}
+
+	return waitNone
+}
+
+// manageAuthorWait runs the clock of an issue or PR carrying the waiting on author label. It returns true when
+// the item is waiting on its author, in which case the rest of the lifecycle doesn't apply to it.
+func (lm *LifecycleMgr) manageAuthorWait(context context.Context, issue *storage.Issue, st *stats, lr *lifecycleRecord, dryRun bool) (bool, error) {
+	if lr.WaitingOnAuthorLabel == "" {
+		return false, nil
+	}
+
+	wait, err := lm.store.ReadAuthorWait(context, issue.OrgLogin, issue.RepoName, int(issue.IssueNumber))
+	if err != nil {
+		return false, fmt.Errorf("unable to read author wait for issue/PR %d in repo %s/%s: %v", issue.IssueNumber, issue.OrgLogin, issue.RepoName, err)
+	}
+
+	if !hasLabel(issue, lr.WaitingOnAuthorLabel) {
+		if wait != nil {
+			// somebody removed the label by hand, so stop waiting
+			return false, lm.stopWaiting(context, issue, wait, dryRun)
+		}
+		return false, nil
+	}
+
+	now := time.Now()
+
+	if wait == nil {
+		if issue.State == "closed" {
+			return true, nil
+		}
+
+		wait = &storage.AuthorWait{
+			OrgLogin:    issue.OrgLogin,
+			RepoName:    issue.RepoName,
+			IssueNumber: issue.IssueNumber,
+			Author:      issue.Author,
+			StartedAt:   now,
+		}
+
+		cal := lm.calendarFor(issue.OrgLogin, issue.RepoName)
+		closeAt := cal.deadline(now, time.Duration(lr.AuthorCloseDelay))
+		lm.audit(context, issue, &storage.LifecycleEvent{
+			Transition: transitionWaiting,
+			Policy:     waitingOnAuthorPolicy,
+			Reason:     "since a member is waiting for the author to respond",
+			ClockStart: now,
+			Deadline:   &closeAt,
+		}, dryRun)
+
+		return true, lm.writeAuthorWait(context, wait, "Started waiting on the author of", dryRun)
+	}
+
+	replied, err := lm.store.GetLatestIssueUserComment(context, issue.OrgLogin, issue.RepoName, int(issue.IssueNumber), wait.Author)
+	if err != nil {
+		return false, fmt.Errorf("could not get author comment for issue/PR %d in repo %s/%s: %v", issue.IssueNumber, issue.OrgLogin, issue.RepoName, err)
+	}
+
+	cal := lm.calendarFor(issue.OrgLogin, issue.RepoName)
+	startDate := wait.StartedAt.In(cal.loc).Format("2006-01-02")
+
+	switch authorWaitStep(wait, replied, issue.State == "closed", lr, cal, now) {
+	case waitReplied:
+		st.authorResponded++
+
+		if err := lm.removeLabel(context, issue, lr.WaitingOnAuthorLabel, dryRun); err != nil {
+			return false, err
+		}
+
+		reason := fmt.Sprintf("since the author commented on %s", replied.In(cal.loc).Format("2006-01-02"))
+		if wait.ClosedAt != nil && issue.State == "closed" {
+			if err := lm.reopenIssue(context, issue, dryRun); err != nil {
+				return false, err
+			}
+			reason += ", which reopened it"
+		}
+
+		lm.audit(context, issue, &storage.LifecycleEvent{
+			Transition: transitionAuthorResponded,
+			Policy:     waitingOnAuthorPolicy,
+			Reason:     reason,
+			ClockStart: wait.StartedAt,
+		}, dryRun)
+
+		// the regular lifecycle takes over again on the next run
+		return true, lm.stopWaiting(context, issue, wait, dryRun)
+
+	case waitClose:
+		st.closed++
+
+		if err := lm.closeIssue(context, issue, dryRun); err != nil {
+			return false, err
+		}
+
+		if lr.AuthorCloseComment != "" {
+			if err := lm.addAuthorComment(context, issue, fmt.Sprintf(lr.AuthorCloseComment, startDate), dryRun); err != nil {
+				return false, err
+			}
+		}
+
+		lm.audit(context, issue, &storage.LifecycleEvent{
+			Transition: transitionAuthorClosed,
+			Policy:     waitingOnAuthorPolicy,
+			Reason:     fmt.Sprintf("since the author hasn't responded since %s", startDate),
+			ClockStart: wait.StartedAt,
+		}, dryRun)
+
+		wait.ClosedAt = &now
+		return true, lm.writeAuthorWait(context, wait, "Closed for lack of an author response", dryRun)
+
+	case waitNudge:
+		st.nudgedAuthors++
+
+		closeAt := cal.deadline(wait.StartedAt, time.Duration(lr.AuthorCloseDelay))
+		if lr.AuthorNudgeComment != "" {
+			closeDate := closeAt.In(cal.loc).Format("2006-01-02")
+			if err := lm.addAuthorComment(context, issue, fmt.Sprintf(lr.AuthorNudgeComment, startDate, closeDate), dryRun); err != nil {
+				return false, err
+			}
+		}
+
+		lm.audit(context, issue, &storage.LifecycleEvent{
+			Transition: transitionNudged,
+			Policy:     waitingOnAuthorPolicy,
+			Reason:     fmt.Sprintf("since the author hasn't responded since %s", startDate),
+			ClockStart: wait.StartedAt,
+			Deadline:   &closeAt,
+		}, dryRun)
+
+		wait.NudgedAt = &now
+		return true, lm.writeAuthorWait(context, wait, "Nudged the author of", dryRun)
+	}
+
+	return true, nil
+}
+
+// WaitOnAuthor marks an issue or PR as waiting on its author, which starts the author's response clock.
+func (lm *LifecycleMgr) WaitOnAuthor(context context.Context, issue *storage.Issue) error {
+	r, ok := lm.reg.SingleRecord(RecordType, issue.OrgLogin+"/"+issue.RepoName)
+	if !ok {
+		return fmt.Errorf("no lifecycle configuration for repo %s/%s", issue.OrgLogin, issue.RepoName)
+	}
+
+	lr := r.(*lifecycleRecord)
+	if lr.WaitingOnAuthorLabel == "" {
+		return fmt.Errorf("no waiting on author label configured for repo %s/%s", issue.OrgLogin, issue.RepoName)
+	}
+
+	if !hasLabel(issue, lr.WaitingOnAuthorLabel) {
+		if err := lm.addLabel(context, issue, lr.WaitingOnAuthorLabel, false); err != nil {
+			return err
+		}
+		issue.Labels = append(issue.Labels, lr.WaitingOnAuthorLabel)
+	}
+
+	_, err := lm.manageAuthorWait(context, issue, &stats{}, lr, false)
+	return err
+}
+
+func (lm *LifecycleMgr) stopWaiting(context context.Context, issue *storage.Issue, wait *storage.AuthorWait, dryRun bool) error {
+	if dryRun {
+		scope.Infof("Would have stopped waiting on the author of issue/PR %d in repo %s/%s", issue.IssueNumber, issue.OrgLogin, issue.RepoName)
+		return nil
+	}
+
+	if err := lm.store.DeleteAuthorWaits(context, []*storage.AuthorWait{wait}); err != nil {
+		return fmt.Errorf("unable to delete author wait for issue/PR %d in repo %s/%s: %v", issue.IssueNumber, issue.OrgLogin, issue.RepoName, err)
+	}
+
+	if err := lm.gc.RemoveBotComment(context, issue.OrgLogin, issue.RepoName, int(issue.IssueNumber), authorWaitSignature); err != nil {
+		return err
+	}
+
+	scope.Infof("Stopped waiting on the author of issue/PR %d in repo %s/%s", issue.IssueNumber, issue.OrgLogin, issue.RepoName)
+	return nil
+}
+
+func (lm *LifecycleMgr) writeAuthorWait(context context.Context, wait *storage.AuthorWait, what string, dryRun bool) error {
+	if dryRun {
+		scope.Infof("Would have recorded: %s issue/PR %d in repo %s/%s", what, wait.IssueNumber, wait.OrgLogin, wait.RepoName)
+		return nil
+	}
+
+	if err := lm.store.WriteAuthorWaits(context, []*storage.AuthorWait{wait}); err != nil {
+		return fmt.Errorf("unable to write author wait for issue/PR %d in repo %s/%s: %v", wait.IssueNumber, wait.OrgLogin, wait.RepoName, err)
+	}
+
+	scope.Infof("%s issue/PR %d in repo %s/%s", what, wait.IssueNumber, wait.OrgLogin, wait.RepoName)
+	return nil
+}
+
+func (lm *LifecycleMgr) addAuthorComment(context context.Context, issue *storage.Issue, comment string, dryRun bool) error {
+	if dryRun {
+		scope.Infof("Would have added author comment to issue/PR %d in repo %s/%s", issue.IssueNumber, issue.OrgLogin, issue.RepoName)
+		return nil
+	}
+
+	if err := lm.gc.AddOrReplaceBotComment(context, issue.OrgLogin, issue.RepoName, int(issue.IssueNumber), issue.Author, comment,
+		authorWaitSignature); err != nil {
+		return err
+	}
+
+	scope.Infof("Added author comment to issue/PR %d in repo %s/%s", issue.IssueNumber, issue.OrgLogin, issue.RepoName)
+	return nil
+}
+
+func (lm *LifecycleMgr) reopenIssue(context context.Context, issue *storage.Issue, dryRun bool) error {
+	if dryRun {
+		scope.Infof("Would have reopened issue/PR %d in repo %s/%s", issue.IssueNumber, issue.OrgLogin, issue.RepoName)
+		return nil
+	}
+
+	if _, _, err := lm.gc.ThrottledCall(func(client *github.Client) (interface{}, *github.Response, error) {
+		open := "open"
+		return client.Issues.Edit(context, issue.OrgLogin, issue.RepoName, int(issue.IssueNumber), &github.IssueRequest{
+			State: &open,
+		})
+	}); err != nil {
+		return fmt.Errorf("unable to reopen issue/PR %d in repo %s/%s: %v", issue.IssueNumber, issue.OrgLogin, issue.RepoName, err)
+	}
+
+	scope.Infof("Reopened issue/PR %d in repo %s/%s", issue.IssueNumber, issue.OrgLogin, issue.RepoName)
+	return nil
+}
@@ -0,0 +1,62 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lifecyclemgr
+
+import (
+	"testing"
+	"time"
+
+	"istio.io/bots/policybot/pkg/config"
+	"istio.io/bots/policybot/pkg/storage"
+)
+
+func TestAuthorWaitStep(t *testing.T) {
+	day := 24 * time.Hour
+	now := time.Date(2026, 12, 1, 12, 0, 0, 0, time.UTC)
+	lr := &lifecycleRecord{
+		AuthorNudgeDelay: config.Duration(7 * day),
+		AuthorCloseDelay: config.Duration(21 * day),
+	}
+	nudged := now.Add(-day)
+
+	cases := []struct {
+		name     string
+		started  time.Time
+		nudgedAt *time.Time
+		replied  time.Time
+		closed   bool
+		cal      *calendar
+		expected waitStep
+	}{
+		{"just started", now.Add(-day), nil, time.Time{}, false, wallClock, waitNone},
+		{"due for a nudge", now.Add(-8 * day), nil, time.Time{}, false, wallClock, waitNudge},
+		{"already nudged", now.Add(-8 * day), &nudged, time.Time{}, false, wallClock, waitNone},
+		{"due for closing", now.Add(-22 * day), &nudged, time.Time{}, false, wallClock, waitClose},
+		{"comment before the wait", now.Add(-22 * day), nil, now.Add(-30 * day), false, wallClock, waitClose},
+		{"replied", now.Add(-8 * day), nil, now.Add(-time.Hour), false, wallClock, waitReplied},
+		{"replied after closing", now.Add(-30 * day), &nudged, now.Add(-time.Hour), true, wallClock, waitReplied},
+		{"closed", now.Add(-30 * day), &nudged, time.Time{}, true, wallClock, waitNone},
+		{"weekends don't count", now.Add(-8 * day), nil, time.Time{}, false, &calendar{loc: time.UTC, businessDays: true}, waitNone},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			wait := &storage.AuthorWait{StartedAt: c.started, NudgedAt: c.nudgedAt}
+			if got := authorWaitStep(wait, c.replied, c.closed, lr, c.cal, now); got != c.expected {
+				t.Errorf("Got step %d, expected %d", got, c.expected)
+			}
+		})
+	}
+}
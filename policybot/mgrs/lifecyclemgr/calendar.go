@@ -0,0 +1,180 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lifecyclemgr
+
+import (
+	"fmt"
+	"time"
+)
+
+// calendar measures lifecycle delays, skipping the time that doesn't count such as weekends and freezes
+type calendar struct {
+	loc          *time.Location
+	businessDays bool
+	freezes      []freeze
+}
+
+// freeze is a compiled freeze window, from the start of its first day up to the start of the day after its last
+type freeze struct {
+	name  string
+	start time.Time
+	end   time.Time
+}
+
+// wallClock is used for repos without a calendar record, where every moment counts
+var wallClock = &calendar{loc: time.UTC}
+
+const dateLayout = "2006-01-02"
+
+// compileCalendar validates a calendar record and turns it into a calendar
+func compileCalendar(cr *calendarRecord) (*calendar, error) {
+	tz := cr.Timezone
+	if tz == "" {
+		tz = "UTC"
+	}
+
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone in calendar record %s: %v", cr.Name, err)
+	}
+
+	cal := &calendar{
+		loc:          loc,
+		businessDays: cr.BusinessDays,
+		freezes:      make([]freeze, 0, len(cr.Freezes)),
+	}
+
+	for _, fw := range cr.Freezes {
+		start, err := time.ParseInLocation(dateLayout, fw.Start, loc)
+		if err != nil {
+			return nil, fmt.Errorf("invalid start date for freeze %s in calendar record %s: %v", fw.Name, cr.Name, err)
+		}
+
+		end, err := time.ParseInLocation(dateLayout, fw.End, loc)
+		if err != nil {
+			return nil, fmt.Errorf("invalid end date for freeze %s in calendar record %s: %v", fw.Name, cr.Name, err)
+		}
+
+		if end.Before(start) {
+			return nil, fmt.Errorf("freeze %s in calendar record %s ends before it starts", fw.Name, cr.Name)
+		}
+
+		cal.freezes = append(cal.freezes, freeze{
+			name:  fw.Name,
+			start: start,
+			end:   end.AddDate(0, 0, 1),
+		})
+	}
+
+	return cal, nil
+}
+
+// counts returns whether time spent on the day starting at the given time counts towards lifecycle delays
+func (cal *calendar) counts(day time.Time) bool {
+	if cal.businessDays {
+		if wd := day.Weekday(); wd == time.Saturday || wd == time.Sunday {
+			return false
+		}
+	}
+
+	for _, f := range cal.freezes {
+		if !day.Before(f.start) && day.Before(f.end) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// frozen returns the freeze covering the given time, if any
+func (cal *calendar) frozen(t time.Time) (string, bool) {
+	t = t.In(cal.loc)
+	for _, f := range cal.freezes {
+		if !t.Before(f.start) && t.Before(f.end) {
+			return f.name, true
+		}
+	}
+
+	return "", false
+}
+
+// startOfDay returns midnight of the day holding the given time
+func (cal *calendar) startOfDay(t time.Time) time.Time {
+	t = t.In(cal.loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, cal.loc)
+}
+
+// elapsed returns how much of the time between from and to counts towards lifecycle delays
+func (cal *calendar) elapsed(from time.Time, to time.Time) time.Duration {
+	if !to.After(from) {
+		return 0
+	}
+
+	if cal.wallClock() {
+		return to.Sub(from)
+	}
+
+	var total time.Duration
+	for day := cal.startOfDay(from); day.Before(to); day = day.AddDate(0, 0, 1) {
+		if !cal.counts(day) {
+			continue
+		}
+
+		start := day
+		if start.Before(from) {
+			start = from
+		}
+
+		end := day.AddDate(0, 0, 1)
+		if end.After(to) {
+			end = to
+		}
+
+		total += end.Sub(start)
+	}
+
+	return total
+}
+
+// deadline returns the moment at which the given delay will have elapsed since from
+func (cal *calendar) deadline(from time.Time, delay time.Duration) time.Time {
+	if cal.wallClock() {
+		return from.Add(delay)
+	}
+
+	remaining := delay
+	for day := cal.startOfDay(from); ; day = day.AddDate(0, 0, 1) {
+		if !cal.counts(day) {
+			continue
+		}
+
+		start := day
+		if start.Before(from) {
+			start = from
+		}
+
+		end := day.AddDate(0, 0, 1)
+		if end.Sub(start) >= remaining {
+			return start.Add(remaining)
+		}
+
+		remaining -= end.Sub(start)
+	}
+}
+
+// wallClock returns whether every moment counts, in which case delays are plain durations
+func (cal *calendar) wallClock() bool {
+	return !cal.businessDays && len(cal.freezes) == 0
+}
@@ -0,0 +1,99 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lifecyclemgr
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCalendar(t *testing.T) {
+	cal, err := compileCalendar(&calendarRecord{
+		Timezone:     "UTC",
+		BusinessDays: true,
+		Freezes: []freezeWindow{
+			{Name: "holidays", Start: "2026-12-21", End: "2026-12-31"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	day := 24 * time.Hour
+	date := func(s string) time.Time {
+		d, _ := time.Parse("2006-01-02 15:04", s)
+		return d
+	}
+
+	cases := []struct {
+		name    string
+		from    time.Time
+		to      time.Time
+		elapsed time.Duration
+	}{
+		{"same day", date("2026-12-01 09:00"), date("2026-12-01 17:00"), 8 * time.Hour},
+		{"over a weekend", date("2026-12-04 12:00"), date("2026-12-07 12:00"), day},
+		{"within a weekend", date("2026-12-05 00:00"), date("2026-12-06 23:00"), 0},
+		{"into a freeze", date("2026-12-18 00:00"), date("2026-12-25 00:00"), day},
+		{"across a freeze", date("2026-12-18 00:00"), date("2027-01-05 00:00"), 3 * day},
+		{"backwards", date("2026-12-02 00:00"), date("2026-12-01 00:00"), 0},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := cal.elapsed(c.from, c.to); got != c.elapsed {
+				t.Errorf("Got %v elapsed, expected %v", got, c.elapsed)
+			}
+
+			if c.elapsed > 0 {
+				if got := cal.deadline(c.from, c.elapsed); !got.After(c.from) || cal.elapsed(c.from, got) != c.elapsed {
+					t.Errorf("Deadline %v doesn't match an elapsed time of %v", got, c.elapsed)
+				}
+			}
+		})
+	}
+
+	if got := cal.deadline(date("2026-12-18 12:00"), 2*day); !got.Equal(date("2027-01-04 12:00")) {
+		t.Errorf("Got deadline %v, expected it to skip the freeze and the weekend", got)
+	}
+
+	if name, ok := cal.frozen(date("2026-12-31 23:00")); !ok || name != "holidays" {
+		t.Errorf("Expected the last day of the freeze to be frozen, got %q, %v", name, ok)
+	}
+
+	if _, ok := cal.frozen(date("2027-01-01 00:00")); ok {
+		t.Error("Expected the day after the freeze not to be frozen")
+	}
+
+	from := date("2026-12-18 12:00")
+	if got := wallClock.elapsed(from, from.Add(10*day)); got != 10*day {
+		t.Errorf("Got %v elapsed on the wall clock, expected %v", got, 10*day)
+	}
+}
+
+func TestCompileCalendarErrors(t *testing.T) {
+	records := []*calendarRecord{
+		{Timezone: "Nowhere/Special"},
+		{Freezes: []freezeWindow{{Name: "bad", Start: "2026-13-01", End: "2026-12-31"}}},
+		{Freezes: []freezeWindow{{Name: "bad", Start: "2026-12-01", End: "tomorrow"}}},
+		{Freezes: []freezeWindow{{Name: "backwards", Start: "2026-12-31", End: "2026-12-01"}}},
+	}
+
+	for _, r := range records {
+		if _, err := compileCalendar(r); err == nil {
+			t.Errorf("Expected an error for %+v", r)
+		}
+	}
+}
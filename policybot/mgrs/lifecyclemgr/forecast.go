@@ -0,0 +1,363 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lifecyclemgr
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"istio.io/bots/policybot/pkg/storage"
+)
+
+// Forecast lists what the lifecycle manager will do to the open issues and PRs in storage over the coming days,
+// assuming nobody comments on them in the meantime.
+type Forecast struct {
+	Start     time.Time       `json:"start"`
+	Days      int             `json:"days"`
+	Events    []ForecastEvent `json:"events"`
+	Breakdown []ForecastCount `json:"breakdown"`
+}
+
+// ForecastEvent is an issue or PR being marked stale or closed on a given day.
+type ForecastEvent struct {
+	Date        time.Time `json:"date"`
+	OrgLogin    string    `json:"org"`
+	RepoName    string    `json:"repo"`
+	Number      int64     `json:"number"`
+	Title       string    `json:"title"`
+	PullRequest bool      `json:"pull_request"`
+	Action      string    `json:"action"`
+	Policy      string    `json:"policy"`
+	Labels      []string  `json:"labels"`
+}
+
+// ForecastCount is the number of items with a given label in a repo that are expected to be marked stale or closed.
+type ForecastCount struct {
+	OrgLogin string `json:"org"`
+	RepoName string `json:"repo"`
+	Label    string `json:"label"`
+	Stale    int    `json:"stale"`
+	Closed   int    `json:"closed"`
+}
+
+const (
+	ForecastStale  = "stale"
+	ForecastClose  = "close"
+	noLabelsMarker = "(no labels)"
+
+	// waitingOnAuthorPolicy names the lifecycle of items waiting on their author in forecasts
+	waitingOnAuthorPolicy = "waiting-on-author"
+)
+
+// Forecast simulates the daily lifecycle manager runs over the given number of days, starting today, for the
+// repos of an org, or of all orgs when orgLogin is empty.
+func (lm *LifecycleMgr) Forecast(context context.Context, orgLogin string, days int) (*Forecast, error) {
+	f := &Forecast{
+		Start: time.Now(),
+		Days:  days,
+	}
+
+	for _, repo := range lm.reg.Repos() {
+		if orgLogin != "" && repo.OrgLogin != orgLogin {
+			continue
+		}
+
+		r, ok := lm.reg.SingleRecord(RecordType, repo.OrgAndRepo)
+		if !ok {
+			continue
+		}
+		lr := r.(*lifecycleRecord)
+
+		var issues []*storage.Issue
+		if err := lm.store.QueryOpenIssuesByRepo(context, repo.OrgLogin, repo.RepoName, func(issue *storage.Issue) error {
+			issues = append(issues, issue)
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+
+		for _, issue := range issues {
+			events, err := lm.forecastIssue(context, issue, lr, f.Start, days)
+			if err != nil {
+				return nil, err
+			}
+			f.Events = append(f.Events, events...)
+		}
+	}
+
+	sort.SliceStable(f.Events, func(i, j int) bool {
+		a, b := f.Events[i], f.Events[j]
+		if !a.Date.Equal(b.Date) {
+			return a.Date.Before(b.Date)
+		} else if a.OrgLogin != b.OrgLogin {
+			return a.OrgLogin < b.OrgLogin
+		} else if a.RepoName != b.RepoName {
+			return a.RepoName < b.RepoName
+		}
+		return a.Number < b.Number
+	})
+
+	f.Breakdown = breakdown(f.Events)
+	return f, nil
+}
+
+// Truncate returns the part of a forecast that covers the given number of days, which can't exceed the forecast's.
+func (f *Forecast) Truncate(days int) *Forecast {
+	if days >= f.Days {
+		return f
+	}
+
+	end := f.Start.AddDate(0, 0, days)
+	result := &Forecast{
+		Start: f.Start,
+		Days:  days,
+	}
+
+	for _, e := range f.Events {
+		if !e.Date.After(end) {
+			result.Events = append(result.Events, e)
+		}
+	}
+
+	result.Breakdown = breakdown(result.Events)
+	return result
+}
+
+// forecastIssue replays manageIssue's staleness decisions for an issue or PR once a day
+func (lm *LifecycleMgr) forecastIssue(context context.Context, issue *storage.Issue, lr *lifecycleRecord, start time.Time,
+	days int) ([]ForecastEvent, error) {
+	if lr.WaitingOnAuthorLabel != "" && hasLabel(issue, lr.WaitingOnAuthorLabel) {
+		return lm.forecastAuthorWait(context, issue, lr, start, days)
+	}
+
+	for _, il := range lr.IgnoreLabels {
+		if hasLabel(issue, il) {
+			return nil, nil
+		}
+	}
+
+	from, err := lm.latestActivity(context, issue)
+	if err != nil {
+		return nil, err
+	}
+
+	pr, err := lm.isPullRequest(context, issue)
+	if err != nil {
+		return nil, err
+	}
+
+	pol := resolvePolicy(issue, pr, hasLabel(issue, lr.FeatureRequestLabel), lr, lm.policies[lr])
+	return simulate(issue, pr, lr, pol, lm.calendarFor(issue.OrgLogin, issue.RepoName), from, start, days), nil
+}
+
+// simulate returns the days on which an issue or PR changes from fresh to stale to closed, given when a member
+// last commented on it
+func simulate(issue *storage.Issue, pr bool, lr *lifecycleRecord, pol policy, cal *calendar, from time.Time, start time.Time,
+	days int) []ForecastEvent {
+	staleproof := hasLabel(issue, lr.CantBeStaleLabel)
+
+	prev := stepNone
+	if hasLabel(issue, lr.StaleLabel) {
+		prev = stepStale
+	}
+
+	var events []ForecastEvent
+	for day := 0; day <= days; day++ {
+		now := start.AddDate(0, 0, day)
+		if now.Sub(issue.CreatedAt) < time.Duration(lr.TriageDelay) {
+			continue
+		}
+
+		step := staleness(staleproof, pol, cal, from, now)
+		if step == prev {
+			continue
+		}
+		prev = step
+
+		action := ""
+		switch step {
+		case stepStale:
+			action = ForecastStale
+		case stepClose:
+			action = ForecastClose
+		default:
+			continue
+		}
+
+		events = append(events, ForecastEvent{
+			Date:        now,
+			OrgLogin:    issue.OrgLogin,
+			RepoName:    issue.RepoName,
+			Number:      issue.IssueNumber,
+			Title:       issue.Title,
+			PullRequest: pr,
+			Action:      action,
+			Policy:      pol.name,
+			Labels:      issue.Labels,
+		})
+
+		if step == stepClose {
+			// closed items are left alone from then on
+			break
+		}
+	}
+
+	return events
+}
+
+// forecastAuthorWait returns when an issue or PR waiting on its author gets closed if the author stays silent
+func (lm *LifecycleMgr) forecastAuthorWait(context context.Context, issue *storage.Issue, lr *lifecycleRecord, start time.Time,
+	days int) ([]ForecastEvent, error) {
+	wait, err := lm.store.ReadAuthorWait(context, issue.OrgLogin, issue.RepoName, int(issue.IssueNumber))
+	if err != nil {
+		return nil, fmt.Errorf("unable to read author wait for issue/PR %d in repo %s/%s: %v", issue.IssueNumber, issue.OrgLogin, issue.RepoName, err)
+	} else if wait == nil {
+		// the clock starts on the next run
+		wait = &storage.AuthorWait{StartedAt: start}
+	}
+
+	pr, err := lm.isPullRequest(context, issue)
+	if err != nil {
+		return nil, err
+	}
+
+	cal := lm.calendarFor(issue.OrgLogin, issue.RepoName)
+	for day := 0; day <= days; day++ {
+		now := start.AddDate(0, 0, day)
+		if authorWaitStep(wait, time.Time{}, false, lr, cal, now) == waitClose {
+			return []ForecastEvent{{
+				Date:        now,
+				OrgLogin:    issue.OrgLogin,
+				RepoName:    issue.RepoName,
+				Number:      issue.IssueNumber,
+				Title:       issue.Title,
+				PullRequest: pr,
+				Action:      ForecastClose,
+				Policy:      waitingOnAuthorPolicy,
+				Labels:      issue.Labels,
+			}}, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// breakdown counts events by repo and by label, with items carrying several labels counted under each
+func breakdown(events []ForecastEvent) []ForecastCount {
+	counts := make(map[string]*ForecastCount)
+	var result []*ForecastCount
+
+	for _, e := range events {
+		labels := e.Labels
+		if len(labels) == 0 {
+			labels = []string{noLabelsMarker}
+		}
+
+		for _, lb := range labels {
+			key := e.OrgLogin + "/" + e.RepoName + "|" + lb
+			c, ok := counts[key]
+			if !ok {
+				c = &ForecastCount{OrgLogin: e.OrgLogin, RepoName: e.RepoName, Label: lb}
+				counts[key] = c
+				result = append(result, c)
+			}
+
+			if e.Action == ForecastClose {
+				c.Closed++
+			} else {
+				c.Stale++
+			}
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.OrgLogin != b.OrgLogin {
+			return a.OrgLogin < b.OrgLogin
+		} else if a.RepoName != b.RepoName {
+			return a.RepoName < b.RepoName
+		}
+		return a.Label < b.Label
+	})
+
+	breakdown := make([]ForecastCount, len(result))
+	for i, c := range result {
+		breakdown[i] = *c
+	}
+
+	return breakdown
+}
+
+// Write outputs a forecast in one of the "table", "csv", or "json" formats.
+func (f *Forecast) Write(w io.Writer, format string) error {
+	switch format {
+	case "table":
+		return f.writeTable(w)
+	case "csv":
+		return f.writeCSV(w)
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(f)
+	}
+
+	return fmt.Errorf("unknown forecast format %s, expecting one of [table, csv, json]", format)
+}
+
+func (f *Forecast) writeTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+
+	_, _ = fmt.Fprintf(tw, "DATE\tREPO\tNUMBER\tACTION\tPOLICY\tLABELS\tTITLE\n")
+	for _, e := range f.Events {
+		_, _ = fmt.Fprintf(tw, "%s\t%s/%s\t%d\t%s\t%s\t%s\t%s\n", e.Date.Format("2006-01-02"), e.OrgLogin, e.RepoName,
+			e.Number, e.Action, e.Policy, strings.Join(e.Labels, ","), e.Title)
+	}
+
+	_, _ = fmt.Fprintf(tw, "\nREPO\tLABEL\tSTALE\tCLOSED\n")
+	for _, c := range f.Breakdown {
+		_, _ = fmt.Fprintf(tw, "%s/%s\t%s\t%d\t%d\n", c.OrgLogin, c.RepoName, c.Label, c.Stale, c.Closed)
+	}
+
+	return tw.Flush()
+}
+
+func (f *Forecast) writeCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+
+	_ = cw.Write([]string{"date", "org", "repo", "number", "action", "policy", "labels", "title"})
+	for _, e := range f.Events {
+		_ = cw.Write([]string{
+			e.Date.Format("2006-01-02"),
+			e.OrgLogin,
+			e.RepoName,
+			strconv.FormatInt(e.Number, 10),
+			e.Action,
+			e.Policy,
+			strings.Join(e.Labels, ";"),
+			e.Title,
+		})
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,214 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lifecyclemgr
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"istio.io/bots/policybot/pkg/config"
+	"istio.io/bots/policybot/pkg/storage"
+)
+
+func TestSimulate(t *testing.T) {
+	day := 24 * time.Hour
+	start := time.Date(2026, 12, 1, 12, 0, 0, 0, time.UTC)
+
+	lr := &lifecycleRecord{
+		TriageDelay:      config.Duration(3 * day),
+		StaleLabel:       "lifecycle/stale",
+		CantBeStaleLabel: "lifecycle/staleproof",
+	}
+	pol := policy{name: defaultPolicy, staleDelay: 10 * day, closeDelay: 20 * day}
+
+	cases := []struct {
+		name    string
+		issue   *storage.Issue
+		from    time.Time
+		cal     *calendar
+		actions []string
+		dates   []string
+	}{
+		{
+			name:    "goes stale then closed",
+			issue:   &storage.Issue{CreatedAt: start.Add(-5 * day)},
+			from:    start.Add(-5 * day),
+			cal:     wallClock,
+			actions: []string{ForecastStale, ForecastClose},
+			dates:   []string{"2026-12-07", "2026-12-17"},
+		},
+		{
+			name:    "already stale",
+			issue:   &storage.Issue{CreatedAt: start.Add(-15 * day), Labels: []string{"lifecycle/stale"}},
+			from:    start.Add(-15 * day),
+			cal:     wallClock,
+			actions: []string{ForecastClose},
+			dates:   []string{"2026-12-07"},
+		},
+		{
+			name:    "overdue",
+			issue:   &storage.Issue{CreatedAt: start.Add(-40 * day)},
+			from:    start.Add(-40 * day),
+			cal:     wallClock,
+			actions: []string{ForecastClose},
+			dates:   []string{"2026-12-01"},
+		},
+		{
+			name:    "staleproof",
+			issue:   &storage.Issue{CreatedAt: start.Add(-40 * day), Labels: []string{"lifecycle/staleproof"}},
+			from:    start.Add(-40 * day),
+			cal:     wallClock,
+			actions: nil,
+		},
+		{
+			name:    "beyond the horizon",
+			issue:   &storage.Issue{CreatedAt: start},
+			from:    start,
+			cal:     wallClock,
+			actions: []string{ForecastStale},
+			dates:   []string{"2026-12-12"},
+		},
+		{
+			name:    "business days",
+			issue:   &storage.Issue{CreatedAt: start.Add(-5 * day)},
+			from:    start.Add(-5 * day),
+			cal:     &calendar{loc: time.UTC, businessDays: true},
+			actions: []string{ForecastStale},
+			dates:   []string{"2026-12-11"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			events := simulate(c.issue, false, lr, pol, c.cal, c.from, start, 20)
+			if len(events) != len(c.actions) {
+				t.Fatalf("Got %d events, expected %d: %+v", len(events), len(c.actions), events)
+			}
+
+			for i, e := range events {
+				if e.Action != c.actions[i] || e.Date.Format("2006-01-02") != c.dates[i] {
+					t.Errorf("Got %s on %s, expected %s on %s", e.Action, e.Date.Format("2006-01-02"), c.actions[i], c.dates[i])
+				}
+			}
+		})
+	}
+}
+
+func TestBreakdown(t *testing.T) {
+	events := []ForecastEvent{
+		{OrgLogin: "istio", RepoName: "istio", Number: 1, Action: ForecastStale, Labels: []string{"area/networking", "kind/bug"}},
+		{OrgLogin: "istio", RepoName: "istio", Number: 1, Action: ForecastClose, Labels: []string{"area/networking", "kind/bug"}},
+		{OrgLogin: "istio", RepoName: "istio", Number: 2, Action: ForecastStale, Labels: []string{"kind/bug"}},
+		{OrgLogin: "istio", RepoName: "api", Number: 3, Action: ForecastStale},
+	}
+
+	expected := []ForecastCount{
+		{OrgLogin: "istio", RepoName: "api", Label: noLabelsMarker, Stale: 1},
+		{OrgLogin: "istio", RepoName: "istio", Label: "area/networking", Stale: 1, Closed: 1},
+		{OrgLogin: "istio", RepoName: "istio", Label: "kind/bug", Stale: 2, Closed: 1},
+	}
+
+	got := breakdown(events)
+	if len(got) != len(expected) {
+		t.Fatalf("Got %+v, expected %+v", got, expected)
+	}
+
+	for i := range got {
+		if got[i] != expected[i] {
+			t.Errorf("Got %+v, expected %+v", got[i], expected[i])
+		}
+	}
+}
+
+func TestForecastTruncate(t *testing.T) {
+	start := time.Date(2019, 10, 1, 12, 0, 0, 0, time.UTC)
+	f := &Forecast{
+		Start: start,
+		Days:  90,
+		Events: []ForecastEvent{
+			{Date: start, OrgLogin: "istio", RepoName: "istio", Number: 1, Action: ForecastStale},
+			{Date: start.AddDate(0, 0, 7), OrgLogin: "istio", RepoName: "istio", Number: 1, Action: ForecastClose},
+			{Date: start.AddDate(0, 0, 8), OrgLogin: "istio", RepoName: "istio", Number: 2, Action: ForecastStale},
+		},
+	}
+
+	got := f.Truncate(7)
+	if got.Days != 7 || len(got.Events) != 2 {
+		t.Fatalf("Got %d events over %d days, expected 2 events over 7 days", len(got.Events), got.Days)
+	}
+
+	if len(got.Breakdown) != 1 || got.Breakdown[0].Stale != 1 || got.Breakdown[0].Closed != 1 {
+		t.Errorf("Got breakdown %+v, expected a single entry with one stale and one closed item", got.Breakdown)
+	}
+
+	if f.Truncate(90) != f {
+		t.Errorf("Expected a forecast truncated to its own length to be left alone")
+	}
+}
+
+func TestForecastWrite(t *testing.T) {
+	f := &Forecast{
+		Days: 7,
+		Events: []ForecastEvent{
+			{
+				Date:     time.Date(2026, 12, 3, 0, 0, 0, 0, time.UTC),
+				OrgLogin: "istio",
+				RepoName: "istio",
+				Number:   42,
+				Title:    "Sidecar, crashes",
+				Action:   ForecastClose,
+				Policy:   "flakes",
+				Labels:   []string{"kind/flake", "area/test"},
+			},
+		},
+	}
+	f.Breakdown = breakdown(f.Events)
+
+	var b bytes.Buffer
+	if err := f.Write(&b, "table"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(b.String(), "2026-12-03  istio/istio  42") || !strings.Contains(b.String(), "istio/istio  kind/flake") {
+		t.Errorf("Unexpected table output:\n%s", b.String())
+	}
+
+	b.Reset()
+	if err := f.Write(&b, "csv"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(b.String(), `2026-12-03,istio,istio,42,close,flakes,kind/flake;area/test,"Sidecar, crashes"`) {
+		t.Errorf("Unexpected CSV output:\n%s", b.String())
+	}
+
+	b.Reset()
+	if err := f.Write(&b, "json"); err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded Forecast
+	if err := json.Unmarshal(b.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded.Events) != 1 || decoded.Events[0].Number != 42 || len(decoded.Breakdown) != 2 {
+		t.Errorf("Unexpected JSON output:\n%s", b.String())
+	}
+
+	if err := f.Write(&b, "xml"); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+}
@@ -17,12 +17,16 @@ package lifecyclemgr
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/google/go-github/v26/github"
 
 	"istio.io/bots/policybot/pkg/config"
 	"istio.io/bots/policybot/pkg/gh"
+	"istio.io/bots/policybot/pkg/pipeline"
+	"istio.io/bots/policybot/pkg/report"
+	"istio.io/bots/policybot/pkg/rules"
 	"istio.io/bots/policybot/pkg/storage"
 	"istio.io/bots/policybot/pkg/storage/cache"
 	"istio.io/istio/pkg/log"
@@ -30,10 +34,12 @@ import (
 
 // LifecycleMgr is responsible for managing the lifecycle of issues and pull requests.
 type LifecycleMgr struct {
-	gc    *gh.ThrottledClient
-	store storage.Store
-	cache *cache.Cache
-	reg   *config.Registry
+	gc        *gh.ThrottledClient
+	store     storage.Store
+	cache     *cache.Cache
+	reg       *config.Registry
+	policies  map[*lifecycleRecord][]*rules.Expr
+	calendars map[*calendarRecord]*calendar
 }
 
 type stats struct {
@@ -41,52 +47,133 @@ type stats struct {
 	closed                int
 	markedNeedsTriage     int
 	markedNeedsEscalation int
+	nudgedAuthors         int
+	authorResponded       int
+}
+
+func (st *stats) add(other *stats) {
+	st.markedStale += other.markedStale
+	st.closed += other.closed
+	st.markedNeedsTriage += other.markedNeedsTriage
+	st.markedNeedsEscalation += other.markedNeedsEscalation
+	st.nudgedAuthors += other.nudgedAuthors
+	st.authorResponded += other.authorResponded
 }
 
 var scope = log.RegisterScope("lifecyclemgr", "The issue and pull request lifecycle manager")
 
 const botSignature = "\n\n_Created by the issue and PR lifecycle manager_."
 
-func New(gc *gh.ThrottledClient, store storage.Store, cache *cache.Cache, reg *config.Registry) *LifecycleMgr {
-	return &LifecycleMgr{
-		gc:    gc,
-		store: store,
-		cache: cache,
-		reg:   reg,
+func New(gc *gh.ThrottledClient, store storage.Store, cache *cache.Cache, reg *config.Registry) (*LifecycleMgr, error) {
+	lm := &LifecycleMgr{
+		gc:        gc,
+		store:     store,
+		cache:     cache,
+		reg:       reg,
+		policies:  make(map[*lifecycleRecord][]*rules.Expr),
+		calendars: make(map[*calendarRecord]*calendar),
+	}
+
+	for _, r := range reg.Records(RecordType, "*") {
+		lr := r.(*lifecycleRecord)
+		exprs, err := compilePolicies(lr)
+		if err != nil {
+			return nil, err
+		}
+		lm.policies[lr] = exprs
 	}
+
+	for _, r := range reg.Records(calendarRecordType, "*") {
+		cr := r.(*calendarRecord)
+		cal, err := compileCalendar(cr)
+		if err != nil {
+			return nil, err
+		}
+		lm.calendars[cr] = cal
+	}
+
+	return lm, nil
+}
+
+// calendarFor returns the calendar against which lifecycle delays are measured in a repo
+func (lm *LifecycleMgr) calendarFor(orgLogin string, repoName string) *calendar {
+	r, ok := lm.reg.SingleRecord(calendarRecordType, orgLogin+"/"+repoName)
+	if !ok {
+		return wallClock
+	}
+
+	return lm.calendars[r.(*calendarRecord)]
 }
 
-func (lm *LifecycleMgr) ManageAll(context context.Context, dryRun bool) error {
+// ManageAll runs the lifecycle over every open issue and PR of the configured repos.
+func (lm *LifecycleMgr) ManageAll(context context.Context, dryRun bool) (*report.Report, error) {
+	core := lm.reg.Core()
+	rep := report.New("lifecyclemgr")
+
+	var repos []interface{}
 	for _, repo := range lm.reg.Repos() {
+		repos = append(repos, repo)
+	}
+
+	err := pipeline.ForEach(context, repos, core.RepoParallelism, func(item interface{}) error {
+		repo := item.(gh.RepoDesc)
 		r, ok := lm.reg.SingleRecord(RecordType, repo.OrgAndRepo)
 		if !ok {
-			continue
+			return nil
 		}
 
-		lr := r.(*lifecycleRecord)
+		return lm.manageRepo(context, repo, r.(*lifecycleRecord), core.ItemParallelism, dryRun, rep.Repo(repo.OrgAndRepo))
+	})
 
-		var st stats
+	rep.Finish()
+	return rep, err
+}
 
-		var issues []*storage.Issue
-		if err := lm.store.QueryOpenIssuesByRepo(context, repo.OrgLogin, repo.OrgLogin, func(issue *storage.Issue) error {
-			issues = append(issues, issue)
-			return nil
-		}); err != nil {
-			return err
-		}
+func (lm *LifecycleMgr) manageRepo(context context.Context, repo gh.RepoDesc, lr *lifecycleRecord, parallelism int,
+	dryRun bool, rr *report.RepoReport) error {
+	var issues []interface{}
+	if err := lm.store.QueryOpenIssuesByRepo(context, repo.OrgLogin, repo.RepoName, func(issue *storage.Issue) error {
+		issues = append(issues, issue)
+		return nil
+	}); err != nil {
+		err = fmt.Errorf("unable to read open issues for repo %s: %v", repo, err)
+		rr.Fail(err)
+		return err
+	}
 
-		for _, issue := range issues {
-			err := lm.manageIssue(context, issue, &st, lr, dryRun)
-			if err != nil {
-				scope.Errorf("%v", err)
-			}
+	rr.Add("issues", len(issues))
+
+	var st stats
+	var stLock sync.Mutex
+	err := pipeline.ForEach(context, issues, parallelism, func(item interface{}) error {
+		issue := item.(*storage.Issue)
+
+		var ist stats
+		err := lm.manageIssue(context, issue, &ist, lr, dryRun)
+
+		stLock.Lock()
+		st.add(&ist)
+		stLock.Unlock()
+
+		if err != nil {
+			err = fmt.Errorf("unable to manage issue/PR %d in repo %s: %v", issue.IssueNumber, repo, err)
+			scope.Errorf("%v", err)
+			rr.Fail(err)
 		}
+		return err
+	})
 
-		scope.Infof("STATS: repo %s, markedStale %d, closed %d, markedNeedsTriage %d, markedNeedsEscalation %d\n", repo,
-			st.markedStale, st.closed, st.markedNeedsEscalation, st.markedNeedsTriage)
-	}
+	scope.Infof("STATS: repo %s, markedStale %d, closed %d, markedNeedsTriage %d, markedNeedsEscalation %d, nudgedAuthors %d, authorResponded %d\n",
+		repo, st.markedStale, st.closed, st.markedNeedsTriage, st.markedNeedsEscalation, st.nudgedAuthors, st.authorResponded)
 
-	return nil
+	rr.Add("markedStale", st.markedStale)
+	rr.Add("closed", st.closed)
+	rr.Add("markedNeedsTriage", st.markedNeedsTriage)
+	rr.Add("markedNeedsEscalation", st.markedNeedsEscalation)
+	rr.Add("nudgedAuthors", st.nudgedAuthors)
+	rr.Add("authorResponded", st.authorResponded)
+
+	return err
 }
 
 func (lm *LifecycleMgr) ManageIssue(context context.Context, issue *storage.Issue) error {
@@ -99,9 +186,48 @@ func (lm *LifecycleMgr) ManageIssue(context context.Context, issue *storage.Issu
 	return lm.manageIssue(context, issue, &stats{}, lr, false)
 }
 
+// MakeStaleproof marks an issue or PR such that it never becomes stale.
+func (lm *LifecycleMgr) MakeStaleproof(context context.Context, issue *storage.Issue) error {
+	r, ok := lm.reg.SingleRecord(RecordType, issue.OrgLogin+"/"+issue.RepoName)
+	if !ok {
+		return fmt.Errorf("no lifecycle configuration for repo %s/%s", issue.OrgLogin, issue.RepoName)
+	}
+
+	lr := r.(*lifecycleRecord)
+	if lr.CantBeStaleLabel == "" {
+		return fmt.Errorf("no staleproof label configured for repo %s/%s", issue.OrgLogin, issue.RepoName)
+	}
+
+	if !hasLabel(issue, lr.CantBeStaleLabel) {
+		if err := lm.addLabel(context, issue, lr.CantBeStaleLabel, false); err != nil {
+			return err
+		}
+
+		lm.audit(context, issue, &storage.LifecycleEvent{
+			Transition: transitionStaleproof,
+			Reason:     "as a member asked for it never to go stale",
+			ClockStart: time.Now(),
+		}, false)
+	}
+
+	if hasLabel(issue, lr.StaleLabel) {
+		if err := lm.removeLabel(context, issue, lr.StaleLabel, false); err != nil {
+			return err
+		}
+	}
+
+	// remove any staleness comment
+	return lm.removeComment(context, issue, false)
+}
+
 func (lm *LifecycleMgr) manageIssue(context context.Context, issue *storage.Issue, st *stats, lr *lifecycleRecord, dryRun bool) error {
 	now := time.Now()
 
+	// items waiting on their author follow the author's clock rather than that of members
+	if waiting, err := lm.manageAuthorWait(context, issue, st, lr, dryRun); err != nil || waiting {
+		return err
+	}
+
 	if now.Sub(issue.CreatedAt) < time.Duration(lr.TriageDelay) {
 		// stay quiet if the item is less than the triage delay
 		scope.Infof("Issue/PR %d in repo %s/%s is too new, ignoring", issue.IssueNumber, issue.OrgLogin, issue.RepoName)
@@ -153,57 +279,32 @@ func (lm *LifecycleMgr) manageIssue(context context.Context, issue *storage.Issu
 		return nil
 	}
 
-	latestMemberComment, err := lm.store.GetLatestIssueMemberComment(context, issue.OrgLogin, issue.RepoName, int(issue.IssueNumber))
+	from, err := lm.latestActivity(context, issue)
 	if err != nil {
-		return fmt.Errorf("could not get member comment for issue/PR %d in repo %s/%s: %v", issue.IssueNumber, issue.OrgLogin, issue.RepoName, err)
+		return err
 	}
 
-	latestMemberCommentDelta := now.Sub(latestMemberComment)
-	if (latestMemberComment == time.Time{}) {
-		latestMemberCommentDelta = now.Sub(issue.CreatedAt)
+	cal := lm.calendarFor(issue.OrgLogin, issue.RepoName)
+	if name, ok := cal.frozen(now); ok {
+		scope.Debugf("Lifecycle clocks in repo %s/%s are stopped for the %s freeze", issue.OrgLogin, issue.RepoName, name)
 	}
 
-	pull, err := lm.cache.ReadPullRequest(context, issue.OrgLogin, issue.RepoName, int(issue.IssueNumber))
+	pr, err := lm.isPullRequest(context, issue)
 	if err != nil {
-		return fmt.Errorf("could not get pr info for issue/PR %d in repo %s/%s: %v", issue.IssueNumber, issue.OrgLogin, issue.RepoName, err)
+		return err
 	}
-	pr := pull != nil
 
-	if hasStaleproofLabel {
-		// clean up any leftover stale label and staleness comment
-		if hasStaleLabel {
-			if err := lm.removeLabel(context, issue, lr.StaleLabel, dryRun); err != nil {
-				return err
-			}
-		}
-
-		// remove staleness comment
-		if err := lm.removeComment(context, issue, dryRun); err != nil {
+	if !pr {
+		if err := lm.manageTriage(context, issue, st, lr, hasTriageLabel, hasEscalationLabel, dryRun); err != nil {
 			return err
 		}
-
-		return nil
 	}
 
-	var staleDelay time.Duration
-	var closeDelay time.Duration
-	if pr {
-		staleDelay = time.Duration(lr.PullRequestStaleDelay)
-		closeDelay = time.Duration(lr.PullRequestCloseDelay)
-	} else if hasEnhancementLabel {
-		staleDelay = time.Duration(lr.FeatureRequestStaleDelay)
-		closeDelay = time.Duration(lr.FeatureRequestCloseDelay)
-	} else {
-		staleDelay = time.Duration(lr.IssueStaleDelay)
-		closeDelay = time.Duration(lr.IssueCloseDelay)
-	}
-
-	from := latestMemberComment
-	if from == (time.Time{}) {
-		from = issue.CreatedAt
-	}
+	pol := resolvePolicy(issue, pr, hasEnhancementLabel, lr, lm.policies[lr])
+	scope.Debugf("Issue/PR %d in repo %s/%s follows the %s lifecycle policy", issue.IssueNumber, issue.OrgLogin, issue.RepoName, pol.name)
 
-	if latestMemberCommentDelta > closeDelay {
+	switch step := staleness(hasStaleproofLabel, pol, cal, from, now); step {
+	case stepClose:
 		st.closed++
 
 		// close the issue
@@ -212,8 +313,8 @@ func (lm *LifecycleMgr) manageIssue(context context.Context, issue *storage.Issu
 		}
 
 		// add closing comment
-		commentDate := from.Format("2006-01-02")
-		if err := lm.addComment(context, issue, fmt.Sprintf(lr.CloseComment, commentDate), "closing", dryRun); err != nil {
+		commentDate := from.In(cal.loc).Format("2006-01-02")
+		if err := lm.addComment(context, issue, fmt.Sprintf(pol.closeComment, commentDate), "closing", dryRun); err != nil {
 			return err
 		}
 
@@ -223,14 +324,22 @@ func (lm *LifecycleMgr) manageIssue(context context.Context, issue *storage.Issu
 				return err
 			}
 		}
-	} else if latestMemberCommentDelta > staleDelay {
-		commentDate := from.Format("2006-01-02")
-		closeDate := from.Add(closeDelay).Format("2006-01-02")
+
+		lm.audit(context, issue, &storage.LifecycleEvent{
+			Transition: transitionClosed,
+			Policy:     pol.name,
+			Reason:     fmt.Sprintf("since no member had commented on it since %s", commentDate),
+			ClockStart: from,
+		}, dryRun)
+
+	case stepStale:
+		commentDate := from.In(cal.loc).Format("2006-01-02")
+		closeDate := cal.deadline(from, pol.closeDelay).In(cal.loc).Format("2006-01-02")
 
 		st.markedStale++
 
 		// add staleness comment
-		if err := lm.addComment(context, issue, fmt.Sprintf(lr.StaleComment, commentDate, closeDate), "staleness", dryRun); err != nil {
+		if err := lm.addComment(context, issue, fmt.Sprintf(pol.staleComment, commentDate, closeDate), "staleness", dryRun); err != nil {
 			return err
 		}
 
@@ -238,12 +347,36 @@ func (lm *LifecycleMgr) manageIssue(context context.Context, issue *storage.Issu
 		if err := lm.addLabel(context, issue, lr.StaleLabel, dryRun); err != nil {
 			return err
 		}
-	} else {
-		// remove stale label
+
+		if !hasStaleLabel {
+			closeAt := cal.deadline(from, pol.closeDelay)
+			lm.audit(context, issue, &storage.LifecycleEvent{
+				Transition: transitionStale,
+				Policy:     pol.name,
+				Reason:     fmt.Sprintf("since no member had commented on it since %s", commentDate),
+				ClockStart: from,
+				Deadline:   &closeAt,
+			}, dryRun)
+		}
+
+	default:
+		// remove any leftover stale label
 		if hasStaleLabel {
 			if err := lm.removeLabel(context, issue, lr.StaleLabel, dryRun); err != nil {
 				return err
 			}
+
+			reason := fmt.Sprintf("since a member commented on it on %s", from.In(cal.loc).Format("2006-01-02"))
+			if step == stepExempt {
+				reason = "since it's exempt from going stale"
+			}
+
+			lm.audit(context, issue, &storage.LifecycleEvent{
+				Transition: transitionUnstale,
+				Policy:     pol.name,
+				Reason:     reason,
+				ClockStart: from,
+			}, dryRun)
 		}
 
 		// remove staleness comment
@@ -255,6 +388,56 @@ func (lm *LifecycleMgr) manageIssue(context context.Context, issue *storage.Issu
 	return nil
 }
 
+// lifecycleStep is the action manageIssue takes with regards to the staleness of an open issue or PR
+type lifecycleStep int
+
+const (
+	stepNone lifecycleStep = iota
+	stepExempt
+	stepStale
+	stepClose
+)
+
+// staleness decides whether an issue or PR is due to be marked stale or closed at the given time, based on
+// when a member last commented on it. Only the time that counts on the repo's calendar brings it closer to either.
+func staleness(staleproof bool, pol policy, cal *calendar, from time.Time, now time.Time) lifecycleStep {
+	if staleproof || pol.exempt {
+		return stepExempt
+	}
+
+	elapsed := cal.elapsed(from, now)
+	if elapsed > pol.closeDelay {
+		return stepClose
+	} else if elapsed > pol.staleDelay {
+		return stepStale
+	}
+
+	return stepNone
+}
+
+// latestActivity returns when a member last commented on an issue or PR, or when it was created absent such comments
+func (lm *LifecycleMgr) latestActivity(context context.Context, issue *storage.Issue) (time.Time, error) {
+	latestMemberComment, err := lm.store.GetLatestIssueMemberComment(context, issue.OrgLogin, issue.RepoName, int(issue.IssueNumber))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("could not get member comment for issue/PR %d in repo %s/%s: %v", issue.IssueNumber, issue.OrgLogin, issue.RepoName, err)
+	}
+
+	if latestMemberComment == (time.Time{}) {
+		return issue.CreatedAt, nil
+	}
+
+	return latestMemberComment, nil
+}
+
+func (lm *LifecycleMgr) isPullRequest(context context.Context, issue *storage.Issue) (bool, error) {
+	pull, err := lm.cache.ReadPullRequest(context, issue.OrgLogin, issue.RepoName, int(issue.IssueNumber))
+	if err != nil {
+		return false, fmt.Errorf("could not get pr info for issue/PR %d in repo %s/%s: %v", issue.IssueNumber, issue.OrgLogin, issue.RepoName, err)
+	}
+
+	return pull != nil, nil
+}
+
 func (lm *LifecycleMgr) closeIssue(context context.Context, issue *storage.Issue, dryRun bool) error {
 	if dryRun {
 		scope.Infof("Would have closed issue/PR %d in repo %s/%s", issue.IssueNumber, issue.OrgLogin, issue.RepoName)
@@ -349,3 +532,13 @@ func (lm *LifecycleMgr) removeComment(context context.Context, issue *storage.Is
 	scope.Infof("Removed comment from issue/PR %d in repo %s/%s", issue.IssueNumber, issue.OrgLogin, issue.RepoName)
 	return nil
 }
+
+func hasLabel(issue *storage.Issue, label string) bool {
+	for _, lb := range issue.Labels {
+		if lb == label {
+			return true
+		}
+	}
+
+	return false
+}
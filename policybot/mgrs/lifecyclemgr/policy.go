@@ -0,0 +1,114 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lifecyclemgr
+
+import (
+	"fmt"
+	"time"
+
+	"istio.io/bots/policybot/pkg/rules"
+	"istio.io/bots/policybot/pkg/storage"
+)
+
+// policy holds the lifecycle settings that apply to a particular issue or PR
+type policy struct {
+	name         string
+	staleDelay   time.Duration
+	closeDelay   time.Duration
+	staleComment string
+	closeComment string
+	exempt       bool
+}
+
+const defaultPolicy = "default"
+
+// compilePolicies prepares the rules of a lifecycle record's policies, in order
+func compilePolicies(lr *lifecycleRecord) ([]*rules.Expr, error) {
+	exprs := make([]*rules.Expr, len(lr.Policies))
+	for i := range lr.Policies {
+		p := &lr.Policies[i]
+		if p.Match == nil {
+			return nil, fmt.Errorf("lifecycle policy %s in record %s doesn't have a match rule", p.Name, lr.Name)
+		}
+
+		expr, err := rules.Compile(p.Match)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rule for lifecycle policy %s in record %s: %v", p.Name, lr.Name, err)
+		}
+		exprs[i] = expr
+	}
+
+	return exprs, nil
+}
+
+// resolvePolicy returns the settings for an issue or PR, from the first policy that matches it or otherwise from
+// the lifecycle record itself
+func resolvePolicy(issue *storage.Issue, pr bool, hasEnhancementLabel bool, lr *lifecycleRecord, exprs []*rules.Expr) policy {
+	result := policy{
+		name:         defaultPolicy,
+		staleComment: lr.StaleComment,
+		closeComment: lr.CloseComment,
+	}
+
+	if pr {
+		result.staleDelay = time.Duration(lr.PullRequestStaleDelay)
+		result.closeDelay = time.Duration(lr.PullRequestCloseDelay)
+	} else if hasEnhancementLabel {
+		result.staleDelay = time.Duration(lr.FeatureRequestStaleDelay)
+		result.closeDelay = time.Duration(lr.FeatureRequestCloseDelay)
+	} else {
+		result.staleDelay = time.Duration(lr.IssueStaleDelay)
+		result.closeDelay = time.Duration(lr.IssueCloseDelay)
+	}
+
+	subject := rules.IssueSubject(issue)
+	subject.PullRequest = pr
+
+	for i, expr := range exprs {
+		if !expr.Eval(subject) {
+			continue
+		}
+
+		p := &lr.Policies[i]
+		result.name = p.Name
+
+		if p.StaleDelay != 0 {
+			result.staleDelay = time.Duration(p.StaleDelay)
+		}
+
+		if p.CloseDelay != 0 {
+			result.closeDelay = time.Duration(p.CloseDelay)
+		}
+
+		if p.StaleComment != "" {
+			result.staleComment = p.StaleComment
+		}
+
+		if p.CloseComment != "" {
+			result.closeComment = p.CloseComment
+		}
+
+		for _, lb := range p.ExemptLabels {
+			if hasLabel(issue, lb) {
+				result.exempt = true
+				break
+			}
+		}
+
+		break
+	}
+
+	return result
+}
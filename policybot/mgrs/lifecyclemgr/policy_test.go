@@ -0,0 +1,74 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lifecyclemgr
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"sigs.k8s.io/yaml"
+
+	"istio.io/bots/policybot/pkg/storage"
+)
+
+func TestResolvePolicy(t *testing.T) {
+	b, err := os.ReadFile("../../config/lifecycles/main.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lr := &lifecycleRecord{}
+	if err := yaml.Unmarshal(b, lr); err != nil {
+		t.Fatal(err)
+	}
+
+	exprs, err := compilePolicies(lr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name        string
+		labels      []string
+		pr          bool
+		enhancement bool
+		policy      string
+		staleDelay  time.Duration
+		exempt      bool
+	}{
+		{"plain issue", nil, false, false, defaultPolicy, time.Duration(lr.IssueStaleDelay), false},
+		{"plain PR", nil, true, false, defaultPolicy, time.Duration(lr.PullRequestStaleDelay), false},
+		{"feature request", []string{"kind/enhancement"}, false, true, defaultPolicy, time.Duration(lr.FeatureRequestStaleDelay), false},
+		{"P0 bug", []string{"priority/P0"}, false, false, "p0-bugs", 336 * time.Hour, false},
+		{"P0 PR", []string{"priority/P0"}, true, false, defaultPolicy, time.Duration(lr.PullRequestStaleDelay), false},
+		{"first match wins", []string{"kind/flake", "area/docs"}, false, false, "flakes", 720 * time.Hour, false},
+		{"exempt docs", []string{"area/docs", "help wanted"}, false, false, "docs", 4320 * time.Hour, true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			p := resolvePolicy(&storage.Issue{Labels: c.labels}, c.pr, c.enhancement, lr, exprs)
+			if p.name != c.policy || p.staleDelay != c.staleDelay || p.exempt != c.exempt {
+				t.Errorf("Got policy %s with stale delay %v and exempt %v, expected %s, %v, %v",
+					p.name, p.staleDelay, p.exempt, c.policy, c.staleDelay, c.exempt)
+			}
+
+			if p.staleComment == "" || p.closeComment == "" {
+				t.Errorf("Expected comments to be inherited, got %+v", p)
+			}
+		})
+	}
+}
@@ -18,9 +18,13 @@ import (
 	"time"
 
 	"istio.io/bots/policybot/pkg/config"
+	"istio.io/bots/policybot/pkg/rules"
 )
 
-const RecordType = "lifecycle"
+const (
+	RecordType         = "lifecycle"
+	calendarRecordType = "calendar"
+)
 
 type lifecycleRecord struct {
 	config.RecordBase
@@ -32,8 +36,18 @@ type lifecycleRecord struct {
 	TriageDelay config.Duration `json:"triage_delay"`
 	TriageLabel string          `json:"triage_label"`
 
-	EscalationDelay config.Duration `json:"escalation_delay"`
-	EscalationLabel string          `json:"escalation_label"`
+	// TriagedLabels are the labels that show an issue has been triaged, when applied by a member of the org rather
+	// than by the bot. Entries ending in a slash match any label with that prefix.
+	TriagedLabels []string `json:"triaged_labels"`
+
+	// EscalationDelay is how long an issue may carry the triage label before it is escalated
+	EscalationDelay   config.Duration `json:"escalation_delay"`
+	EscalationLabel   string          `json:"escalation_label"`
+	EscalationComment string          `json:"escalation_comment"`
+
+	// AreaPaths maps area labels to paths in the repo, which determine the owners to notify when escalating
+	// based on the repo's CODEOWNERS file.
+	AreaPaths map[string][]string `json:"area_paths"`
 
 	PullRequestStaleDelay    config.Duration `json:"pull_request_stale_delay"`
 	FeatureRequestStaleDelay config.Duration `json:"feature_request_stale_delay"`
@@ -47,14 +61,63 @@ type lifecycleRecord struct {
 	IssueCloseDelay          config.Duration `json:"issue_close_delay"`
 	CloseLabel               string          `json:"close_label"`
 	CloseComment             string          `json:"close_comment"`
+
+	// WaitingOnAuthorLabel marks issues and PRs that can't progress until their author responds. While it's
+	// present, the author is nudged and the item eventually closed, instead of the usual staleness handling.
+	WaitingOnAuthorLabel string          `json:"waiting_on_author_label"`
+	AuthorNudgeDelay     config.Duration `json:"author_nudge_delay"`
+	AuthorNudgeComment   string          `json:"author_nudge_comment"`
+	AuthorCloseDelay     config.Duration `json:"author_close_delay"`
+	AuthorCloseComment   string          `json:"author_close_comment"`
+
+	// Policies override the delays and comments above for the issues and PRs they match. They are tried in
+	// order and the first match wins, while items matching no policy follow the settings above.
+	Policies []lifecyclePolicy `json:"policies"`
+}
+
+// lifecyclePolicy sets the lifecycle of the issues and PRs matching a rule. Delays and comments that are left
+// empty are inherited from the lifecycle record.
+type lifecyclePolicy struct {
+	Name         string          `json:"name"`
+	Match        *rules.Rule     `json:"match"`
+	StaleDelay   config.Duration `json:"stale_delay"`
+	CloseDelay   config.Duration `json:"close_delay"`
+	StaleComment string          `json:"stale_comment"`
+	CloseComment string          `json:"close_comment"`
+
+	// ExemptLabels keep matching items from going stale while they carry any of these labels
+	ExemptLabels []string `json:"exempt_labels"`
+}
+
+// calendarRecord determines which time counts towards the lifecycle delays of the repos it applies to
+type calendarRecord struct {
+	config.RecordBase
+
+	// Timezone is the IANA name of the zone in which days start and end, UTC by default
+	Timezone string `json:"timezone"`
+
+	// BusinessDays leaves Saturdays and Sundays out of the count
+	BusinessDays bool `json:"business_days"`
+
+	// Freezes are periods during which lifecycle clocks stop, such as holidays or conferences
+	Freezes []freezeWindow `json:"freezes"`
+}
+
+// freezeWindow is a range of days, in YYYY-MM-DD form, with both ends included
+type freezeWindow struct {
+	Name  string `json:"name"`
+	Start string `json:"start"`
+	End   string `json:"end"`
 }
 
 func init() {
 	config.RegisterType(RecordType, config.OnePerRepo, func() config.Record {
 		return &lifecycleRecord{
 			TriageLabel:              "lifecycle/needs triage",
+			TriagedLabels:            []string{"area/", "priority/"},
 			EscalationDelay:          config.Duration(7 * 24 * time.Hour),
 			EscalationLabel:          "lifecycle/needs escalation",
+			EscalationComment:        "",
 			FeatureRequestLabel:      "enhancement",
 			PullRequestStaleDelay:    config.Duration(30 * 24 * time.Hour),
 			FeatureRequestStaleDelay: config.Duration(30 * 24 * time.Hour),
@@ -67,6 +130,15 @@ func init() {
 			IssueCloseDelay:          config.Duration(60 * 24 * time.Hour),
 			CloseLabel:               "",
 			CloseComment:             "",
+			WaitingOnAuthorLabel:     "lifecycle/waiting-on-author",
+			AuthorNudgeDelay:         config.Duration(7 * 24 * time.Hour),
+			AuthorCloseDelay:         config.Duration(21 * 24 * time.Hour),
+		}
+	})
+
+	config.RegisterType(calendarRecordType, config.OnePerRepo, func() config.Record {
+		return &calendarRecord{
+			Timezone: "UTC",
 		}
 	})
 }
@@ -0,0 +1,340 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lifecyclemgr
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v26/github"
+
+	"istio.io/bots/policybot/pkg/storage"
+)
+
+const escalationSignature = "\n\n_Escalated by the issue and PR lifecycle manager_."
+
+// manageTriage keeps the triage and escalation labels of an open issue in sync with whether someone has triaged it
+func (lm *LifecycleMgr) manageTriage(context context.Context, issue *storage.Issue, st *stats, lr *lifecycleRecord,
+	hasTriageLabel bool, hasEscalationLabel bool, dryRun bool) error {
+	// finding out who applied labels takes a call to GitHub, so only do it when the answer matters
+	needLabelings := hasTriageLabel
+	for _, lb := range issue.Labels {
+		needLabelings = needLabelings || isTriagedLabel(lb, lr.TriagedLabels)
+	}
+
+	labelings := make(map[string]labeling)
+	if needLabelings {
+		var err error
+		if labelings, err = lm.labelings(context, issue); err != nil {
+			return err
+		}
+	}
+
+	triaged, err := lm.isTriaged(context, issue, lr, labelings)
+	if err != nil {
+		return err
+	}
+
+	// the escalation clock starts when the issue was marked as needing triage
+	clockStart := issue.CreatedAt
+	if l, ok := labelings[strings.ToLower(lr.TriageLabel)]; ok {
+		clockStart = l.at
+	}
+
+	if triaged {
+		if hasTriageLabel {
+			if err := lm.removeLabel(context, issue, lr.TriageLabel, dryRun); err != nil {
+				return err
+			}
+
+			lm.audit(context, issue, &storage.LifecycleEvent{
+				Transition: transitionTriaged,
+				Reason:     "since a member applied a triage label",
+				ClockStart: clockStart,
+			}, dryRun)
+		}
+
+		if hasEscalationLabel {
+			if err := lm.removeLabel(context, issue, lr.EscalationLabel, dryRun); err != nil {
+				return err
+			}
+
+			if err := lm.removeEscalationComment(context, issue, dryRun); err != nil {
+				return err
+			}
+
+			lm.audit(context, issue, &storage.LifecycleEvent{
+				Transition: transitionDeescalated,
+				Reason:     "since a member applied a triage label",
+				ClockStart: clockStart,
+			}, dryRun)
+		}
+
+		return nil
+	}
+
+	if lr.TriageLabel == "" {
+		return nil
+	}
+
+	if !hasTriageLabel {
+		st.markedNeedsTriage++
+		if err := lm.addLabel(context, issue, lr.TriageLabel, dryRun); err != nil {
+			return err
+		}
+
+		clockStart = time.Now()
+		escalateAt := clockStart.Add(time.Duration(lr.EscalationDelay))
+		lm.audit(context, issue, &storage.LifecycleEvent{
+			Transition: transitionNeedsTriage,
+			Reason:     "since no member has applied a triage label yet",
+			ClockStart: clockStart,
+			Deadline:   &escalateAt,
+		}, dryRun)
+	}
+
+	if hasEscalationLabel || lr.EscalationLabel == "" || time.Since(clockStart) <= time.Duration(lr.EscalationDelay) {
+		return nil
+	}
+
+	st.markedNeedsEscalation++
+	if err := lm.addLabel(context, issue, lr.EscalationLabel, dryRun); err != nil {
+		return err
+	}
+
+	lm.audit(context, issue, &storage.LifecycleEvent{
+		Transition: transitionEscalated,
+		Reason:     fmt.Sprintf("since it has been waiting to be triaged since %s", clockStart.Format("2006-01-02")),
+		ClockStart: clockStart,
+	}, dryRun)
+
+	if lr.EscalationComment == "" {
+		return nil
+	}
+
+	owners, err := lm.findOwners(context, issue, lr)
+	if err != nil {
+		return err
+	}
+
+	comment := fmt.Sprintf(lr.EscalationComment, clockStart.Format("2006-01-02"))
+	if len(owners) > 0 {
+		comment += "\n\ncc @" + strings.Join(owners, " @")
+	}
+
+	return lm.addEscalationComment(context, issue, comment, dryRun)
+}
+
+// labeling records who last applied a label to an issue, and when
+type labeling struct {
+	actor string
+	at    time.Time
+}
+
+// labelings returns who last applied each label of an issue, keyed by lowercase label name
+func (lm *LifecycleMgr) labelings(context context.Context, issue *storage.Issue) (map[string]labeling, error) {
+	var events []*github.IssueEvent
+	opt := &github.ListOptions{PerPage: 100}
+	for {
+		page, resp, err := lm.gc.ThrottledCall(func(client *github.Client) (interface{}, *github.Response, error) {
+			return client.Issues.ListIssueEvents(context, issue.OrgLogin, issue.RepoName, int(issue.IssueNumber), opt)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("unable to list the events of issue %d in repo %s/%s: %v", issue.IssueNumber, issue.OrgLogin, issue.RepoName, err)
+		}
+
+		events = append(events, page.([]*github.IssueEvent)...)
+
+		if resp.NextPage == 0 {
+			return latestLabelings(events), nil
+		}
+		opt.Page = resp.NextPage
+	}
+}
+
+// latestLabelings picks the latest time each label was applied out of an issue's events
+func latestLabelings(events []*github.IssueEvent) map[string]labeling {
+	result := make(map[string]labeling)
+	for _, e := range events {
+		if e.GetEvent() != "labeled" {
+			continue
+		}
+
+		label := strings.ToLower(e.GetLabel().GetName())
+		if l, ok := result[label]; ok && l.at.After(e.GetCreatedAt()) {
+			continue
+		}
+
+		result[label] = labeling{actor: e.GetActor().GetLogin(), at: e.GetCreatedAt()}
+	}
+
+	return result
+}
+
+// isTriaged returns whether a member of the org applied one of the labels that show an issue was triaged
+func (lm *LifecycleMgr) isTriaged(context context.Context, issue *storage.Issue, lr *lifecycleRecord, labelings map[string]labeling) (bool, error) {
+	var candidates []string
+	for _, lb := range issue.Labels {
+		if isTriagedLabel(lb, lr.TriagedLabels) {
+			candidates = append(candidates, lb)
+		}
+	}
+
+	if len(candidates) == 0 {
+		return false, nil
+	}
+
+	// labels applied by the bot, such as area labels guessed from the title, don't count
+	botLabels := make(map[string]bool)
+	if err := lm.store.QueryBotLabelsByIssue(context, issue.OrgLogin, issue.RepoName, int(issue.IssueNumber), func(bl *storage.BotLabel) error {
+		botLabels[bl.LabelName] = bl.ReappliedBy == nil
+		return nil
+	}); err != nil {
+		return false, fmt.Errorf("unable to read the bot labels of issue %d in repo %s/%s: %v", issue.IssueNumber, issue.OrgLogin, issue.RepoName, err)
+	}
+
+	for _, lb := range candidates {
+		if botLabels[lb] {
+			continue
+		}
+
+		l, ok := labelings[strings.ToLower(lb)]
+		if !ok {
+			continue
+		}
+
+		member, err := lm.cache.ReadMember(context, issue.OrgLogin, l.actor)
+		if err != nil {
+			return false, fmt.Errorf("unable to read member %s of org %s: %v", l.actor, issue.OrgLogin, err)
+		}
+
+		if member != nil {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func isTriagedLabel(label string, triagedLabels []string) bool {
+	for _, tl := range triagedLabels {
+		if strings.HasSuffix(tl, "/") {
+			if strings.HasPrefix(strings.ToLower(label), strings.ToLower(tl)) {
+				return true
+			}
+		} else if strings.EqualFold(label, tl) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// findOwners returns the maintainers responsible for the areas of an issue, according to the repo's CODEOWNERS
+func (lm *LifecycleMgr) findOwners(context context.Context, issue *storage.Issue, lr *lifecycleRecord) ([]string, error) {
+	var paths []string
+	for _, lb := range issue.Labels {
+		paths = append(paths, lr.AreaPaths[lb]...)
+	}
+
+	if len(paths) == 0 {
+		return nil, nil
+	}
+
+	var maintainers []*storage.Maintainer
+	if err := lm.store.QueryMaintainersByOrg(context, issue.OrgLogin, func(m *storage.Maintainer) error {
+		maintainers = append(maintainers, m)
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("unable to read the maintainers of org %s: %v", issue.OrgLogin, err)
+	}
+
+	return ownersOf(maintainers, issue.RepoName, paths), nil
+}
+
+// ownersOf returns the maintainers with the most specific CODEOWNERS entries covering each of the paths
+func ownersOf(maintainers []*storage.Maintainer, repoName string, paths []string) []string {
+	owners := make(map[string]bool)
+	for _, path := range paths {
+		path = strings.Trim(path, "/")
+
+		best := -1
+		var bestOwners []string
+		for _, m := range maintainers {
+			if m.Emeritus {
+				continue
+			}
+
+			for _, mp := range m.Paths {
+				if !strings.HasPrefix(mp, repoName+"/") {
+					continue
+				}
+
+				owned := strings.Trim(strings.TrimPrefix(mp, repoName+"/"), "/")
+				if owned != "" && path != owned && !strings.HasPrefix(path, owned+"/") {
+					continue
+				}
+
+				if len(owned) > best {
+					best = len(owned)
+					bestOwners = bestOwners[:0]
+				}
+
+				if len(owned) == best {
+					bestOwners = append(bestOwners, m.UserLogin)
+				}
+			}
+		}
+
+		for _, o := range bestOwners {
+			owners[o] = true
+		}
+	}
+
+	result := make([]string, 0, len(owners))
+	for o := range owners {
+		result = append(result, o)
+	}
+	sort.Strings(result)
+
+	return result
+}
+
+func (lm *LifecycleMgr) addEscalationComment(context context.Context, issue *storage.Issue, comment string, dryRun bool) error {
+	if dryRun {
+		scope.Infof("Would have added escalation comment to issue %d in repo %s/%s", issue.IssueNumber, issue.OrgLogin, issue.RepoName)
+		return nil
+	}
+
+	if err := lm.gc.AddOrReplaceBotComment(context, issue.OrgLogin, issue.RepoName, int(issue.IssueNumber), issue.Author, comment,
+		escalationSignature); err != nil {
+		return err
+	}
+
+	scope.Infof("Added escalation comment to issue %d in repo %s/%s", issue.IssueNumber, issue.OrgLogin, issue.RepoName)
+	return nil
+}
+
+func (lm *LifecycleMgr) removeEscalationComment(context context.Context, issue *storage.Issue, dryRun bool) error {
+	if dryRun {
+		scope.Infof("Would have removed escalation comment from issue %d in repo %s/%s", issue.IssueNumber, issue.OrgLogin, issue.RepoName)
+		return nil
+	}
+
+	return lm.gc.RemoveBotComment(context, issue.OrgLogin, issue.RepoName, int(issue.IssueNumber), escalationSignature)
+}
@@ -0,0 +1,113 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lifecyclemgr
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-github/v26/github"
+
+	"istio.io/bots/policybot/pkg/storage"
+)
+
+func TestIsTriagedLabel(t *testing.T) {
+	triaged := []string{"area/", "priority/P0"}
+
+	cases := map[string]bool{
+		"area/networking": true,
+		"Area/Security":   true,
+		"priority/P0":     true,
+		"priority/P1":     false,
+		"kind/bug":        false,
+		"area":            false,
+		"lifecycle/stale": false,
+	}
+
+	for label, expected := range cases {
+		if got := isTriagedLabel(label, triaged); got != expected {
+			t.Errorf("isTriagedLabel(%s) = %v, expected %v", label, got, expected)
+		}
+	}
+}
+
+func TestLatestLabelings(t *testing.T) {
+	day := func(d int) *time.Time {
+		t := time.Date(2019, 10, d, 0, 0, 0, 0, time.UTC)
+		return &t
+	}
+
+	event := func(kind string, label string, actor string, d int) *github.IssueEvent {
+		return &github.IssueEvent{
+			Event:     &kind,
+			Label:     &github.Label{Name: &label},
+			Actor:     &github.User{Login: &actor},
+			CreatedAt: day(d),
+		}
+	}
+
+	events := []*github.IssueEvent{
+		event("labeled", "triage/needs-triage", "istio-policy-bot", 1),
+		event("labeled", "area/networking", "outsider", 2),
+		event("unlabeled", "area/networking", "member", 3),
+		event("labeled", "Area/Networking", "member", 4),
+		event("labeled", "triage/needs-triage", "istio-policy-bot", 5),
+		event("labeled", "kind/bug", "member", 7),
+		event("labeled", "kind/bug", "outsider", 6),
+	}
+
+	expected := map[string]labeling{
+		"triage/needs-triage": {actor: "istio-policy-bot", at: *day(5)},
+		"area/networking":     {actor: "member", at: *day(4)},
+		"kind/bug":            {actor: "member", at: *day(7)},
+	}
+
+	if diff := cmp.Diff(expected, latestLabelings(events), cmp.AllowUnexported(labeling{})); diff != "" {
+		t.Errorf("Unexpected labelings (-want +got):\n%s", diff)
+	}
+}
+
+func TestOwnersOf(t *testing.T) {
+	maintainers := []*storage.Maintainer{
+		{UserLogin: "root", Paths: []string{"istio/"}},
+		{UserLogin: "net1", Paths: []string{"istio/pilot/pkg/networking"}},
+		{UserLogin: "net2", Paths: []string{"istio/pilot/pkg/networking/", "istio/pilot"}},
+		{UserLogin: "pilot", Paths: []string{"istio/pilot"}},
+		{UserLogin: "retired", Paths: []string{"istio/pilot/pkg/networking"}, Emeritus: true},
+		{UserLogin: "other", Paths: []string{"api/pilot/pkg/networking"}},
+		{UserLogin: "sec", Paths: []string{"istio/security"}},
+	}
+
+	cases := []struct {
+		name     string
+		paths    []string
+		expected []string
+	}{
+		{"most specific", []string{"pilot/pkg/networking/core"}, []string{"net1", "net2"}},
+		{"parent path", []string{"pilot/pkg/serviceregistry"}, []string{"net2", "pilot"}},
+		{"several areas", []string{"pilot/pkg/networking", "security/"}, []string{"net1", "net2", "sec"}},
+		{"root fallback", []string{"tools"}, []string{"root"}},
+		{"prefix is not a directory", []string{"securityx"}, []string{"root"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if diff := cmp.Diff(c.expected, ownersOf(maintainers, "istio", c.paths)); diff != "" {
+				t.Errorf("Unexpected owners (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
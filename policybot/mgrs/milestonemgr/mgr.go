@@ -23,6 +23,8 @@ import (
 
 	"istio.io/bots/policybot/pkg/config"
 	"istio.io/bots/policybot/pkg/gh"
+	"istio.io/bots/policybot/pkg/pipeline"
+	"istio.io/bots/policybot/pkg/report"
 	"istio.io/istio/pkg/log"
 )
 
@@ -41,23 +43,47 @@ func New(gc *gh.ThrottledClient, reg *config.Registry) *MilestoneMgr {
 	}
 }
 
-func (mm *MilestoneMgr) MakeConfiguredMilestones(context context.Context, dryRun bool) error {
+// MakeConfiguredMilestones creates or updates the configured milestones in every repo.
+func (mm *MilestoneMgr) MakeConfiguredMilestones(context context.Context, dryRun bool) (*report.Report, error) {
+	core := mm.reg.Core()
+	rep := report.New("milestonemgr")
+
+	var repos []interface{}
 	for _, repo := range mm.reg.Repos() {
+		repos = append(repos, repo)
+	}
+
+	err := pipeline.ForEach(context, repos, core.RepoParallelism, func(item interface{}) error {
+		repo := item.(gh.RepoDesc)
+		rr := rep.Repo(repo.OrgAndRepo)
+
+		var milestones []interface{}
 		for _, r := range mm.reg.Records(recordType, repo.OrgAndRepo) {
-			milestone := r.(*milestoneRecord)
+			milestones = append(milestones, r)
+		}
+
+		return pipeline.ForEach(context, milestones, core.ItemParallelism, func(item interface{}) error {
+			milestone := item.(*milestoneRecord)
 
 			if dryRun {
 				scope.Infof("Would have created or updated milestone %s in repo %s", milestone.Name, repo)
-				continue
+				rr.Add("skipped", 1)
+				return nil
 			}
 
 			if err := mm.makeMilestone(context, repo, milestone); err != nil {
-				return fmt.Errorf("unable to create milestone %s in repo %s: %v", milestone.Name, repo, err)
+				err = fmt.Errorf("unable to create milestone %s in repo %s: %v", milestone.Name, repo, err)
+				rr.Fail(err)
+				return err
 			}
-		}
-	}
 
-	return nil
+			rr.Add("milestones", 1)
+			return nil
+		})
+	})
+
+	rep.Finish()
+	return rep, err
 }
 
 var (
@@ -90,7 +116,7 @@ func (mm *MilestoneMgr) makeMilestone(context context.Context, repo gh.RepoDesc,
 		return nil
 	}
 
-	num, err := findMilestone(context, mm.gc, repo, milestone.Name)
+	num, err := FindMilestone(context, mm.gc, repo, milestone.Name)
 	if num < 0 {
 		if err == nil {
 			return fmt.Errorf("unable to create or edit milestone %s in repo %s", milestone.Name, repo)
@@ -110,8 +136,8 @@ func (mm *MilestoneMgr) makeMilestone(context context.Context, repo gh.RepoDesc,
 	return err
 }
 
-// findMilestone looks for a milestone with the given name in a repo
-func findMilestone(context context.Context, gc *gh.ThrottledClient, repo gh.RepoDesc, name string) (int, error) {
+// FindMilestone looks for a milestone with the given name in a repo
+func FindMilestone(context context.Context, gc *gh.ThrottledClient, repo gh.RepoDesc, name string) (int, error) {
 	opt := &github.MilestoneListOptions{
 		State: "all",
 		ListOptions: github.ListOptions{
@@ -0,0 +1,248 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rebasemgr
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v26/github"
+
+	"istio.io/bots/policybot/pkg/config"
+	"istio.io/bots/policybot/pkg/gh"
+	"istio.io/istio/pkg/log"
+)
+
+// RebaseMgr flags PRs that can't be merged because they conflict with their base branch.
+type RebaseMgr struct {
+	gc  *gh.ThrottledClient
+	reg *config.Registry
+}
+
+var scope = log.RegisterScope("rebasemgr", "The PR rebase manager")
+
+const rebaseSignature = "\n\n_Courtesy of your friendly rebase detector_."
+
+func New(gc *gh.ThrottledClient, reg *config.Registry) *RebaseMgr {
+	return &RebaseMgr{
+		gc:  gc,
+		reg: reg,
+	}
+}
+
+// ManageAll reconciles the rebase label on all open PRs in the configured repos.
+func (rm *RebaseMgr) ManageAll(context context.Context, dryRun bool) error {
+	for _, repo := range rm.reg.Repos() {
+		if err := rm.manageRepo(context, repo.OrgLogin, repo.RepoName, "", dryRun); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ManageBranch reconciles the rebase label on the open PRs targeting the given branch.
+func (rm *RebaseMgr) ManageBranch(context context.Context, orgLogin string, repoName string, branch string) error {
+	return rm.manageRepo(context, orgLogin, repoName, branch, false)
+}
+
+// ManagePR reconciles the rebase label on a single PR.
+func (rm *RebaseMgr) ManagePR(context context.Context, orgLogin string, repoName string, prNumber int) error {
+	r, ok := rm.reg.SingleRecord(RecordType, orgLogin+"/"+repoName)
+	if !ok {
+		return nil
+	}
+
+	return rm.managePR(context, orgLogin, repoName, prNumber, r.(*rebaseRecord), false)
+}
+
+// Timeout returns how long a webhook-triggered scan of PRs in the given repo may take.
+func (rm *RebaseMgr) Timeout(orgAndRepo string) time.Duration {
+	if r, ok := rm.reg.SingleRecord(RecordType, orgAndRepo); ok && r.(*rebaseRecord).ScanTimeout > 0 {
+		return time.Duration(r.(*rebaseRecord).ScanTimeout)
+	}
+
+	return DefaultScanTimeout
+}
+
+func (rm *RebaseMgr) manageRepo(context context.Context, orgLogin string, repoName string, branch string, dryRun bool) error {
+	r, ok := rm.reg.SingleRecord(RecordType, orgLogin+"/"+repoName)
+	if !ok {
+		return nil
+	}
+	rr := r.(*rebaseRecord)
+
+	var prs []int
+	if err := rm.gc.FetchOpenPullRequests(context, orgLogin, repoName, branch, func(list []*github.PullRequest) error {
+		for _, pr := range list {
+			prs = append(prs, pr.GetNumber())
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	scope.Infof("Checking %d open PRs in repo %s/%s", len(prs), orgLogin, repoName)
+
+	for _, num := range prs {
+		if err := context.Err(); err != nil {
+			// cancelled or out of time, the remaining PRs will be checked by a later scan
+			return err
+		}
+
+		if err := rm.managePR(context, orgLogin, repoName, num, rr, dryRun); err != nil {
+			scope.Errorf("%v", err)
+		}
+	}
+
+	return nil
+}
+
+func (rm *RebaseMgr) managePR(context context.Context, orgLogin string, repoName string, prNumber int, rr *rebaseRecord, dryRun bool) error {
+	pr, err := rm.getMergeablePR(context, orgLogin, repoName, prNumber, rr)
+	if err != nil {
+		return fmt.Errorf("unable to get PR %d in repo %s/%s: %v", prNumber, orgLogin, repoName, err)
+	} else if pr == nil {
+		scope.Infof("Mergeability of PR %d in repo %s/%s is still unknown, skipping", prNumber, orgLogin, repoName)
+		return nil
+	}
+
+	if pr.GetState() != "open" {
+		return nil
+	}
+
+	hasLabel := false
+	for _, l := range pr.Labels {
+		if strings.EqualFold(l.GetName(), rr.Label) {
+			hasLabel = true
+			break
+		}
+	}
+
+	// a dirty state means there are conflicts, other non-mergeable states are due to checks or reviews
+	needsRebase := !pr.GetMergeable() && pr.GetMergeableState() == "dirty"
+
+	if needsRebase && !hasLabel {
+		if dryRun {
+			scope.Infof("Would have added label %s to PR %d in repo %s/%s", rr.Label, prNumber, orgLogin, repoName)
+		} else if _, _, err := rm.gc.ThrottledCall(func(client *github.Client) (interface{}, *github.Response, error) {
+			return client.Issues.AddLabelsToIssue(context, orgLogin, repoName, prNumber, []string{rr.Label})
+		}); err != nil {
+			return fmt.Errorf("unable to add label %s to PR %d in repo %s/%s: %v", rr.Label, prNumber, orgLogin, repoName, err)
+		}
+	} else if !needsRebase && hasLabel {
+		if dryRun {
+			scope.Infof("Would have removed label %s from PR %d in repo %s/%s", rr.Label, prNumber, orgLogin, repoName)
+		} else if _, err := rm.gc.ThrottledCallNoResult(func(client *github.Client) (*github.Response, error) {
+			return client.Issues.RemoveLabelForIssue(context, orgLogin, repoName, prNumber, rr.Label)
+		}); err != nil {
+			return fmt.Errorf("unable to remove label %s from PR %d in repo %s/%s: %v", rr.Label, prNumber, orgLogin, repoName, err)
+		}
+	}
+
+	if !rr.Comment || dryRun {
+		return nil
+	}
+
+	if !needsRebase {
+		if err := rm.gc.RemoveBotComment(context, orgLogin, repoName, prNumber, rebaseSignature); err != nil {
+			return fmt.Errorf("unable to remove comment from PR %d in repo %s/%s: %v", prNumber, orgLogin, repoName, err)
+		}
+		return nil
+	}
+
+	msg := fmt.Sprintf("😞 @{{ .Author }}, this PR has merge conflicts with the `%s` branch and needs to be rebased.", pr.GetBase().GetRef())
+	if conflicts := rm.conflicts(context, orgLogin, repoName, pr); len(conflicts) > 0 {
+		msg += " The following files were changed on both sides and are likely conflicting:\n\n- `" + strings.Join(conflicts, "`\n- `") + "`"
+	}
+
+	if err := rm.gc.AddOrReplaceBotComment(context, orgLogin, repoName, prNumber, pr.GetUser().GetLogin(), msg, rebaseSignature); err != nil {
+		return fmt.Errorf("unable to comment on PR %d in repo %s/%s: %v", prNumber, orgLogin, repoName, err)
+	}
+
+	return nil
+}
+
+// getMergeablePR fetches a PR, waiting until GitHub has worked out whether it can be merged. Returns nil if that
+// remains unknown after the configured number of retries.
+func (rm *RebaseMgr) getMergeablePR(context context.Context, orgLogin string, repoName string, prNumber int, rr *rebaseRecord) (*github.PullRequest, error) {
+	for i := 0; ; i++ {
+		pr, _, err := rm.gc.ThrottledCall(func(client *github.Client) (interface{}, *github.Response, error) {
+			return client.PullRequests.Get(context, orgLogin, repoName, prNumber)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		result := pr.(*github.PullRequest)
+		if result.Mergeable != nil || result.GetState() != "open" {
+			return result, nil
+		}
+
+		if i >= rr.MergeableRetries {
+			return nil, nil
+		}
+
+		select {
+		case <-context.Done():
+			return nil, context.Err()
+		case <-time.After(time.Duration(rr.MergeableRetryDelay)):
+		}
+	}
+}
+
+// conflicts returns the files changed both by the PR and on its base branch since the PR branched off
+func (rm *RebaseMgr) conflicts(context context.Context, orgLogin string, repoName string, pr *github.PullRequest) []string {
+	prFiles := make(map[string]bool)
+	if err := rm.gc.FetchFiles(context, orgLogin, repoName, pr.GetNumber(), func(files []string) error {
+		for _, f := range files {
+			prFiles[f] = true
+		}
+		return nil
+	}); err != nil {
+		scope.Warnf("Unable to get files for PR %d in repo %s/%s: %v", pr.GetNumber(), orgLogin, repoName, err)
+		return nil
+	}
+
+	c, _, err := rm.gc.ThrottledCall(func(client *github.Client) (interface{}, *github.Response, error) {
+		return client.Repositories.CompareCommits(context, orgLogin, repoName, pr.GetBase().GetRef(), pr.GetHead().GetSHA())
+	})
+	if err != nil {
+		scope.Warnf("Unable to compare PR %d in repo %s/%s with %s: %v", pr.GetNumber(), orgLogin, repoName, pr.GetBase().GetRef(), err)
+		return nil
+	}
+	mergeBase := c.(*github.CommitsComparison).GetMergeBaseCommit().GetSHA()
+
+	c, _, err = rm.gc.ThrottledCall(func(client *github.Client) (interface{}, *github.Response, error) {
+		return client.Repositories.CompareCommits(context, orgLogin, repoName, mergeBase, pr.GetBase().GetRef())
+	})
+	if err != nil {
+		scope.Warnf("Unable to compare %s with %s in repo %s/%s: %v", mergeBase, pr.GetBase().GetRef(), orgLogin, repoName, err)
+		return nil
+	}
+
+	var conflicts []string
+	for _, f := range c.(*github.CommitsComparison).Files {
+		if prFiles[f.GetFilename()] {
+			conflicts = append(conflicts, f.GetFilename())
+		}
+	}
+	sort.Strings(conflicts)
+
+	return conflicts
+}
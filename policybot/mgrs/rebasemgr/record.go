@@ -0,0 +1,54 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rebasemgr
+
+import (
+	"time"
+
+	"istio.io/bots/policybot/pkg/config"
+)
+
+const RecordType = "rebase"
+
+// DefaultScanTimeout is how long a webhook-triggered scan may take unless configured otherwise
+const DefaultScanTimeout = 15 * time.Minute
+
+type rebaseRecord struct {
+	config.RecordBase
+
+	// Label applied to PRs that conflict with their base branch
+	Label string `json:"label"`
+
+	// Comment controls whether a comment listing the conflicting files is posted on PRs that need a rebase
+	Comment bool `json:"comment"`
+
+	// GitHub computes mergeability lazily, so we poll until it's known
+	MergeableRetries    int             `json:"mergeable_retries"`
+	MergeableRetryDelay config.Duration `json:"mergeable_retry_delay"`
+
+	// ScanTimeout bounds how long a scan triggered by a webhook event may take
+	ScanTimeout config.Duration `json:"scan_timeout"`
+}
+
+func init() {
+	config.RegisterType(RecordType, config.OnePerRepo, func() config.Record {
+		return &rebaseRecord{
+			Label:               "needs-rebase",
+			MergeableRetries:    5,
+			MergeableRetryDelay: config.Duration(3 * time.Second),
+			ScanTimeout:         config.Duration(DefaultScanTimeout),
+		}
+	})
+}
@@ -0,0 +1,209 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package releasenotesmgr
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"istio.io/bots/policybot/pkg/releasenotes"
+)
+
+// headings for the well-known kinds, others are derived from the kind's name
+var kindHeadings = map[string]string{
+	"bug-fix":      "Bug Fixes",
+	"feature":      "Features",
+	"promotion":    "Promotions",
+	"security-fix": "Security Fixes",
+	"test":         "Testing",
+}
+
+// Markdown renders the release notes in the format used for the change notes on istio.io.
+func (r *Report) Markdown() string {
+	var sb strings.Builder
+
+	sb.WriteString("---\n")
+	sb.WriteString("title: Change Notes\n")
+	sb.WriteString(fmt.Sprintf("description: Istio %s release notes.\n", r.Milestone))
+	sb.WriteString("weight: 10\n")
+	sb.WriteString("---\n")
+
+	var security []string
+	for _, n := range r.Notes {
+		for _, s := range n.Note.SecurityNotes {
+			security = append(security, r.bullet(s, n))
+		}
+	}
+
+	if len(security) > 0 {
+		sb.WriteString("\n## Security Update\n\n")
+		for _, s := range security {
+			sb.WriteString(s)
+		}
+	}
+
+	// group by kind, then by area
+	groups := make(map[string]map[string][]string)
+	for _, n := range r.Notes {
+		for _, rn := range n.Note.ReleaseNotes {
+			areas := groups[n.Note.Kind]
+			if areas == nil {
+				areas = make(map[string][]string)
+				groups[n.Note.Kind] = areas
+			}
+			areas[n.Note.Area] = append(areas[n.Note.Area], r.bullet(rn, n))
+		}
+	}
+
+	for _, kind := range r.kindOrder(groups) {
+		sb.WriteString(fmt.Sprintf("\n## %s\n", kindHeading(kind)))
+
+		areas := make([]string, 0, len(groups[kind]))
+		for area := range groups[kind] {
+			areas = append(areas, area)
+		}
+		sort.Strings(areas)
+
+		for _, area := range areas {
+			sb.WriteString(fmt.Sprintf("\n### %s\n\n", titleCase(area)))
+			for _, b := range groups[kind][area] {
+				sb.WriteString(b)
+			}
+		}
+	}
+
+	first := true
+	for _, n := range r.Notes {
+		for _, un := range n.Note.UpgradeNotes {
+			if first {
+				sb.WriteString("\n## Upgrade Notes\n")
+				first = false
+			}
+			sb.WriteString(fmt.Sprintf("\n### %s\n\n%s\n", strings.TrimSpace(un.Title), strings.TrimSpace(un.Content)))
+		}
+	}
+
+	return sb.String()
+}
+
+// kindOrder returns the kinds present in the groups, in the configured order followed by any others alphabetically
+func (r *Report) kindOrder(groups map[string]map[string][]string) []string {
+	known := r.Kinds
+	if len(known) == 0 {
+		known = releasenotes.DefaultKinds
+	}
+
+	var result []string
+	seen := make(map[string]bool)
+	for _, kind := range known {
+		if _, ok := groups[kind]; ok {
+			result = append(result, kind)
+			seen[kind] = true
+		}
+	}
+
+	var others []string
+	for kind := range groups {
+		if !seen[kind] {
+			others = append(others, kind)
+		}
+	}
+	sort.Strings(others)
+
+	return append(result, others...)
+}
+
+// bullet renders a single note as a list item, followed by links to the relevant issues and PRs
+func (r *Report) bullet(text string, n *Note) string {
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	for i := 1; i < len(lines); i++ {
+		if lines[i] != "" {
+			lines[i] = "  " + lines[i]
+		}
+	}
+
+	var refs []string
+	for _, issue := range n.Note.Issue {
+		if ref := r.issueRef(issue); ref != "" {
+			refs = append(refs, ref)
+		}
+	}
+
+	for _, num := range n.PullRequests {
+		refs = append(refs, fmt.Sprintf("[#%d](https://github.com/%s/%s/pull/%d)", num, r.OrgLogin, r.RepoName, num))
+	}
+
+	result := "- " + strings.Join(lines, "\n")
+	if len(refs) > 0 {
+		result += " (" + strings.Join(refs, ", ") + ")"
+	}
+
+	return result + "\n"
+}
+
+// issueRef turns an issue number or URL into a link
+func (r *Report) issueRef(issue interface{}) string {
+	var s string
+	switch v := issue.(type) {
+	case float64:
+		s = strconv.FormatInt(int64(v), 10)
+	case int64:
+		s = strconv.FormatInt(v, 10)
+	case string:
+		s = strings.TrimPrefix(strings.TrimSpace(v), "#")
+	default:
+		return ""
+	}
+
+	if num, err := strconv.Atoi(s); err == nil {
+		return fmt.Sprintf("[Issue #%d](https://github.com/%s/%s/issues/%d)", num, r.OrgLogin, r.RepoName, num)
+	}
+
+	u, err := url.Parse(s)
+	if err != nil || u.Scheme == "" {
+		return ""
+	}
+
+	text := path.Base(u.Path)
+	if _, err := strconv.Atoi(text); err == nil {
+		text = "Issue #" + text
+	}
+
+	return fmt.Sprintf("[%s](%s)", text, s)
+}
+
+func kindHeading(kind string) string {
+	if h, ok := kindHeadings[kind]; ok {
+		return h
+	}
+
+	return titleCase(kind)
+}
+
+// titleCase turns a hyphenated name into a heading, e.g. traffic-management -> Traffic Management
+func titleCase(s string) string {
+	words := strings.Split(s, "-")
+	for i, w := range words {
+		if w != "" {
+			words[i] = strings.ToUpper(w[:1]) + w[1:]
+		}
+	}
+
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,248 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package releasenotesmgr
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-github/v26/github"
+
+	"istio.io/bots/policybot/handlers/githubwebhook/releasenoter"
+	"istio.io/bots/policybot/pkg/config"
+	"istio.io/bots/policybot/pkg/gh"
+	"istio.io/bots/policybot/pkg/releasenotes"
+	"istio.io/bots/policybot/pkg/storage"
+	"istio.io/istio/pkg/log"
+)
+
+// ReleaseNotesMgr assembles the release notes for a milestone from the notes carried by merged PRs.
+type ReleaseNotesMgr struct {
+	gc    *gh.ThrottledClient
+	store storage.Store
+	reg   *config.Registry
+}
+
+// Note is a release note along with the PRs that carried it.
+type Note struct {
+	Path         string
+	Note         *releasenotes.Note
+	PullRequests []int64
+}
+
+// Report holds the outcome of collecting the release notes for a milestone.
+type Report struct {
+	OrgLogin  string
+	RepoName  string
+	Milestone string
+	Kinds     []string
+	Notes     []*Note
+	Missing   []*storage.PullRequest
+	Invalid   []string
+}
+
+// prNotes tracks the notes found in a single PR
+type prNotes struct {
+	pr     *storage.PullRequest
+	notes  []fileNote
+	exempt bool
+}
+
+type fileNote struct {
+	path    string
+	content string
+	note    *releasenotes.Note
+}
+
+var scope = log.RegisterScope("releasenotesmgr", "The release notes manager")
+
+// matches the body of backport PRs created by the cherrypicker
+var cherryPickRegexp = regexp.MustCompile(`(?i)cherry-pick of #(\d+)`)
+
+func New(gc *gh.ThrottledClient, store storage.Store, reg *config.Registry) *ReleaseNotesMgr {
+	return &ReleaseNotesMgr{
+		gc:    gc,
+		store: store,
+		reg:   reg,
+	}
+}
+
+// Generate collects the release notes from the PRs merged for the given milestone. A PR
+// belongs to the milestone if it is assigned to it, or if it was merged into the given branch.
+func (rm *ReleaseNotesMgr) Generate(context context.Context, repo gh.RepoDesc, milestone string, branchName string) (*Report, error) {
+	r, ok := rm.reg.SingleRecord(releasenoter.RecordType, repo.OrgAndRepo)
+	if !ok {
+		return nil, fmt.Errorf("release notes aren't configured for repo %s", repo)
+	}
+	rnr := r.(*releasenoter.ReleaseNoteRecord)
+
+	globs := make(map[string]*regexp.Regexp)
+	if err := releasenoter.CompileGlobs(rnr, globs); err != nil {
+		return nil, err
+	}
+
+	report := &Report{
+		OrgLogin:  repo.OrgLogin,
+		RepoName:  repo.RepoName,
+		Milestone: milestone,
+		Kinds:     rnr.Kinds,
+	}
+
+	var all []*prNotes
+	if err := rm.store.QueryMergedPullRequestsByMilestone(context, repo.OrgLogin, repo.RepoName, milestone, branchName, func(pr *storage.PullRequest) error {
+		pn := &prNotes{pr: pr}
+		all = append(all, pn)
+
+		for _, f := range pr.Files {
+			if !globs[rnr.NotesPath].MatchString(f) {
+				continue
+			}
+
+			content, found, err := rm.getContent(context, repo, pr.HeadCommit, f)
+			if err != nil {
+				return fmt.Errorf("unable to read release note %s from PR %d: %v", f, pr.PullRequestNumber, err)
+			} else if !found {
+				// the file was deleted by the PR
+				continue
+			}
+
+			note, err := releasenotes.Parse(content)
+			if err != nil {
+				report.Invalid = append(report.Invalid, fmt.Sprintf("%s in PR %d: unable to parse: %v", f, pr.PullRequestNumber, err))
+				continue
+			}
+
+			pn.notes = append(pn.notes, fileNote{path: f, content: strings.TrimSpace(string(content)), note: note})
+		}
+
+		if len(pn.notes) == 0 {
+			pn.exempt = releasenoter.Exemption(rnr, globs, pr.Labels, pr.Author, pr.Files) != ""
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	scope.Infof("Found %d merged PRs for milestone %s in repo %s", len(all), milestone, repo)
+
+	report.Notes, report.Missing = collate(all)
+	return report, nil
+}
+
+// collate merges the notes from the given PRs, dropping duplicates introduced by cherry-picks,
+// and returns the PRs that should have had notes but didn't.
+func collate(all []*prNotes) ([]*Note, []*storage.PullRequest) {
+	byNumber := make(map[int64]*prNotes, len(all))
+	for _, pn := range all {
+		byNumber[pn.pr.PullRequestNumber] = pn
+	}
+
+	var notes []*Note
+	byContent := make(map[string]*Note)
+	byPath := make(map[string]*Note)
+	var missing []*storage.PullRequest
+
+	add := func(pn *prNotes, fn fileNote) {
+		n := byContent[fn.content]
+		if n == nil {
+			n = &Note{Path: fn.path, Note: fn.note}
+			byContent[fn.content] = n
+			byPath[fn.path] = n
+			notes = append(notes, n)
+		}
+		n.PullRequests = append(n.PullRequests, pn.pr.PullRequestNumber)
+	}
+
+	// handle originals first, so that cherry-picks can be folded into them even if a note was tweaked in the process
+	var picks []*prNotes
+	for _, pn := range all {
+		if origin(pn, byNumber) != nil {
+			picks = append(picks, pn)
+			continue
+		}
+
+		for _, fn := range pn.notes {
+			add(pn, fn)
+		}
+
+		if len(pn.notes) == 0 && !pn.exempt {
+			missing = append(missing, pn.pr)
+		}
+	}
+
+	for _, pn := range picks {
+		for _, fn := range pn.notes {
+			if n := byPath[fn.path]; n != nil {
+				n.PullRequests = append(n.PullRequests, pn.pr.PullRequestNumber)
+				continue
+			}
+			add(pn, fn)
+		}
+
+		if len(pn.notes) == 0 && !pn.exempt && len(origin(pn, byNumber).notes) == 0 {
+			missing = append(missing, pn.pr)
+		}
+	}
+
+	return notes, missing
+}
+
+// origin returns the PR that the given PR is a cherry-pick of, if that PR is part of the set
+func origin(pn *prNotes, byNumber map[int64]*prNotes) *prNotes {
+	m := cherryPickRegexp.FindStringSubmatch(pn.pr.Body)
+	if m == nil {
+		return nil
+	}
+
+	num, err := strconv.ParseInt(m[1], 10, 64)
+	if err != nil || num == pn.pr.PullRequestNumber {
+		return nil
+	}
+
+	return byNumber[num]
+}
+
+// getContent returns the content of a file at the given commit
+func (rm *ReleaseNotesMgr) getContent(context context.Context, repo gh.RepoDesc, sha string, path string) ([]byte, bool, error) {
+	opt := &github.RepositoryContentGetOptions{Ref: sha}
+
+	fc, _, resp, err := rm.gc.ThrottledCallTwoResult(func(client *github.Client) (interface{}, interface{}, *github.Response, error) {
+		return client.Repositories.GetContents(context, repo.OrgLogin, repo.RepoName, path, opt)
+	})
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+
+	rc := fc.(*github.RepositoryContent)
+	if rc == nil {
+		// not a file
+		return nil, false, nil
+	}
+
+	content, err := rc.GetContent()
+	if err != nil {
+		return nil, false, err
+	}
+
+	return []byte(content), true, nil
+}
@@ -0,0 +1,140 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package releasenotesmgr
+
+import (
+	"testing"
+
+	"istio.io/bots/policybot/pkg/releasenotes"
+	"istio.io/bots/policybot/pkg/storage"
+)
+
+func TestCollate(t *testing.T) {
+	fix := &releasenotes.Note{Kind: "bug-fix", Area: "traffic-management", ReleaseNotes: []string{"**Fixed** a crash."}}
+	fixTweaked := &releasenotes.Note{Kind: "bug-fix", Area: "traffic-management", ReleaseNotes: []string{"**Fixed** a crash!"}}
+	feature := &releasenotes.Note{Kind: "feature", Area: "istioctl", ReleaseNotes: []string{"**Added** a command."}}
+
+	all := []*prNotes{
+		{
+			pr:    &storage.PullRequest{PullRequestNumber: 1},
+			notes: []fileNote{{path: "releasenotes/notes/fix.yaml", content: "fix", note: fix}},
+		},
+		{
+			// cherry-pick with a tweaked note at the same path
+			pr:    &storage.PullRequest{PullRequestNumber: 2, Body: "This is an automated cherry-pick of #1"},
+			notes: []fileNote{{path: "releasenotes/notes/fix.yaml", content: "fix!", note: fixTweaked}},
+		},
+		{
+			// same note content under a different path
+			pr:    &storage.PullRequest{PullRequestNumber: 3},
+			notes: []fileNote{{path: "releasenotes/notes/fix-again.yaml", content: "fix", note: fix}},
+		},
+		{
+			pr:    &storage.PullRequest{PullRequestNumber: 4},
+			notes: []fileNote{{path: "releasenotes/notes/feature.yaml", content: "feature", note: feature}},
+		},
+		{
+			pr: &storage.PullRequest{PullRequestNumber: 5},
+		},
+		{
+			pr:     &storage.PullRequest{PullRequestNumber: 6},
+			exempt: true,
+		},
+		{
+			// cherry-pick of a PR that had a note, so nothing is missing
+			pr: &storage.PullRequest{PullRequestNumber: 7, Body: "This is an automated cherry-pick of #4"},
+		},
+		{
+			// cherry-pick of a PR from outside the milestone
+			pr: &storage.PullRequest{PullRequestNumber: 8, Body: "This is an automated cherry-pick of #100"},
+		},
+	}
+
+	notes, missing := collate(all)
+
+	if len(notes) != 2 {
+		t.Fatalf("Got %d notes, expected 2", len(notes))
+	}
+
+	if got := notes[0].PullRequests; len(got) != 3 || got[0] != 1 || got[1] != 3 || got[2] != 2 {
+		t.Errorf("Got PRs %v for the first note, expected [1 3 2]", got)
+	}
+
+	if got := notes[1].PullRequests; len(got) != 1 || got[0] != 4 {
+		t.Errorf("Got PRs %v for the second note, expected [4]", got)
+	}
+
+	if len(missing) != 2 || missing[0].PullRequestNumber != 5 || missing[1].PullRequestNumber != 8 {
+		t.Errorf("Got %d missing PRs, expected PRs 5 and 8", len(missing))
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	r := &Report{
+		OrgLogin:  "istio",
+		RepoName:  "istio",
+		Milestone: "1.5",
+		Notes: []*Note{
+			{
+				Note: &releasenotes.Note{
+					Kind:         "feature",
+					Area:         "istioctl",
+					Issue:        []interface{}{float64(10), "https://github.com/istio/istio/issues/11"},
+					ReleaseNotes: []string{"**Added** a command."},
+				},
+				PullRequests: []int64{1},
+			},
+			{
+				Note: &releasenotes.Note{
+					Kind:         "bug-fix",
+					Area:         "traffic-management",
+					ReleaseNotes: []string{"**Fixed** a crash\nwhen things go wrong."},
+					UpgradeNotes: []releasenotes.UpgradeNote{{Title: "Removed a flag", Content: "Use the other one."}},
+				},
+				PullRequests: []int64{2, 3},
+			},
+		},
+	}
+
+	expected := `---
+title: Change Notes
+description: Istio 1.5 release notes.
+weight: 10
+---
+
+## Bug Fixes
+
+### Traffic Management
+
+- **Fixed** a crash
+  when things go wrong. ([#2](https://github.com/istio/istio/pull/2), [#3](https://github.com/istio/istio/pull/3))
+
+## Features
+
+### Istioctl
+
+- **Added** a command. ([Issue #10](https://github.com/istio/istio/issues/10), [Issue #11](https://github.com/istio/istio/issues/11), [#1](https://github.com/istio/istio/pull/1))
+
+## Upgrade Notes
+
+### Removed a flag
+
+Use the other one.
+`
+
+	if got := r.Markdown(); got != expected {
+		t.Errorf("Got:\n%s\nExpected:\n%s", got, expected)
+	}
+}
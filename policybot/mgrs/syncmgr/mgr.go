@@ -38,7 +38,9 @@ import (
 	"istio.io/bots/policybot/pkg/gh"
 	"istio.io/bots/policybot/pkg/pipeline"
 	"istio.io/bots/policybot/pkg/resultgatherer"
+	"istio.io/bots/policybot/pkg/signatures"
 	"istio.io/bots/policybot/pkg/storage"
+	"istio.io/bots/policybot/pkg/testflakes"
 	"istio.io/istio/pkg/env"
 	"istio.io/istio/pkg/log"
 )
@@ -1009,12 +1011,19 @@ func (ss *syncState) handleTestResults() (result error) {
 			continue
 		}
 
+		sigs, err := signatures.ForRepo(ss.mgr.reg, repo.OrgAndRepo)
+		if err != nil {
+			// results are still worth having without signatures
+			scope.Errorf("Unable to load failure signatures for repo %s: %v", repo.OrgAndRepo, err)
+		}
+
 		tor := r.(*refresher.TestOutputRecord)
 		g := resultgatherer.TestResultGatherer{
 			Client:           ss.mgr.blobstore,
 			BucketName:       tor.BucketName,
 			PreSubmitPrefix:  tor.PreSubmitTestPath,
 			PostSubmitPrefix: tor.PostSubmitTestPath,
+			Signatures:       sigs,
 		}
 
 		scope.Debugf("Getting test results for org %s", repo.OrgLogin)
@@ -1112,6 +1121,21 @@ func (ss *syncState) handleTestResults() (result error) {
 		log.Infof("detected %d new flakes", rowCount)
 	}
 
+	// refresh a full scoring window, since flakes are confirmed some time after the runs that found them
+	dayCount, err := ss.mgr.store.UpdateTestFlakeDays(ss.ctx, time.Now().AddDate(0, 0, -testflakes.LongWindowDays))
+	if err != nil {
+		result = multierror.Append(result, err)
+	} else {
+		log.Infof("updated %d daily test flake aggregates", dayCount)
+	}
+
+	caseCount, err := ss.mgr.store.UpdateTestCaseFlakeCache(ss.ctx, time.Now().AddDate(0, 0, -testflakes.LongWindowDays))
+	if err != nil {
+		result = multierror.Append(result, err)
+	} else {
+		log.Infof("detected %d new test case flakes", caseCount)
+	}
+
 	return result
 }
 
@@ -1122,12 +1146,19 @@ func (ss *syncState) handlePostSubmitTestResults() error {
 			continue
 		}
 
+		sigs, err := signatures.ForRepo(ss.mgr.reg, repo.OrgAndRepo)
+		if err != nil {
+			// results are still worth having without signatures
+			scope.Errorf("Unable to load failure signatures for repo %s: %v", repo.OrgAndRepo, err)
+		}
+
 		tor := r.(*refresher.TestOutputRecord)
 		g := resultgatherer.TestResultGatherer{
 			Client:           ss.mgr.blobstore,
 			BucketName:       tor.BucketName,
 			PreSubmitPrefix:  tor.PreSubmitTestPath,
 			PostSubmitPrefix: tor.PostSubmitTestPath,
+			Signatures:       sigs,
 		}
 
 		scope.Debugf("Getting post submit test results for org %s", repo.OrgLogin)
@@ -49,6 +49,12 @@ type CoreRecord struct {
 
 	// Default GitHub org to use in the UI when none is specified
 	DefaultOrg string `json:"default_org"`
+
+	// Number of repos the batch managers work on at once
+	RepoParallelism int `json:"repo_parallelism"`
+
+	// Number of items (issues, labels, milestones, etc.) the batch managers work on at once within a single repo
+	ItemParallelism int `json:"item_parallelism"`
 }
 
 func init() {
@@ -58,6 +64,8 @@ func init() {
 			CacheTTL:                 Duration(15 * time.Minute),
 			MaintainerActivityWindow: Duration(90 * 24 * time.Hour),
 			MemberActivityWindow:     Duration(180 * 24 * time.Hour),
+			RepoParallelism:          4,
+			ItemParallelism:          8,
 		}
 	})
 }
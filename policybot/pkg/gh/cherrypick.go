@@ -0,0 +1,251 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gh
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-github/v26/github"
+
+	"istio.io/istio/pkg/log"
+)
+
+// ErrCherryPickConflict is returned by CherryPick when a commit can't be cleanly applied to the target branch.
+var ErrCherryPickConflict = errors.New("the changes don't apply cleanly")
+
+// CherryPick applies the changes introduced by a list of commits, oldest first, on top of a target branch and
+// stores the result in a new branch, returning the SHA of the last new commit. This only uses the GitHub API, no
+// local clone is needed. If the new branch is left over from an earlier attempt, it is reused.
+//
+// The changes of each commit are computed relative to its first parent, so for a merge commit this picks up all
+// the changes that were merged in. ErrCherryPickConflict is returned if the changes don't apply cleanly.
+func (tc *ThrottledClient) CherryPick(context context.Context, orgLogin string, repoName string, shas []string,
+	targetBranch string, newBranch string,
+) (string, error) {
+	if len(shas) == 0 {
+		return "", fmt.Errorf("no commits to cherry-pick to branch %s in repo %s/%s", targetBranch, orgLogin, repoName)
+	}
+
+	r, _, err := tc.ThrottledCall(func(client *github.Client) (interface{}, *github.Response, error) {
+		return client.Git.GetRef(context, orgLogin, repoName, "heads/"+targetBranch)
+	})
+	if err != nil {
+		return "", fmt.Errorf("unable to get branch %s in repo %s/%s: %v", targetBranch, orgLogin, repoName, err)
+	}
+	tip := r.(*github.Reference).GetObject().GetSHA()
+
+	created := false
+	for _, sha := range shas {
+		if tip, err = tc.pickOne(context, orgLogin, repoName, sha, tip, newBranch, &created); err != nil {
+			if created {
+				tc.deleteBranch(context, orgLogin, repoName, newBranch)
+			}
+			return "", err
+		}
+	}
+
+	if err := tc.setBranch(context, orgLogin, repoName, newBranch, tip, &created); err != nil {
+		tc.deleteBranch(context, orgLogin, repoName, newBranch)
+		return "", err
+	}
+
+	return tip, nil
+}
+
+// pickOne applies the changes of a single commit on top of another, returning the SHA of the resulting commit.
+// The new branch is used as scratch space for the merge.
+func (tc *ThrottledClient) pickOne(context context.Context, orgLogin string, repoName string, sha string, onto string,
+	newBranch string, created *bool,
+) (string, error) {
+	c, _, err := tc.ThrottledCall(func(client *github.Client) (interface{}, *github.Response, error) {
+		return client.Git.GetCommit(context, orgLogin, repoName, sha)
+	})
+	if err != nil {
+		return "", fmt.Errorf("unable to get commit %s in repo %s/%s: %v", sha, orgLogin, repoName, err)
+	}
+	commit := c.(*github.Commit)
+
+	if len(commit.Parents) == 0 {
+		return "", fmt.Errorf("commit %s in repo %s/%s has no parent", sha, orgLogin, repoName)
+	}
+	parent := commit.Parents[0].GetSHA()
+
+	t, _, err := tc.ThrottledCall(func(client *github.Client) (interface{}, *github.Response, error) {
+		return client.Git.GetCommit(context, orgLogin, repoName, onto)
+	})
+	if err != nil {
+		return "", fmt.Errorf("unable to get commit %s in repo %s/%s: %v", onto, orgLogin, repoName, err)
+	}
+	ontoTree := t.(*github.Commit).GetTree().GetSHA()
+
+	// Create a commit holding the content being picked onto, but whose parent is the parent of the commit being picked.
+	// Merging the picked commit into this one then brings in exactly the changes made by the picked commit.
+	msg := fmt.Sprintf("Temporary commit for cherry-picking %s", sha)
+	s, _, err := tc.ThrottledCall(func(client *github.Client) (interface{}, *github.Response, error) {
+		return client.Git.CreateCommit(context, orgLogin, repoName, &github.Commit{
+			Message: &msg,
+			Tree:    &github.Tree{SHA: &ontoTree},
+			Parents: []github.Commit{{SHA: &parent}},
+		})
+	})
+	if err != nil {
+		return "", fmt.Errorf("unable to create commit in repo %s/%s: %v", orgLogin, repoName, err)
+	}
+
+	if err := tc.setBranch(context, orgLogin, repoName, newBranch, s.(*github.Commit).GetSHA(), created); err != nil {
+		return "", err
+	}
+
+	m, resp, err := tc.ThrottledCall(func(client *github.Client) (interface{}, *github.Response, error) {
+		return client.Repositories.Merge(context, orgLogin, repoName, &github.RepositoryMergeRequest{
+			Base:          &newBranch,
+			Head:          &sha,
+			CommitMessage: &msg,
+		})
+	})
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusConflict {
+			return "", ErrCherryPickConflict
+		}
+		return "", fmt.Errorf("unable to merge commit %s into branch %s in repo %s/%s: %v", sha, newBranch, orgLogin, repoName, err)
+	}
+
+	merged := m.(*github.RepositoryCommit)
+	if merged == nil || merged.GetCommit().GetTree().GetSHA() == "" {
+		return "", fmt.Errorf("commit %s has no changes to apply in repo %s/%s", sha, orgLogin, repoName)
+	}
+	mergedTree := merged.GetCommit().GetTree().GetSHA()
+
+	// now create the real commit, with the merged content on top of what we're picking onto
+	p, _, err := tc.ThrottledCall(func(client *github.Client) (interface{}, *github.Response, error) {
+		return client.Git.CreateCommit(context, orgLogin, repoName, &github.Commit{
+			Message: commit.Message,
+			Author:  commit.Author,
+			Tree:    &github.Tree{SHA: &mergedTree},
+			Parents: []github.Commit{{SHA: &onto}},
+		})
+	})
+	if err != nil {
+		return "", fmt.Errorf("unable to create commit in repo %s/%s: %v", orgLogin, repoName, err)
+	}
+
+	return p.(*github.Commit).GetSHA(), nil
+}
+
+// setBranch points a branch at a commit, creating the branch the first time around. A branch that already
+// exists when it should be created is left over from an earlier attempt, and gets reused.
+func (tc *ThrottledClient) setBranch(context context.Context, orgLogin string, repoName string, branch string, sha string,
+	created *bool,
+) error {
+	ref := "refs/heads/" + branch
+	if !*created {
+		_, resp, err := tc.ThrottledCall(func(client *github.Client) (interface{}, *github.Response, error) {
+			return client.Git.CreateRef(context, orgLogin, repoName, &github.Reference{
+				Ref:    &ref,
+				Object: &github.GitObject{SHA: &sha},
+			})
+		})
+		if err == nil {
+			*created = true
+			return nil
+		} else if resp == nil || resp.StatusCode != http.StatusUnprocessableEntity {
+			return fmt.Errorf("unable to create branch %s in repo %s/%s: %v", branch, orgLogin, repoName, err)
+		}
+
+		log.Infof("Reusing existing branch %s in repo %s/%s", branch, orgLogin, repoName)
+		*created = true
+	}
+
+	if _, _, err := tc.ThrottledCall(func(client *github.Client) (interface{}, *github.Response, error) {
+		return client.Git.UpdateRef(context, orgLogin, repoName, &github.Reference{
+			Ref:    &ref,
+			Object: &github.GitObject{SHA: &sha},
+		}, true)
+	}); err != nil {
+		return fmt.Errorf("unable to update branch %s in repo %s/%s: %v", branch, orgLogin, repoName, err)
+	}
+
+	return nil
+}
+
+// LandedCommits returns the commits a merged PR added to its base branch, oldest first. A PR merged with a merge
+// commit or squashed is represented by a single commit, while a rebased PR landed as a copy of each of its commits.
+func (tc *ThrottledClient) LandedCommits(context context.Context, orgLogin string, repoName string, pr *github.PullRequest) ([]string, error) {
+	sha := pr.GetMergeCommitSHA()
+	if sha == "" {
+		return nil, fmt.Errorf("PR %d in repo %s/%s has no merge commit", pr.GetNumber(), orgLogin, repoName)
+	}
+
+	if pr.GetCommits() <= 1 {
+		return []string{sha}, nil
+	}
+
+	// the messages of the PR's own commits, which a rebase preserves
+	var messages []string
+	opt := &github.ListOptions{PerPage: 100}
+	for {
+		c, resp, err := tc.ThrottledCall(func(client *github.Client) (interface{}, *github.Response, error) {
+			return client.PullRequests.ListCommits(context, orgLogin, repoName, pr.GetNumber(), opt)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("unable to list commits of PR %d in repo %s/%s: %v", pr.GetNumber(), orgLogin, repoName, err)
+		}
+
+		for _, rc := range c.([]*github.RepositoryCommit) {
+			messages = append(messages, rc.GetCommit().GetMessage())
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+
+	// walk back from the merge commit as many commits as the PR has, to see whether they're copies of the PR's commits
+	landed := make([]string, len(messages))
+	cur := sha
+	for i := len(messages) - 1; i >= 0; i-- {
+		c, _, err := tc.ThrottledCall(func(client *github.Client) (interface{}, *github.Response, error) {
+			return client.Git.GetCommit(context, orgLogin, repoName, cur)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("unable to get commit %s in repo %s/%s: %v", cur, orgLogin, repoName, err)
+		}
+		commit := c.(*github.Commit)
+
+		if len(commit.Parents) != 1 || strings.TrimSpace(commit.GetMessage()) != strings.TrimSpace(messages[i]) {
+			// a merge commit, or a squash followed by unrelated history
+			return []string{sha}, nil
+		}
+
+		landed[i] = cur
+		cur = commit.Parents[0].GetSHA()
+	}
+
+	return landed, nil
+}
+
+// deleteBranch does a best-effort removal of a branch
+func (tc *ThrottledClient) deleteBranch(context context.Context, orgLogin string, repoName string, branch string) {
+	if _, err := tc.ThrottledCallNoResult(func(client *github.Client) (*github.Response, error) {
+		return client.Git.DeleteRef(context, orgLogin, repoName, "heads/"+branch)
+	}); err != nil {
+		log.Warnf("Unable to delete branch %s in repo %s/%s: %v", branch, orgLogin, repoName, err)
+	}
+}
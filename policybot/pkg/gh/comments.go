@@ -31,16 +31,13 @@ type MessageTemplate struct {
 	Author string
 }
 
-// AddOrReplaceBotComment injects a comment from the bot into an issue or PR. It first removes any other
-// comment it finds with the same signature
-func (tc *ThrottledClient) AddOrReplaceBotComment(context context.Context, orgLogin string, repoName string, number int, userName string, message string,
-	signature string,
-) error {
+// ExpandMessage evaluates a message template, as used for bot comments
+func ExpandMessage(orgLogin string, repoName string, userName string, message string) (string, error) {
 	var b bytes.Buffer
 
 	tmpl, err := template.New("message").Parse(message)
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	err = tmpl.Execute(&b, MessageTemplate{
@@ -48,11 +45,29 @@ func (tc *ThrottledClient) AddOrReplaceBotComment(context context.Context, orgLo
 		Repo:   repoName,
 		Author: userName,
 	})
+	if err != nil {
+		return "", err
+	}
+
+	return b.String(), nil
+}
+
+// EscapeTemplate prevents text from being interpreted by the comment template engine
+func EscapeTemplate(s string) string {
+	return strings.ReplaceAll(s, "{{", "{{`{{`}}")
+}
+
+// AddOrReplaceBotComment injects a comment from the bot into an issue or PR. It first removes any other
+// comment it finds with the same signature
+func (tc *ThrottledClient) AddOrReplaceBotComment(context context.Context, orgLogin string, repoName string, number int, userName string, message string,
+	signature string,
+) error {
+	msg, err := ExpandMessage(orgLogin, repoName, userName, message)
 	if err != nil {
 		return err
 	}
 
-	msg := b.String() + signature
+	msg += signature
 	pc := &github.IssueComment{
 		Body: &msg,
 	}
@@ -19,6 +19,7 @@ import (
 
 	"github.com/google/go-github/v26/github"
 
+	"istio.io/bots/policybot/pkg/issueform"
 	"istio.io/bots/policybot/pkg/storage"
 )
 
@@ -47,9 +48,35 @@ func ConvertIssue(orgLogin string, repoName string, issue *github.Issue) *storag
 		State:       issue.GetState(),
 		Author:      issue.GetUser().GetLogin(),
 		Assignees:   assignees,
+		Fields:      convertIssueFields(orgLogin, repoName, issue),
 	}
 }
 
+// Maps from the body of a GitHub issue to storage issue fields.
+func convertIssueFields(orgLogin string, repoName string, issue *github.Issue) []*storage.IssueField {
+	result := make([]*storage.IssueField, 0)
+	seen := make(map[string]bool)
+	for _, f := range issueform.Parse(issue.GetBody()) {
+		key := issueform.Key(f.Heading)
+		value := issueform.Plain(f.Value)
+		if key == "" || value == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		result = append(result, &storage.IssueField{
+			OrgLogin:    orgLogin,
+			RepoName:    repoName,
+			IssueNumber: int64(issue.GetNumber()),
+			FieldName:   key,
+			Heading:     f.Heading,
+			Value:       value,
+		})
+	}
+
+	return result
+}
+
 // Maps from a GitHub issue comment to a storage issue comment.
 func ConvertIssueComment(orgLogin string, repoName string, issueNumber int, issueComment *github.IssueComment) *storage.IssueComment {
 	return &storage.IssueComment{
@@ -142,6 +169,12 @@ func ConvertPullRequest(orgLogin string, repoName string, pr *github.PullRequest
 	base := pr.GetBase().GetLabel()
 	branch := base[strings.Index(base, ":")+1:]
 
+	var milestone *string
+	if pr.Milestone != nil {
+		title := pr.GetMilestone().GetTitle()
+		milestone = &title
+	}
+
 	return &storage.PullRequest{
 		OrgLogin:           orgLogin,
 		RepoName:           repoName,
@@ -161,6 +194,7 @@ func ConvertPullRequest(orgLogin string, repoName string, pr *github.PullRequest
 		HeadCommit:         sha,
 		BranchName:         branch,
 		Merged:             pr.GetMerged(),
+		Milestone:          milestone,
 	}
 }
 
@@ -298,6 +298,39 @@ func (tc *ThrottledClient) FetchPullRequests(context context.Context, orgLogin s
 	return nil
 }
 
+// FetchOpenPullRequests fetches the open PRs in a repo, limited to those targeting the given base branch unless it's empty.
+func (tc *ThrottledClient) FetchOpenPullRequests(context context.Context, orgLogin string, repoName string, base string,
+	cb func([]*github.PullRequest) error) error {
+	opt := &github.PullRequestListOptions{
+		State: "open",
+		Base:  base,
+		ListOptions: github.ListOptions{
+			PerPage: 100,
+		},
+	}
+
+	for {
+		prs, resp, err := tc.ThrottledCall(func(client *github.Client) (interface{}, *github.Response, error) {
+			return client.PullRequests.List(context, orgLogin, repoName, opt)
+		})
+		if err != nil {
+			return fmt.Errorf("unable to list open pull requests in repo %s/%s: %v", orgLogin, repoName, err)
+		}
+
+		if err := cb(prs.([]*github.PullRequest)); err != nil {
+			return err
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+
+		opt.ListOptions.Page = resp.NextPage
+	}
+
+	return nil
+}
+
 func (tc *ThrottledClient) FetchReviews(context context.Context, orgLogin string, repoName string, prNumber int,
 	cb func([]*github.PullRequestReview) error,
 ) error {
@@ -0,0 +1,136 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package issueform extracts structured fields from the bodies of issues, as produced by GitHub issue forms
+// or by Markdown issue templates.
+package issueform
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Field is a section of an issue body, introduced by a heading.
+type Field struct {
+	Heading string
+	Value   string
+}
+
+// GitHub issue forms render fields that were left empty with this text
+const noResponse = "_No response_"
+
+var (
+	headingRegexp  = regexp.MustCompile(`^ {0,3}#{1,6}\s+(.+?)\s*#*\s*$`)
+	boldRegexp     = regexp.MustCompile(`^\*\*([^*]+?)\*\*:?\s*$`)
+	commentRegexp  = regexp.MustCompile(`(?s)<!--.*?-->`)
+	fenceRegexp    = regexp.MustCompile("^ {0,3}(```|~~~)")
+	checkboxRegexp = regexp.MustCompile(`(?i)^\s*[-*]\s+\[([ x])\]\s+(.*)$`)
+	keyRegexp      = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+// Parse splits an issue body into fields. Headings are either Markdown headings or lines holding only bold
+// text, and text inside fenced code blocks is never treated as a heading. Any text before the first heading
+// ends up in a field with an empty heading. HTML comments, which templates use for instructions, are dropped,
+// as are the placeholders issue forms use for empty fields.
+func Parse(body string) []Field {
+	body = strings.ReplaceAll(body, "\r\n", "\n")
+	body = commentRegexp.ReplaceAllString(body, "")
+
+	var fields []Field
+	current := Field{}
+	var value []string
+	inFence := false
+
+	flush := func() {
+		current.Value = strings.TrimSpace(strings.Join(value, "\n"))
+		if current.Value == noResponse {
+			current.Value = ""
+		}
+
+		if current.Heading != "" || current.Value != "" {
+			fields = append(fields, current)
+		}
+	}
+
+	for _, line := range strings.Split(body, "\n") {
+		if fenceRegexp.MatchString(line) {
+			inFence = !inFence
+		}
+
+		if !inFence {
+			m := headingRegexp.FindStringSubmatch(line)
+			if m == nil {
+				m = boldRegexp.FindStringSubmatch(line)
+			}
+
+			if m != nil {
+				flush()
+				current = Field{Heading: strings.TrimSpace(m[1])}
+				value = nil
+				continue
+			}
+		}
+
+		value = append(value, line)
+	}
+	flush()
+
+	return fields
+}
+
+// Find returns the first field whose heading matches the given expression.
+func Find(fields []Field, heading *regexp.Regexp) (Field, bool) {
+	for _, f := range fields {
+		if heading.MatchString(f.Heading) {
+			return f, true
+		}
+	}
+
+	return Field{}, false
+}
+
+// Checked returns the labels of the ticked checkboxes in a field's value.
+func Checked(value string) []string {
+	var result []string
+	for _, line := range strings.Split(value, "\n") {
+		if m := checkboxRegexp.FindStringSubmatch(line); m != nil && m[1] != " " {
+			result = append(result, strings.TrimSpace(m[2]))
+		}
+	}
+
+	return result
+}
+
+// Key turns a heading into a short identifier, such as "affected-product-area" for "Affected product area".
+func Key(heading string) string {
+	return strings.Trim(keyRegexp.ReplaceAllString(strings.ToLower(heading), "-"), "-")
+}
+
+// Plain returns a field's value without the Markdown decorations issue forms add. Code fences are dropped and
+// checkbox lists are reduced to their ticked entries, one per line.
+func Plain(value string) string {
+	var lines []string
+	for _, line := range strings.Split(value, "\n") {
+		if checkboxRegexp.MatchString(line) {
+			// anything around the checkboxes is instructions
+			return strings.Join(Checked(value), "\n")
+		}
+
+		if !fenceRegexp.MatchString(line) {
+			lines = append(lines, line)
+		}
+	}
+
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
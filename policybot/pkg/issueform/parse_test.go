@@ -0,0 +1,126 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package issueform
+
+import (
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		name     string
+		body     string
+		expected []Field
+	}{
+		{
+			"issue form",
+			"### Bug Description\n\nThings broke\n\n### Steps\n\n_No response_\n\n### Version\n\n```\n# not a heading\n1.4\n```\n",
+			[]Field{
+				{"Bug Description", "Things broke"},
+				{"Steps", ""},
+				{"Version", "```\n# not a heading\n1.4\n```"},
+			},
+		},
+		{
+			"markdown template",
+			"Intro text\r\n\r\n**Bug description**\r\n<!-- describe the bug -->\r\nIt crashed\r\n\r\n**Expected behavior**\r\n\r\n## Environment ##\r\nk8s 1.15",
+			[]Field{
+				{"", "Intro text"},
+				{"Bug description", "It crashed"},
+				{"Expected behavior", ""},
+				{"Environment", "k8s 1.15"},
+			},
+		},
+		{
+			"no headings",
+			"Just some text",
+			[]Field{{"", "Just some text"}},
+		},
+		{
+			"empty",
+			"",
+			nil,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if diff := cmp.Diff(c.expected, Parse(c.body)); diff != "" {
+				t.Errorf("Unexpected fields (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestParseIssueTemplate(t *testing.T) {
+	body, err := os.ReadFile("../../handlers/githubwebhook/cleaner/testdata/issue-default.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fields := Parse(string(body))
+
+	f, ok := Find(fields, regexp.MustCompile("(?i)^version$"))
+	if !ok || f.Value == "" {
+		t.Errorf("Expected to find the version, got %+v", fields)
+	}
+
+	f, ok = Find(fields, regexp.MustCompile("(?i)product area"))
+	if !ok {
+		t.Fatalf("Expected to find the product area, got %+v", fields)
+	}
+
+	if diff := cmp.Diff([]string{"Security", "Virtual Machine"}, Checked(f.Value)); diff != "" {
+		t.Errorf("Unexpected checked areas (-want +got):\n%s", diff)
+	}
+}
+
+func TestKey(t *testing.T) {
+	cases := map[string]string{
+		"Affected product area":              "affected-product-area",
+		"Is this the right place to submit?": "is-this-the-right-place-to-submit",
+		"  Version  ":                        "version",
+		"Kubernetes/OS Platform (e.g. GKE)":  "kubernetes-os-platform-e-g-gke",
+		"":                                   "",
+	}
+
+	for heading, expected := range cases {
+		if got := Key(heading); got != expected {
+			t.Errorf("Key(%q) = %q, expected %q", heading, got, expected)
+		}
+	}
+}
+
+func TestPlain(t *testing.T) {
+	cases := []struct {
+		value    string
+		expected string
+	}{
+		{"```prose\n$ istioctl version\n1.5.0\n```", "$ istioctl version\n1.5.0"},
+		{"Pick any:\n- [ ] Docs\n- [x] Networking\n- [X] Security", "Networking\nSecurity"},
+		{"- [ ] Docs", ""},
+		{"plain text", "plain text"},
+	}
+
+	for _, c := range cases {
+		if got := Plain(c.value); got != c.expected {
+			t.Errorf("Plain(%q) = %q, expected %q", c.value, got, c.expected)
+		}
+	}
+}
@@ -304,3 +304,27 @@ func From(f func() (interface{}, error)) Pipeline {
 	}
 	return FromIter(x)
 }
+
+// FromSlice produces each element of items in order.
+func FromSlice(items []interface{}) Pipeline {
+	var i int
+	return From(func() (interface{}, error) {
+		if i >= len(items) {
+			return "", iterator.Done
+		}
+		i++
+		return items[i-1], nil
+	})
+}
+
+// ForEach calls f for every element of items, running up to parallelism calls at once. Unlike a plain
+// loop it does not stop at the first failure: every element is visited and all errors are returned
+// together as a multierror.
+func ForEach(ctx context.Context, items []interface{}, parallelism int, f func(item interface{}) error) error {
+	var result *multierror.Error
+	errorChan := FromSlice(items).WithContext(ctx).WithParallelism(parallelism).To(f).Go()
+	for err := range errorChan {
+		result = multierror.Append(result, err.Err())
+	}
+	return result.ErrorOrNil()
+}
@@ -21,6 +21,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -105,3 +106,41 @@ func TestNilErrors(t *testing.T) {
 	assert.Equal(t, errcount, 0)
 	assert.Equal(t, rescount, 4)
 }
+
+func TestForEach(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	var items []interface{}
+	for i := 0; i < 10; i++ {
+		items = append(items, i)
+	}
+
+	var visited, running, maxRunning int32
+	err := ForEach(ctx, items, 3, func(item interface{}) error {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			m := atomic.LoadInt32(&maxRunning)
+			if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		atomic.AddInt32(&visited, 1)
+		if item.(int)%4 == 0 {
+			return fmt.Errorf("item %d failed", item)
+		}
+		return nil
+	})
+
+	// every item is visited even though some fail, and all failures are reported
+	assert.Equal(t, visited, int32(10))
+	assert.Assert(t, maxRunning <= 3)
+	assert.ErrorContains(t, err, "3 errors occurred")
+	for _, want := range []string{"item 0 failed", "item 4 failed", "item 8 failed"} {
+		assert.ErrorContains(t, err, want)
+	}
+
+	assert.NilError(t, ForEach(ctx, nil, 3, func(interface{}) error { return errors.New("unexpected") }))
+}
@@ -0,0 +1,125 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package releasenotes understands the release note files that Istio PRs carry under releasenotes/notes.
+package releasenotes
+
+import (
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Note is the content of a single release note file.
+type Note struct {
+	APIVersion    string        `json:"apiVersion,omitempty"`
+	Kind          string        `json:"kind"`
+	Area          string        `json:"area"`
+	Issue         []interface{} `json:"issue,omitempty"` // issue numbers or URLs
+	Docs          []string      `json:"docs,omitempty"`
+	ReleaseNotes  []string      `json:"releaseNotes,omitempty"`
+	UpgradeNotes  []UpgradeNote `json:"upgradeNotes,omitempty"`
+	SecurityNotes []string      `json:"securityNotes,omitempty"`
+}
+
+// UpgradeNote describes something users need to be aware of when upgrading.
+type UpgradeNote struct {
+	Title   string `json:"title"`
+	Content string `json:"content"`
+}
+
+// The kinds and areas release notes can have, unless configured otherwise.
+var (
+	DefaultKinds = []string{"bug-fix", "feature", "promotion", "security-fix", "test"}
+	DefaultAreas = []string{"documentation", "extensibility", "installation", "istioctl", "security", "telemetry", "traffic-management"}
+)
+
+// Parse decodes a release note file. Unknown fields are reported as errors to catch typos.
+func Parse(b []byte) (*Note, error) {
+	n := &Note{}
+	if err := yaml.UnmarshalStrict(b, n); err != nil {
+		return nil, err
+	}
+
+	return n, nil
+}
+
+// Validate checks a note's content, returning a description of every problem found. When kinds
+// or areas are empty, the defaults are used.
+func Validate(n *Note, kinds []string, areas []string) []string {
+	if len(kinds) == 0 {
+		kinds = DefaultKinds
+	}
+
+	if len(areas) == 0 {
+		areas = DefaultAreas
+	}
+
+	var problems []string
+
+	if n.Kind == "" {
+		problems = append(problems, "the `kind` field is missing")
+	} else if !contains(kinds, n.Kind) {
+		problems = append(problems, fmt.Sprintf("`%s` is not a valid kind, expecting one of: %s", n.Kind, strings.Join(kinds, ", ")))
+	}
+
+	if n.Area == "" {
+		problems = append(problems, "the `area` field is missing")
+	} else if !contains(areas, n.Area) {
+		problems = append(problems, fmt.Sprintf("`%s` is not a valid area, expecting one of: %s", n.Area, strings.Join(areas, ", ")))
+	}
+
+	if len(n.ReleaseNotes) == 0 && len(n.UpgradeNotes) == 0 && len(n.SecurityNotes) == 0 {
+		problems = append(problems, "at least one of `releaseNotes`, `upgradeNotes`, or `securityNotes` must be supplied")
+	}
+
+	for i, rn := range n.ReleaseNotes {
+		if strings.TrimSpace(rn) == "" {
+			problems = append(problems, fmt.Sprintf("entry %d of `releaseNotes` is empty", i+1))
+		}
+	}
+
+	for i, un := range n.UpgradeNotes {
+		if strings.TrimSpace(un.Title) == "" {
+			problems = append(problems, fmt.Sprintf("entry %d of `upgradeNotes` has no title", i+1))
+		}
+
+		if strings.TrimSpace(un.Content) == "" {
+			problems = append(problems, fmt.Sprintf("entry %d of `upgradeNotes` has no content", i+1))
+		}
+	}
+
+	for i, sn := range n.SecurityNotes {
+		if strings.TrimSpace(sn) == "" {
+			problems = append(problems, fmt.Sprintf("entry %d of `securityNotes` is empty", i+1))
+		}
+	}
+
+	if n.Kind == "security-fix" && len(n.SecurityNotes) == 0 {
+		problems = append(problems, "notes of kind `security-fix` must include `securityNotes`")
+	}
+
+	return problems
+}
+
+func contains(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+
+	return false
+}
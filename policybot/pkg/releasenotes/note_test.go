@@ -0,0 +1,92 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package releasenotes
+
+import (
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	cases := []struct {
+		name     string
+		content  string
+		problems int
+	}{
+		{"valid", `
+apiVersion: release-notes/v2
+kind: bug-fix
+area: traffic-management
+issue:
+  - 12345
+  - https://github.com/istio/istio/issues/6789
+releaseNotes:
+  - |
+    **Fixed** an issue.
+`, 0},
+		{"upgrade only", `
+kind: feature
+area: installation
+upgradeNotes:
+  - title: Something changed
+    content: Adjust your settings.
+`, 0},
+		{"missing kind and area", `
+releaseNotes:
+  - Fixed it.
+`, 2},
+		{"bad kind", `
+kind: bugfix
+area: security
+releaseNotes:
+  - Fixed it.
+`, 1},
+		{"no notes", `
+kind: feature
+area: istioctl
+`, 1},
+		{"empty upgrade note", `
+kind: feature
+area: istioctl
+upgradeNotes:
+  - title: ""
+    content: ""
+`, 2},
+		{"security fix without security notes", `
+kind: security-fix
+area: security
+releaseNotes:
+  - Fixed it.
+`, 1},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			n, err := Parse([]byte(c.content))
+			if err != nil {
+				t.Fatalf("unable to parse: %v", err)
+			}
+
+			if problems := Validate(n, nil, nil); len(problems) != c.problems {
+				t.Errorf("got %d problems, expected %d: %v", len(problems), c.problems, problems)
+			}
+		})
+	}
+}
+
+func TestParseUnknownField(t *testing.T) {
+	if _, err := Parse([]byte("kind: feature\nreleaseNote:\n  - typo\n")); err == nil {
+		t.Error("expected an error for an unknown field")
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package report collects what a batch manager did during a single run, per repo, so that it
+// can be emitted as a structured summary once the run completes.
+package report
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Report summarizes a single run of a manager across repos. It is safe for concurrent use. Managers don't
+// stop at the first failure: each one is recorded in the report and the run goes on, with the error they
+// return aggregating the same failures.
+type Report struct {
+	Manager  string         `json:"manager"`
+	Start    time.Time      `json:"start"`
+	End      time.Time      `json:"end"`
+	Totals   map[string]int `json:"totals"`
+	Failures int            `json:"failures"`
+	Repos    []*RepoReport  `json:"repos"`
+
+	lock   sync.Mutex
+	byRepo map[string]*RepoReport
+}
+
+// RepoReport holds the counters and failures accumulated for a single repo. It is safe for concurrent use.
+type RepoReport struct {
+	Repo     string         `json:"repo"`
+	Counters map[string]int `json:"counters"`
+	Failures []string       `json:"failures,omitempty"`
+
+	lock sync.Mutex
+}
+
+// New starts a report for the named manager.
+func New(manager string) *Report {
+	return &Report{
+		Manager: manager,
+		Start:   time.Now().UTC(),
+		byRepo:  make(map[string]*RepoReport),
+	}
+}
+
+// Repo returns the report for the given org/repo, creating it on first use.
+func (r *Report) Repo(orgAndRepo string) *RepoReport {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	rr, ok := r.byRepo[orgAndRepo]
+	if !ok {
+		rr = &RepoReport{Repo: orgAndRepo, Counters: make(map[string]int)}
+		r.byRepo[orgAndRepo] = rr
+	}
+	return rr
+}
+
+// Add bumps the named counter by delta.
+func (rr *RepoReport) Add(counter string, delta int) {
+	rr.lock.Lock()
+	rr.Counters[counter] += delta
+	rr.lock.Unlock()
+}
+
+// Fail records a failure.
+func (rr *RepoReport) Fail(err error) {
+	rr.lock.Lock()
+	rr.Failures = append(rr.Failures, err.Error())
+	rr.lock.Unlock()
+}
+
+// Finish stamps the end of the run and computes the totals across repos.
+func (r *Report) Finish() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.End = time.Now().UTC()
+	r.Totals = make(map[string]int)
+	r.Failures = 0
+	r.Repos = r.Repos[:0]
+	for _, rr := range r.byRepo {
+		rr.lock.Lock()
+		for counter, n := range rr.Counters {
+			r.Totals[counter] += n
+		}
+		r.Failures += len(rr.Failures)
+		rr.lock.Unlock()
+		r.Repos = append(r.Repos, rr)
+	}
+
+	sort.Slice(r.Repos, func(i, j int) bool {
+		return r.Repos[i].Repo < r.Repos[j].Repo
+	})
+}
+
+// Write emits the report as indented JSON.
+func (r *Report) Write(w io.Writer) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
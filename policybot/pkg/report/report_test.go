@@ -0,0 +1,78 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestReport(t *testing.T) {
+	r := New("testmgr")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			rr := r.Repo(fmt.Sprintf("org/repo%d", i%2))
+			rr.Add("items", 1)
+			if i == 3 {
+				rr.Fail(errors.New("boom"))
+			}
+		}(i)
+	}
+	wg.Wait()
+	r.Finish()
+
+	var buf bytes.Buffer
+	if err := r.Write(&buf); err != nil {
+		t.Fatalf("unable to write report: %v", err)
+	}
+
+	var got struct {
+		Manager  string
+		Totals   map[string]int
+		Failures int
+		Repos    []struct {
+			Repo     string
+			Counters map[string]int
+			Failures []string
+		}
+	}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("unable to parse report: %v\n%s", err, buf.String())
+	}
+
+	if got.Manager != "testmgr" || got.Totals["items"] != 10 || got.Failures != 1 {
+		t.Errorf("unexpected summary: %+v", got)
+	}
+
+	if len(got.Repos) != 2 || got.Repos[0].Repo != "org/repo0" || got.Repos[1].Repo != "org/repo1" {
+		t.Fatalf("unexpected repos: %+v", got.Repos)
+	}
+
+	if got.Repos[0].Counters["items"] != 5 || len(got.Repos[0].Failures) != 0 {
+		t.Errorf("unexpected repo0: %+v", got.Repos[0])
+	}
+
+	if got.Repos[1].Counters["items"] != 5 || len(got.Repos[1].Failures) != 1 || got.Repos[1].Failures[0] != "boom" {
+		t.Errorf("unexpected repo1: %+v", got.Repos[1])
+	}
+}
@@ -0,0 +1,197 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resultgatherer
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	store "istio.io/bots/policybot/pkg/storage"
+)
+
+// the status of a test case, as recorded in TestCaseResults
+const (
+	CasePassed  = "passed"
+	CaseFailed  = "failed"
+	CaseErrored = "error"
+	CaseSkipped = "skipped"
+)
+
+// failure messages are truncated to keep rows small, the full output is in the run's artifacts
+const maxFailureMessageLength = 4096
+
+// junitSuites is the root of a JUnit XML report, which can either be a <testsuites> element or a lone <testsuite>.
+type junitSuites struct {
+	XMLName xml.Name
+	Suites  []junitSuite `xml:"testsuite"`
+	Cases   []junitCase  `xml:"testcase"`
+	Name    string       `xml:"name,attr"`
+}
+
+type junitSuite struct {
+	Name   string       `xml:"name,attr"`
+	Suites []junitSuite `xml:"testsuite"`
+	Cases  []junitCase  `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure"`
+	Error     *junitMessage `xml:"error"`
+	Skipped   *junitMessage `xml:"skipped"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Body    string `xml:",chardata"`
+}
+
+// isJUnitArtifact tells whether an artifact of a test run is a JUnit XML report, going by the usual junit*.xml naming.
+func isJUnitArtifact(name string) bool {
+	base := path.Base(name)
+	return strings.HasPrefix(base, "junit") && strings.HasSuffix(base, ".xml")
+}
+
+// parseJUnit reads the test cases out of a JUnit XML report. The package of a case is its class name, falling back
+// to the name of the suite holding it, as go-junit-report names suites after packages.
+func parseJUnit(r io.Reader) ([]*store.TestCaseResult, error) {
+	var root junitSuites
+	if err := xml.NewDecoder(r).Decode(&root); err != nil {
+		return nil, fmt.Errorf("unable to parse JUnit report: %v", err)
+	}
+
+	var cases []*store.TestCaseResult
+	switch root.XMLName.Local {
+	case "testsuites":
+		for _, s := range root.Suites {
+			cases = appendSuite(cases, s)
+		}
+	case "testsuite":
+		cases = appendSuite(cases, junitSuite{Name: root.Name, Suites: root.Suites, Cases: root.Cases})
+	default:
+		return nil, fmt.Errorf("unexpected root element <%s> in JUnit report", root.XMLName.Local)
+	}
+
+	return cases, nil
+}
+
+func appendSuite(cases []*store.TestCaseResult, s junitSuite) []*store.TestCaseResult {
+	for _, c := range s.Cases {
+		tc := &store.TestCaseResult{
+			PackageName: c.ClassName,
+			CaseName:    c.Name,
+			Status:      CasePassed,
+		}
+
+		if tc.PackageName == "" {
+			tc.PackageName = s.Name
+		}
+
+		if d, err := strconv.ParseFloat(c.Time, 64); err == nil {
+			tc.Duration = d
+		}
+
+		var msg *junitMessage
+		switch {
+		case c.Failure != nil:
+			tc.Status = CaseFailed
+			msg = c.Failure
+		case c.Error != nil:
+			tc.Status = CaseErrored
+			msg = c.Error
+		case c.Skipped != nil:
+			tc.Status = CaseSkipped
+		}
+
+		if msg != nil {
+			m := failureMessage(msg)
+			tc.FailureMessage = &m
+		}
+
+		cases = append(cases, tc)
+	}
+
+	for _, child := range s.Suites {
+		cases = appendSuite(cases, child)
+	}
+
+	return cases
+}
+
+// failureMessage prefers the body of a failure, which holds the test's output, over its often generic message.
+func failureMessage(msg *junitMessage) string {
+	m := strings.TrimSpace(msg.Body)
+	if m == "" {
+		m = strings.TrimSpace(msg.Message)
+	}
+
+	// keep the end of the output, where the failure is, without splitting a character
+	if len(m) > maxFailureMessageLength {
+		start := len(m) - maxFailureMessageLength
+		for start < len(m) && !utf8.RuneStart(m[start]) {
+			start++
+		}
+		m = m[start:]
+	}
+
+	return m
+}
+
+// mergeCases folds the cases of several reports into one result per case. A case run more than once, such as
+// with -count or across reports, keeps its worst outcome and the sum of its durations.
+func mergeCases(cases []*store.TestCaseResult) []*store.TestCaseResult {
+	type key struct{ pkg, name string }
+
+	index := make(map[key]int)
+	var result []*store.TestCaseResult
+	for _, tc := range cases {
+		k := key{tc.PackageName, tc.CaseName}
+		i, ok := index[k]
+		if !ok {
+			index[k] = len(result)
+			result = append(result, tc)
+			continue
+		}
+
+		existing := result[i]
+		existing.Duration += tc.Duration
+		if statusRank(tc.Status) > statusRank(existing.Status) {
+			existing.Status = tc.Status
+			existing.FailureMessage = tc.FailureMessage
+		}
+	}
+
+	return result
+}
+
+func statusRank(status string) int {
+	switch status {
+	case CaseErrored:
+		return 3
+	case CaseFailed:
+		return 2
+	case CasePassed:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resultgatherer
+
+import (
+	"strings"
+	"testing"
+)
+
+const goJUnitReport = `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites>
+	<testsuite tests="3" failures="1" time="1.500" name="istio.io/istio/pilot/pkg/model">
+		<testcase classname="istio.io/istio/pilot/pkg/model" name="TestServiceKey" time="0.010"></testcase>
+		<testcase classname="istio.io/istio/pilot/pkg/model" name="TestPushContext" time="1.200">
+			<failure message="Failed" type="">push_context_test.go:42: timed out waiting for push</failure>
+		</testcase>
+		<testcase classname="istio.io/istio/pilot/pkg/model" name="TestWindows" time="0.000">
+			<skipped message="not on linux"></skipped>
+		</testcase>
+	</testsuite>
+	<testsuite tests="1" errors="1" name="istio.io/istio/pkg/kube">
+		<testcase name="TestClient" time="0.300">
+			<error message="panic: nil map"></error>
+		</testcase>
+	</testsuite>
+</testsuites>`
+
+const loneSuite = `<testsuite name="pilot">
+	<testcase classname="pilot" name="TestFlaky" time="1.0"><failure>first try</failure></testcase>
+	<testcase classname="pilot" name="TestFlaky" time="2.0"></testcase>
+</testsuite>`
+
+func TestParseJUnit(t *testing.T) {
+	cases, err := parseJUnit(strings.NewReader(goJUnitReport))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []struct {
+		pkg, name, status, message string
+		duration                   float64
+	}{
+		{"istio.io/istio/pilot/pkg/model", "TestServiceKey", CasePassed, "", 0.01},
+		{"istio.io/istio/pilot/pkg/model", "TestPushContext", CaseFailed, "push_context_test.go:42: timed out waiting for push", 1.2},
+		{"istio.io/istio/pilot/pkg/model", "TestWindows", CaseSkipped, "", 0},
+		{"istio.io/istio/pkg/kube", "TestClient", CaseErrored, "panic: nil map", 0.3},
+	}
+
+	if len(cases) != len(want) {
+		t.Fatalf("got %d cases, want %d", len(cases), len(want))
+	}
+
+	for i, w := range want {
+		c := cases[i]
+		if c.PackageName != w.pkg || c.CaseName != w.name || c.Status != w.status || c.Duration != w.duration {
+			t.Errorf("case %d: got %s %s %s %v, want %s %s %s %v", i, c.PackageName, c.CaseName, c.Status, c.Duration,
+				w.pkg, w.name, w.status, w.duration)
+		}
+
+		message := ""
+		if c.FailureMessage != nil {
+			message = *c.FailureMessage
+		}
+		if message != w.message {
+			t.Errorf("case %d: got message %q, want %q", i, message, w.message)
+		}
+	}
+}
+
+func TestParseJUnitLoneSuite(t *testing.T) {
+	cases, err := parseJUnit(strings.NewReader(loneSuite))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// the retried case keeps its failure and the time spent on both attempts
+	cases = mergeCases(cases)
+	if len(cases) != 1 {
+		t.Fatalf("got %d cases, want 1", len(cases))
+	}
+
+	c := cases[0]
+	if c.Status != CaseFailed || c.Duration != 3 || c.FailureMessage == nil || *c.FailureMessage != "first try" {
+		t.Errorf("got %s %v %v, want a failure taking 3s", c.Status, c.Duration, c.FailureMessage)
+	}
+}
+
+func TestParseJUnitMalformed(t *testing.T) {
+	for _, report := range []string{`<testsuites><testsuite name="truncated">`, `<html></html>`} {
+		if _, err := parseJUnit(strings.NewReader(report)); err == nil {
+			t.Errorf("expected an error parsing %q", report)
+		}
+	}
+}
+
+func TestIsJUnitArtifact(t *testing.T) {
+	for name, want := range map[string]bool{
+		"pr-logs/pull/istio_istio/1/unit-tests/2/artifacts/junit.xml":         true,
+		"pr-logs/pull/istio_istio/1/unit-tests/2/artifacts/junit_pilot.xml":   true,
+		"pr-logs/pull/istio_istio/1/unit-tests/2/artifacts/junit/report.xml":  false,
+		"pr-logs/pull/istio_istio/1/unit-tests/2/artifacts/coverage.xml":      false,
+		"pr-logs/pull/istio_istio/1/unit-tests/2/artifacts/junit-output.json": false,
+	} {
+		if got := isJUnitArtifact(name); got != want {
+			t.Errorf("isJUnitArtifact(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
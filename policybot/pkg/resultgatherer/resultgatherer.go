@@ -18,13 +18,12 @@
 package resultgatherer
 
 import (
-	"bufio"
+	"bytes"
 	"context"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
-	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -34,6 +33,7 @@ import (
 
 	"istio.io/bots/policybot/pkg/blobstorage"
 	pipelinetwo "istio.io/bots/policybot/pkg/pipeline"
+	"istio.io/bots/policybot/pkg/signatures"
 	store "istio.io/bots/policybot/pkg/storage"
 )
 
@@ -100,6 +100,9 @@ type TestResultGatherer struct {
 	BucketName       string
 	PreSubmitPrefix  string
 	PostSubmitPrefix string
+
+	// Signatures recognize the known causes of failed test runs
+	Signatures []*signatures.Signature
 }
 
 func (trg *TestResultGatherer) getRepoPrPath(orgLogin string, repoName string) string {
@@ -248,40 +251,82 @@ func (trg *TestResultGatherer) getInformationFromYamlFile(ctx context.Context, p
 	return &suiteOutcome, nil
 }
 
-var knownSignatures map[string]map[string]string
-
-// = {
-// 	"build-log.txt": {
-// 		"error parsing HTTP 408 response body": "",
-// 		"failed to get a Boskos resource": "",
-// 		"recipe for target '.*docker.*' failed": "",
-// 		"Entrypoint received interrupt: terminated": "",
-// 		"release istio failed: Service \"istio-ingressgateway\" is invalid: spec\\.ports\\[\\d\\]\\.nodePort\\: Invalid value\\:": "",
-// 		"The connection to the server \\d{1,3}\\.\\d{1,3}\\.\\d{1,3}\\.\\d{1,3}\\ was refused - did you specify the right host or port\\?": "",
-// 		"gzip: stdin: unexpected end of file": "",
-// 		"Process did not finish before": "",
-// 		"No cluster named ": "boskos refers to non-existent cluster or project"
-// 		"API Server failed to come up": "",
-// 	}
-// }
-
-func (trg *TestResultGatherer) getEnvironmentalSignatures(ctx context.Context, testRun string) (result []string) {
+// getFailureSignatures matches the output of a failed test run against the signature catalog. When no signature
+// matches, it returns the excerpt of the run's output that describes the failure instead, so the failure can be
+// clustered with others like it.
+func (trg *TestResultGatherer) getFailureSignatures(ctx context.Context, testRun string) (names []string, excerpt string) {
 	bucket := trg.getBucket()
-	for filename, sigmap := range knownSignatures {
-		r, err := bucket.Reader(ctx, testRun+filename)
+
+	files := signatures.Files(trg.Signatures)
+	hasDefault := false
+	for _, f := range files {
+		hasDefault = hasDefault || f == signatures.DefaultFile
+	}
+	if !hasDefault {
+		files = append(files, signatures.DefaultFile)
+	}
+
+	output := make(map[string][]byte, len(files))
+	for _, f := range files {
+		r, err := bucket.Reader(ctx, testRun+f)
 		if err != nil {
 			continue
 		}
-		var signatures []string
-		var names []string
-		for signature, name := range sigmap {
-			signatures = append(signatures, signature)
-			names = append(names, name)
+		b, err := readTail(r, maxOutputBytes)
+		_ = r.Close()
+		if err != nil {
+			continue
 		}
-		foo := getSignature(r, signatures)
-		result = append(result, names[foo])
+		output[f] = b
 	}
-	return result
+
+	names = signatures.Match(trg.Signatures, output)
+	if len(names) == 0 {
+		if log, ok := output[signatures.DefaultFile]; ok {
+			excerpt = signatures.Excerpt(log)
+		}
+	}
+
+	return names, excerpt
+}
+
+// maxOutputBytes bounds how much of the end of a test run's output is searched for failure signatures. Build logs
+// can be huge, while the failure is normally reported near their end.
+const maxOutputBytes = 8 << 20
+
+// readTail reads everything from r but only keeps the last n bytes, starting at a line boundary when cut
+func readTail(r io.Reader, n int) ([]byte, error) {
+	buf := make([]byte, 0, 2*n)
+	chunk := make([]byte, 64*1024)
+	cut := false
+	for {
+		c, err := r.Read(chunk)
+		buf = append(buf, chunk[:c]...)
+		if len(buf) > 2*n {
+			// keep the buffer from growing by only moving data once it holds twice what's needed
+			buf = append(buf[:0], buf[len(buf)-n:]...)
+			cut = true
+		}
+
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(buf) > n {
+		buf = buf[len(buf)-n:]
+		cut = true
+	}
+
+	if cut {
+		if i := bytes.IndexByte(buf, '\n'); i >= 0 {
+			buf = buf[i+1:]
+		}
+	}
+
+	return buf, nil
 }
 
 func (trg *TestResultGatherer) getTestRunArtifacts(ctx context.Context, testRun string) ([]string, error) {
@@ -293,6 +338,32 @@ func (trg *TestResultGatherer) getTestRunArtifacts(ctx context.Context, testRun
 	return artifacts, err
 }
 
+// getTestCases reads the test cases out of a test run's JUnit XML artifacts. Reports that can't be read or parsed
+// are skipped, as a run killed midway often leaves a truncated report behind.
+func (trg *TestResultGatherer) getTestCases(ctx context.Context, artifacts []string) []*store.TestCaseResult {
+	bucket := trg.getBucket()
+
+	var cases []*store.TestCaseResult
+	for _, a := range artifacts {
+		if !isJUnitArtifact(a) {
+			continue
+		}
+
+		r, err := bucket.Reader(ctx, a)
+		if err != nil {
+			continue
+		}
+		c, err := parseJUnit(r)
+		_ = r.Close()
+		if err != nil {
+			continue
+		}
+		cases = append(cases, c...)
+	}
+
+	return mergeCases(cases)
+}
+
 // getManyResults function return the status of test passing, clone failure, sha number, base sha for each test
 // run under each test suite for the given pr.
 // Client: client used to get buckets and objects from google cloud storage.
@@ -445,14 +516,20 @@ func (trg *TestResultGatherer) GetTestResult(ctx context.Context, testName strin
 	}
 	testResult.HasArtifacts = len(artifacts) != 0
 	testResult.Artifacts = artifacts
+	testResult.TestCases = trg.getTestCases(ctx, artifacts)
 
 	if testResult.Sha == nil {
 		testResult.Sha = []byte{}
 	}
 
-	if !testResult.TestPassed && !testResult.HasArtifacts {
-		// this is almost certainly an environmental failure, check for known sigs
-		testResult.Signatures = trg.getEnvironmentalSignatures(ctx, testRun)
+	if !testResult.TestPassed && testResult.Result != "ABORTED" {
+		var excerpt string
+		testResult.Signatures, excerpt = trg.getFailureSignatures(ctx, testRun)
+		if excerpt != "" {
+			fingerprint := signatures.Fingerprint(excerpt)
+			testResult.FailureExcerpt = &excerpt
+			testResult.FailureFingerprint = &fingerprint
+		}
 	}
 	return testResult, err
 }
@@ -586,9 +663,8 @@ func (trg *TestResultGatherer) GetPostSubmitTestResult(ctx context.Context, test
 	testResult.HasArtifacts = len(artifacts) != 0
 	testResult.Artifacts = artifacts
 
-	if !testResult.TestPassed && !testResult.HasArtifacts {
-		// this is almost certainly an environmental failure, check for known sigs
-		testResult.Signatures = trg.getEnvironmentalSignatures(ctx, testRun)
+	if !testResult.TestPassed && testResult.Result != "ABORTED" {
+		testResult.Signatures, _ = trg.getFailureSignatures(ctx, testRun)
 	}
 
 	testResult.OrgLogin = orgLogin
@@ -668,27 +744,3 @@ func (trg *TestResultGatherer) GetAllPostSubmitTestChan(ctx context.Context) pip
 			return i, nil
 		})
 }
-
-// if any pattern is found in the object, return it's index
-// if no pattern is found, return -1
-func getSignature(r io.Reader, patterns []string) int {
-	kdk := bufio.NewReader(r)
-	re := compileRegex(patterns)
-
-	indices := re.FindReaderSubmatchIndex(kdk)
-
-	// the array is effectively start/end tuples
-	// with the first two tuple representing the whole regex
-	// and outer parens.  indices[4] = pattern[0].start
-	for i := 4; i < len(indices); i += 2 {
-		if indices[i] > -1 {
-			return (i - 4) / 2
-		}
-	}
-	return -1
-}
-
-func compileRegex(patterns []string) *regexp.Regexp {
-	s := fmt.Sprintf("((%s))", strings.Join(patterns, ")|("))
-	return regexp.MustCompile(s)
-}
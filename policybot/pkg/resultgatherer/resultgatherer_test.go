@@ -18,6 +18,7 @@ import (
 	"context"
 	"encoding/hex"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -64,7 +65,7 @@ func TestTestResultGatherer(t *testing.T) {
 	}
 
 	start := time.Now()
-	testResultGatherer := TestResultGatherer{client, "istio-flakey-test", "pr-logs/pull/", ""}
+	testResultGatherer := TestResultGatherer{client, "istio-flakey-test", "pr-logs/pull/", "", nil}
 	testResults, err := testResultGatherer.CheckTestResultsForPr(context, "istio", "istio", prNum)
 	if err != nil {
 		t.Errorf("Expecting no error, got %v", err)
@@ -118,7 +119,7 @@ func TestPostSubmitTestResultGatherer(t *testing.T) {
 	}
 
 	start := time.Now()
-	testResultGatherer := TestResultGatherer{client, "istio-flakey-test", "", ""}
+	testResultGatherer := TestResultGatherer{client, "istio-flakey-test", "", "", nil}
 	postSubmitResults, err := testResultGatherer.CheckPostSubmitTestResults(context, "istio", "istio")
 	if err != nil {
 		t.Errorf("Expecting no error, got %v", err)
@@ -184,3 +185,25 @@ func BenchmarkNewWay(b *testing.B) {
 		// just waiting for channel to be closed
 	}
 }
+
+func TestReadTail(t *testing.T) {
+	cases := []struct {
+		name     string
+		input    string
+		n        int
+		expected string
+	}{
+		{"short", "a\nb\n", 10, "a\nb\n"},
+		{"exact", "a\nb\n", 4, "a\nb\n"},
+		{"cut at a line boundary", "first\nsecond\nthird\n", 10, "third\n"},
+		{"long", strings.Repeat("line\n", 100000) + "FAIL\n", 12, "line\nFAIL\n"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := readTail(strings.NewReader(c.input), c.n)
+			assert.NilError(t, err)
+			assert.Equal(t, string(got), c.expected)
+		})
+	}
+}
@@ -0,0 +1,366 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package rules implements the predicates used by configuration records to select issues and PRs.
+//
+// A rule is a tree of predicates expressed in YAML, for example:
+//
+//	all:
+//	  - any:
+//	      - title: "fix"
+//	      - body: "fixes #[0-9]+"
+//	  - glob: "pilot/**"
+//	  - not:
+//	      glob: "**/*_test.go"
+//	  - size:
+//	      min: 10
+//
+// Each node holds exactly one predicate. Regular expressions are case-insensitive, and the body is
+// matched in multi-line mode.
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"istio.io/bots/policybot/pkg/util"
+)
+
+// Rule is a node in a predicate tree.
+type Rule struct {
+	// All matches if every sub-rule matches
+	All []*Rule `json:"all,omitempty"`
+
+	// Any matches if at least one sub-rule matches
+	Any []*Rule `json:"any,omitempty"`
+
+	// Not matches if its sub-rule doesn't
+	Not *Rule `json:"not,omitempty"`
+
+	// Title, Body, and Author match against the corresponding text
+	Title  string `json:"title,omitempty"`  // regex
+	Body   string `json:"body,omitempty"`   // regex
+	Author string `json:"author,omitempty"` // regex
+
+	// Label matches if any of the labels match
+	Label string `json:"label,omitempty"` // regex
+
+	// File and Glob match if any of the affected files match
+	File string `json:"file,omitempty"` // regex
+	Glob string `json:"glob,omitempty"` // glob
+
+	// Branch matches against the base branch of PRs
+	Branch string `json:"branch,omitempty"` // regex
+
+	// Association matches if the author has one of the given relationships with the repo, using GitHub's
+	// values such as FIRST_TIME_CONTRIBUTOR, CONTRIBUTOR, or MEMBER
+	Association []string `json:"association,omitempty"`
+
+	// Size matches on the number of lines added and deleted by PRs
+	Size *SizeRange `json:"size,omitempty"`
+
+	// Kind matches issues or PRs, using the values "issue" or "pullrequest"
+	Kind string `json:"kind,omitempty"`
+}
+
+// SizeRange bounds the size of a PR, a zero bound is ignored.
+type SizeRange struct {
+	Min int `json:"min,omitempty"`
+	Max int `json:"max,omitempty"`
+}
+
+const (
+	KindIssue       = "issue"
+	KindPullRequest = "pullrequest"
+)
+
+type op int
+
+const (
+	opAll op = iota
+	opAny
+	opNot
+	opTitle
+	opBody
+	opAuthor
+	opLabel
+	opFile
+	opGlob
+	opBranch
+	opAssociation
+	opSize
+	opKind
+)
+
+// Expr is a compiled rule, ready to be evaluated.
+type Expr struct {
+	op       op
+	children []*Expr
+	re       *regexp.Regexp
+	pattern  string
+	values   []string
+	size     SizeRange
+}
+
+// All returns a rule that matches when all the given rules match, skipping nil rules.
+func All(rules ...*Rule) *Rule {
+	r := &Rule{All: []*Rule{}}
+	for _, rule := range rules {
+		if rule != nil {
+			r.All = append(r.All, rule)
+		}
+	}
+
+	return r
+}
+
+// Compile validates a rule and prepares it for evaluation. A nil rule matches everything.
+func Compile(r *Rule) (*Expr, error) {
+	if r == nil {
+		return &Expr{op: opAll}, nil
+	}
+
+	var exprs []*Expr
+	add := func(e *Expr) {
+		exprs = append(exprs, e)
+	}
+
+	if r.All != nil {
+		e := &Expr{op: opAll}
+		for _, child := range r.All {
+			c, err := Compile(child)
+			if err != nil {
+				return nil, err
+			}
+			e.children = append(e.children, c)
+		}
+		add(e)
+	}
+
+	if r.Any != nil {
+		e := &Expr{op: opAny}
+		for _, child := range r.Any {
+			c, err := Compile(child)
+			if err != nil {
+				return nil, err
+			}
+			e.children = append(e.children, c)
+		}
+		add(e)
+	}
+
+	if r.Not != nil {
+		c, err := Compile(r.Not)
+		if err != nil {
+			return nil, err
+		}
+		add(&Expr{op: opNot, children: []*Expr{c}})
+	}
+
+	for _, p := range []struct {
+		op      op
+		pattern string
+		flags   string
+	}{
+		{opTitle, r.Title, "(?i)"},
+		{opBody, r.Body, "(?mi)"},
+		{opAuthor, r.Author, "(?i)"},
+		{opLabel, r.Label, "(?i)"},
+		{opFile, r.File, "(?i)"},
+		{opBranch, r.Branch, "(?i)"},
+	} {
+		if p.pattern == "" {
+			continue
+		}
+
+		re, err := regexp.Compile(p.flags + p.pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression %s: %v", p.pattern, err)
+		}
+		add(&Expr{op: p.op, re: re, pattern: p.pattern})
+	}
+
+	if r.Glob != "" {
+		re, err := util.CompileGlob(r.Glob)
+		if err != nil {
+			return nil, fmt.Errorf("invalid glob %s: %v", r.Glob, err)
+		}
+		add(&Expr{op: opGlob, re: re, pattern: r.Glob})
+	}
+
+	if len(r.Association) > 0 {
+		add(&Expr{op: opAssociation, values: r.Association})
+	}
+
+	if r.Size != nil {
+		add(&Expr{op: opSize, size: *r.Size})
+	}
+
+	if r.Kind != "" {
+		if r.Kind != KindIssue && r.Kind != KindPullRequest {
+			return nil, fmt.Errorf("invalid kind %s, expecting %s or %s", r.Kind, KindIssue, KindPullRequest)
+		}
+		add(&Expr{op: opKind, pattern: r.Kind})
+	}
+
+	switch len(exprs) {
+	case 0:
+		return nil, errors.New("empty rule, expecting a predicate")
+	case 1:
+		return exprs[0], nil
+	default:
+		return nil, fmt.Errorf("a rule must contain a single predicate, found %d, use `all` or `any` to combine them", len(exprs))
+	}
+}
+
+// Eval returns whether the expression matches the subject.
+func (e *Expr) Eval(s *Subject) bool {
+	switch e.op {
+	case opAll:
+		for _, c := range e.children {
+			if !c.Eval(s) {
+				return false
+			}
+		}
+		return true
+
+	case opAny:
+		for _, c := range e.children {
+			if c.Eval(s) {
+				return true
+			}
+		}
+		return false
+
+	case opNot:
+		return !e.children[0].Eval(s)
+
+	case opTitle:
+		return e.re.MatchString(s.Title)
+
+	case opBody:
+		return e.re.MatchString(s.Body)
+
+	case opAuthor:
+		return e.re.MatchString(s.Author)
+
+	case opLabel:
+		return matchAny(e.re, s.Labels)
+
+	case opFile, opGlob:
+		return matchAny(e.re, s.Files)
+
+	case opBranch:
+		return s.PullRequest && e.re.MatchString(s.BaseBranch)
+
+	case opAssociation:
+		for _, v := range e.values {
+			if strings.EqualFold(v, s.AuthorAssociation) {
+				return true
+			}
+		}
+		return false
+
+	case opSize:
+		if !s.PullRequest {
+			return false
+		}
+		return (e.size.Min == 0 || s.Changes >= e.size.Min) && (e.size.Max == 0 || s.Changes <= e.size.Max)
+
+	case opKind:
+		return (e.pattern == KindPullRequest) == s.PullRequest
+	}
+
+	return false
+}
+
+// NeedsFiles returns whether evaluating the expression involves the files affected by a PR.
+func (e *Expr) NeedsFiles() bool {
+	if e.op == opFile || e.op == opGlob {
+		return true
+	}
+
+	for _, c := range e.children {
+		if c.NeedsFiles() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// String describes the expression's predicate, without its children.
+func (e *Expr) String() string {
+	switch e.op {
+	case opAll:
+		return "all"
+	case opAny:
+		return "any"
+	case opNot:
+		return "not"
+	case opTitle:
+		return fmt.Sprintf("title =~ %q", e.pattern)
+	case opBody:
+		return fmt.Sprintf("body =~ %q", e.pattern)
+	case opAuthor:
+		return fmt.Sprintf("author =~ %q", e.pattern)
+	case opLabel:
+		return fmt.Sprintf("label =~ %q", e.pattern)
+	case opFile:
+		return fmt.Sprintf("file =~ %q", e.pattern)
+	case opGlob:
+		return fmt.Sprintf("glob %q", e.pattern)
+	case opBranch:
+		return fmt.Sprintf("branch =~ %q", e.pattern)
+	case opAssociation:
+		return fmt.Sprintf("association in [%s]", strings.Join(e.values, ", "))
+	case opSize:
+		return fmt.Sprintf("size in [%d, %d]", e.size.Min, e.size.Max)
+	case opKind:
+		return fmt.Sprintf("kind is %s", e.pattern)
+	}
+
+	return "?"
+}
+
+// Trace evaluates the expression and describes the outcome of every predicate, one per line.
+func (e *Expr) Trace(s *Subject) string {
+	var sb strings.Builder
+	e.trace(s, &sb, 0)
+	return sb.String()
+}
+
+func (e *Expr) trace(s *Subject, sb *strings.Builder, depth int) {
+	mark := "✗"
+	if e.Eval(s) {
+		mark = "✓"
+	}
+
+	sb.WriteString(fmt.Sprintf("%s%s %s\n", strings.Repeat("  ", depth), mark, e))
+	for _, c := range e.children {
+		c.trace(s, sb, depth+1)
+	}
+}
+
+func matchAny(re *regexp.Regexp, values []string) bool {
+	for _, v := range values {
+		if re.MatchString(v) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,155 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rules
+
+import (
+	"testing"
+
+	"sigs.k8s.io/yaml"
+)
+
+func TestEval(t *testing.T) {
+	pr := &Subject{
+		PullRequest:       true,
+		Title:             "Fix a crash in Pilot",
+		Body:              "Some text\nFixes #123\n",
+		Author:            "octocat",
+		AuthorAssociation: "FIRST_TIME_CONTRIBUTOR",
+		Labels:            []string{"area/networking", "kind/bug"},
+		Files:             []string{"pilot/pkg/foo.go", "pilot/pkg/foo_test.go"},
+		BaseBranch:        "release-1.4",
+		Changes:           250,
+	}
+
+	issue := &Subject{
+		Title:  "Pilot crashes",
+		Author: "octocat",
+		Labels: []string{"area/networking"},
+	}
+
+	cases := []struct {
+		name  string
+		rule  string
+		pr    bool
+		issue bool
+	}{
+		{"title", `title: "fix"`, true, false},
+		{"body", `body: "^fixes #[0-9]+$"`, true, false},
+		{"author", `author: "^OctoCat$"`, true, true},
+		{"label", `label: "^area/"`, true, true},
+		{"file", `file: "_test\\.go$"`, true, false},
+		{"glob", `glob: "pilot/**"`, true, false},
+		{"glob mismatch", `glob: "mixer/**"`, false, false},
+		{"branch", `branch: "^release-"`, true, false},
+		{"association", `association: [FIRST_TIMER, FIRST_TIME_CONTRIBUTOR]`, true, false},
+		{"size", `size: {min: 100, max: 500}`, true, false},
+		{"size too large", `size: {max: 100}`, false, false},
+		{"kind", `kind: issue`, false, true},
+		{"not", `not: {label: "kind/bug"}`, false, true},
+		{"all", `all: [{label: "area/networking"}, {glob: "pilot/**"}]`, true, false},
+		{"any", `any: [{title: "crash"}, {glob: "pilot/**"}]`, true, true},
+		{"empty all", `all: []`, true, true},
+		{"empty any", `any: []`, false, false},
+		{"nested", `
+all:
+  - any:
+      - title: "fix"
+      - body: "fixes #[0-9]+"
+  - glob: "pilot/**"
+  - not:
+      glob: "**/*.md"
+`, true, false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r := &Rule{}
+			if err := yaml.UnmarshalStrict([]byte(c.rule), r); err != nil {
+				t.Fatalf("Unable to parse rule: %v", err)
+			}
+
+			expr, err := Compile(r)
+			if err != nil {
+				t.Fatalf("Unable to compile rule: %v", err)
+			}
+
+			if got := expr.Eval(pr); got != c.pr {
+				t.Errorf("Got %v for the PR, expected %v", got, c.pr)
+			}
+
+			if got := expr.Eval(issue); got != c.issue {
+				t.Errorf("Got %v for the issue, expected %v", got, c.issue)
+			}
+		})
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	cases := []struct {
+		name string
+		rule *Rule
+	}{
+		{"empty", &Rule{}},
+		{"two predicates", &Rule{Title: "a", Body: "b"}},
+		{"bad regex", &Rule{Title: "("}},
+		{"bad kind", &Rule{Kind: "commit"}},
+		{"nested", &Rule{Not: &Rule{}}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if _, err := Compile(c.rule); err == nil {
+				t.Errorf("Expected an error")
+			}
+		})
+	}
+}
+
+func TestNilRule(t *testing.T) {
+	expr, err := Compile(nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !expr.Eval(&Subject{}) {
+		t.Errorf("Expected a nil rule to match")
+	}
+}
+
+func TestNeedsFiles(t *testing.T) {
+	expr, _ := Compile(All(&Rule{Title: "a"}, &Rule{Not: &Rule{Glob: "*.go"}}))
+	if !expr.NeedsFiles() {
+		t.Errorf("Expected the rule to need files")
+	}
+
+	expr, _ = Compile(All(&Rule{Title: "a"}, &Rule{Label: "b"}))
+	if expr.NeedsFiles() {
+		t.Errorf("Expected the rule not to need files")
+	}
+}
+
+func TestTrace(t *testing.T) {
+	expr, _ := Compile(All(&Rule{Title: "fix"}, &Rule{Not: &Rule{Glob: "*.md"}}))
+
+	expected := `✗ all
+  ✓ title =~ "fix"
+  ✗ not
+    ✓ glob "*.md"
+`
+
+	if got := expr.Trace(&Subject{Title: "Fix docs", Files: []string{"README.md"}}); got != expected {
+		t.Errorf("Got:\n%s\nExpected:\n%s", got, expected)
+	}
+}
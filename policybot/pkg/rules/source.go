@@ -0,0 +1,55 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rules
+
+import (
+	"istio.io/bots/policybot/pkg/config"
+)
+
+// Source converts a configuration record into its name and the rule it expresses.
+type Source func(config.Record) (string, *Rule)
+
+var sources = make(map[string]Source)
+
+// RegisterSource declares how to get the rule out of records of the given type.
+func RegisterSource(recordType string, source Source) {
+	sources[recordType] = source
+}
+
+// SourceTypes returns the types of records that express rules.
+func SourceTypes() []string {
+	result := make([]string, 0, len(sources))
+	for t := range sources {
+		result = append(result, t)
+	}
+
+	return result
+}
+
+// FindRule returns the rule expressed by the named record of the given type in a repo.
+func FindRule(reg *config.Registry, recordType string, orgAndRepo string, name string) (*Rule, bool) {
+	source, ok := sources[recordType]
+	if !ok {
+		return nil, false
+	}
+
+	for _, r := range reg.Records(recordType, orgAndRepo) {
+		if n, rule := source(r); n == name {
+			return rule, true
+		}
+	}
+
+	return nil, false
+}
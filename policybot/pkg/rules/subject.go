@@ -0,0 +1,56 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rules
+
+import (
+	"istio.io/bots/policybot/pkg/storage"
+)
+
+// Subject is the issue or PR that rules are evaluated against.
+type Subject struct {
+	PullRequest       bool
+	Title             string
+	Body              string
+	Author            string
+	AuthorAssociation string
+	Labels            []string
+	Files             []string
+	BaseBranch        string
+	Changes           int // lines added and deleted
+}
+
+// IssueSubject returns the subject for an issue.
+func IssueSubject(issue *storage.Issue) *Subject {
+	return &Subject{
+		Title:  issue.Title,
+		Body:   issue.Body,
+		Author: issue.Author,
+		Labels: issue.Labels,
+	}
+}
+
+// PullRequestSubject returns the subject for a PR. The author association and size aren't kept in storage, so
+// callers that know them need to fill them in.
+func PullRequestSubject(pr *storage.PullRequest) *Subject {
+	return &Subject{
+		PullRequest: true,
+		Title:       pr.Title,
+		Body:        pr.Body,
+		Author:      pr.Author,
+		Labels:      pr.Labels,
+		Files:       pr.Files,
+		BaseBranch:  pr.BranchName,
+	}
+}
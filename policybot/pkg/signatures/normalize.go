@@ -0,0 +1,144 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signatures
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"strings"
+)
+
+const (
+	// maxExcerptLines bounds the number of lines kept from a failure's output
+	maxExcerptLines = 5
+
+	// maxLineLength bounds the length of each line kept from a failure's output
+	maxLineLength = 300
+
+	// fingerprintLength is the number of hex digits kept from the hash of an excerpt
+	fingerprintLength = 16
+)
+
+// failureMarker picks the lines of a test run's output that describe what went wrong
+var failureMarker = regexp.MustCompile(`(?i)(--- FAIL|^FAIL\b|panic:|\berror\b|\bfatal\b|timed out|deadline exceeded)`)
+
+// volatile lists the parts of a line that change from one run to another, along with what replaces them.
+// Order matters, as the earlier replacements consume text the later ones would otherwise match.
+var volatile = []struct {
+	re          *regexp.Regexp
+	placeholder string
+}{
+	{regexp.MustCompile(`\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:?\d{2})?`), "<time>"},
+	{regexp.MustCompile(`\b\d{2}:\d{2}:\d{2}(\.\d+)?\b`), "<time>"},
+	{regexp.MustCompile(`(?i)\b[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}\b`), "<uuid>"},
+	{regexp.MustCompile(`\b\d{1,3}(\.\d{1,3}){3}(:\d+)?\b`), "<ip>"},
+	{regexp.MustCompile(`/tmp/[^\s:'"]+`), "<tmp>"},
+	{regexp.MustCompile(`\b[a-z0-9]+(-[a-z0-9]+)*-[a-z0-9]{8,10}-[a-z0-9]{5}\b`), "<pod>"},
+	{regexp.MustCompile(`\b(\d+(\.\d+)?(ns|us|µs|ms|s|m|h))+\b`), "<duration>"},
+	{regexp.MustCompile(`\b(0x)?[0-9a-f]{7,}\b`), "<hex>"},
+	{regexp.MustCompile(`\b\d+\b`), "<n>"},
+}
+
+var placeholder = regexp.MustCompile(`<(time|uuid|ip|tmp|pod|duration|hex|n)>`)
+
+var whitespace = regexp.MustCompile(`\s+`)
+
+// Normalize replaces the parts of a line of output that vary between runs, such as times, addresses and
+// generated names, with placeholders, so that the same failure reads the same way every time.
+func Normalize(line string) string {
+	for _, v := range volatile {
+		line = v.re.ReplaceAllStringFunc(line, func(match string) string {
+			// hashes mix digits and letters, leave words and plain numbers to the other replacements
+			if v.placeholder == "<hex>" && !(strings.ContainsAny(match, "0123456789") && strings.ContainsAny(match, "abcdef")) {
+				return match
+			}
+			return v.placeholder
+		})
+	}
+
+	return strings.Join(strings.Fields(line), " ")
+}
+
+// Excerpt extracts the normalized lines of a test run's output that best describe its failure: the last
+// distinct lines that look like errors, or the last lines of the output when none do.
+func Excerpt(output []byte) string {
+	var marked []string
+	var tail []string
+	seen := make(map[string]bool)
+
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if len(line) > maxLineLength {
+			line = line[:maxLineLength]
+		}
+
+		line = Normalize(line)
+		if line == "" {
+			continue
+		}
+
+		tail = append(tail, line)
+		if len(tail) > maxExcerptLines {
+			tail = tail[1:]
+		}
+
+		if failureMarker.MatchString(line) && !seen[line] {
+			seen[line] = true
+			marked = append(marked, line)
+		}
+	}
+
+	// the scanner stops at a line too long to buffer, in which case the excerpt covers the output up to it
+
+	if len(marked) == 0 {
+		return strings.Join(tail, "\n")
+	}
+
+	if len(marked) > maxExcerptLines {
+		marked = marked[len(marked)-maxExcerptLines:]
+	}
+
+	return strings.Join(marked, "\n")
+}
+
+// Fingerprint identifies a cluster of failures sharing the same excerpt.
+func Fingerprint(excerpt string) string {
+	sum := sha256.Sum256([]byte(excerpt))
+	return hex.EncodeToString(sum[:])[:fingerprintLength]
+}
+
+// PromotionPattern suggests a regular expression matching the failures of a cluster, built from the most
+// specific line of the cluster's excerpt, for use in a new signature. Excerpts have their whitespace collapsed,
+// so the pattern accepts any run of whitespace wherever the excerpt has a space, as in the raw output.
+func PromotionPattern(excerpt string) string {
+	var longest string
+	for _, line := range strings.Split(excerpt, "\n") {
+		if len(line) > len(longest) {
+			longest = line
+		}
+	}
+
+	parts := placeholder.Split(longest, -1)
+	for i := range parts {
+		parts[i] = whitespace.ReplaceAllLiteralString(regexp.QuoteMeta(parts[i]), `\s+`)
+	}
+
+	return strings.Join(parts, ".+?")
+}
@@ -0,0 +1,47 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signatures
+
+import (
+	"istio.io/bots/policybot/pkg/config"
+)
+
+const RecordType = "failuresignature"
+
+// signatureRecord names a known cause of CI failures, recognized by regular expressions matched against the
+// output of a test run.
+type signatureRecord struct {
+	config.RecordBase
+
+	// Category groups signatures by the kind of failure, one of infra, timeout, test-failure or build-failure
+	Category string `json:"category"`
+
+	// File is the file of a test run's output the patterns are matched against
+	File string `json:"file"`
+
+	// Patterns are regular expressions, any of which identifies the failure
+	Patterns []string `json:"patterns"`
+
+	// Description explains the failure to humans
+	Description string `json:"description"`
+}
+
+func init() {
+	config.RegisterType(RecordType, config.MultiplePerRepo, func() config.Record {
+		return &signatureRecord{
+			File: DefaultFile,
+		}
+	})
+}
@@ -0,0 +1,135 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package signatures recognizes the causes of CI failures. Known causes are described by a catalog of
+// named signatures held in config, while unknown ones are clustered by a fingerprint of their normalized
+// failure output so the most common ones can be promoted to named signatures.
+package signatures
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+
+	"istio.io/bots/policybot/pkg/config"
+)
+
+// Categories of failure signatures.
+const (
+	CategoryInfra        = "infra"
+	CategoryTimeout      = "timeout"
+	CategoryTestFailure  = "test-failure"
+	CategoryBuildFailure = "build-failure"
+)
+
+// DefaultFile is the test run output signatures are matched against when they don't say otherwise.
+const DefaultFile = "build-log.txt"
+
+var categories = map[string]bool{
+	CategoryInfra:        true,
+	CategoryTimeout:      true,
+	CategoryTestFailure:  true,
+	CategoryBuildFailure: true,
+}
+
+// Signature is a compiled signature record.
+type Signature struct {
+	Name        string
+	Category    string
+	File        string
+	Description string
+	patterns    []*regexp.Regexp
+}
+
+// ForRepo compiles the signatures that apply to a repo, ordered by name.
+func ForRepo(reg *config.Registry, orgAndRepo string) ([]*Signature, error) {
+	return compile(reg.Records(RecordType, orgAndRepo))
+}
+
+// All compiles the signatures of every repo, ordered by name.
+func All(reg *config.Registry) ([]*Signature, error) {
+	return compile(reg.Records(RecordType, "*"))
+}
+
+func compile(records []config.Record) ([]*Signature, error) {
+	var sigs []*Signature
+	for _, r := range records {
+		sr := r.(*signatureRecord)
+		if !categories[sr.Category] {
+			return nil, fmt.Errorf("signature %s has unknown category %q", sr.Name, sr.Category)
+		}
+
+		if len(sr.Patterns) == 0 {
+			return nil, fmt.Errorf("signature %s has no patterns", sr.Name)
+		}
+
+		sig := &Signature{
+			Name:        sr.Name,
+			Category:    sr.Category,
+			File:        sr.File,
+			Description: sr.Description,
+		}
+
+		if sig.File == "" {
+			sig.File = DefaultFile
+		}
+
+		for _, p := range sr.Patterns {
+			re, err := regexp.Compile(p)
+			if err != nil {
+				return nil, fmt.Errorf("signature %s has invalid pattern %s: %v", sr.Name, p, err)
+			}
+			sig.patterns = append(sig.patterns, re)
+		}
+
+		sigs = append(sigs, sig)
+	}
+
+	sort.Slice(sigs, func(i, j int) bool { return sigs[i].Name < sigs[j].Name })
+	return sigs, nil
+}
+
+// Files returns the distinct files of a test run's output that the signatures look at.
+func Files(sigs []*Signature) []string {
+	seen := make(map[string]bool)
+	var files []string
+	for _, sig := range sigs {
+		if !seen[sig.File] {
+			seen[sig.File] = true
+			files = append(files, sig.File)
+		}
+	}
+
+	return files
+}
+
+// Match returns the names of the signatures found in a test run's output, given as the content of each file.
+func Match(sigs []*Signature, output map[string][]byte) []string {
+	var names []string
+	for _, sig := range sigs {
+		content, ok := output[sig.File]
+		if !ok {
+			continue
+		}
+
+		for _, re := range sig.patterns {
+			if re.Match(content) {
+				names = append(names, sig.Name)
+				break
+			}
+		}
+	}
+
+	return names
+}
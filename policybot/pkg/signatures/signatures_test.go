@@ -0,0 +1,150 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signatures
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+
+	"istio.io/bots/policybot/pkg/config"
+)
+
+func TestNormalize(t *testing.T) {
+	cases := []struct {
+		in   string
+		want string
+	}{
+		{
+			"2026-10-17T08:15:02.123Z  error  failed to connect to 10.4.2.17:15012 after 30.5s",
+			"<time> error failed to connect to <ip> after <duration>",
+		},
+		{
+			"pod istiod-7d9f8b6c4d-x2k9p in namespace echo-1-29184 is not ready",
+			"pod <pod> in namespace echo-<n>-<n> is not ready",
+		},
+		{
+			"image gcr.io/istio-testing/pilot:1.29-dev-3f9a2c1b7e not found, cache in /tmp/istio.k8s-1234/cache",
+			"image gcr.io/istio-testing/pilot:<n>.<n>-dev-<hex> not found, cache in <tmp>",
+		},
+		{
+			"request 0b9c7a52-3d1e-4f6a-9b2c-8e7d6f5a4b3c: deadline exceeded",
+			"request <uuid>: deadline exceeded",
+		},
+	}
+
+	for _, c := range cases {
+		if got := Normalize(c.in); got != c.want {
+			t.Errorf("Normalize(%q):\n got %q\nwant %q", c.in, got, c.want)
+		}
+	}
+}
+
+func TestExcerptAndFingerprint(t *testing.T) {
+	run1 := []byte(`+ make test
+2026-10-17T08:15:02Z info  starting
+2026-10-17T08:15:09Z error failed to reach 10.4.2.17:15012
+--- FAIL: TestTraffic (12.31s)
+FAIL	istio.io/istio/tests/integration/pilot	45.022s
+`)
+	run2 := []byte(`+ make test
+2026-10-18T01:00:00Z info  starting
+2026-10-18T01:00:04Z error failed to reach 10.8.0.3:15012
+--- FAIL: TestTraffic (9.02s)
+FAIL	istio.io/istio/tests/integration/pilot	38.5s
+`)
+	other := []byte(`--- FAIL: TestMTLS (1.00s)
+`)
+
+	e1, e2 := Excerpt(run1), Excerpt(run2)
+	want := "<time> error failed to reach <ip>\n--- FAIL: TestTraffic (<duration>)\nFAIL istio.io/istio/tests/integration/pilot <duration>"
+	if e1 != want {
+		t.Errorf("got excerpt:\n%s\nwant:\n%s", e1, want)
+	}
+
+	if Fingerprint(e1) != Fingerprint(e2) {
+		t.Errorf("expected runs failing the same way to share a fingerprint:\n%s\n%s", e1, e2)
+	}
+
+	if Fingerprint(e1) == Fingerprint(Excerpt(other)) {
+		t.Error("expected runs failing differently to have different fingerprints")
+	}
+
+	if len(Fingerprint(e1)) != fingerprintLength {
+		t.Errorf("got fingerprint %s, expected %d hex digits", Fingerprint(e1), fingerprintLength)
+	}
+
+	// without any error lines, the excerpt is the end of the output
+	if got := Excerpt([]byte("a\nb\nc\nd\ne\nf\ng\n")); got != "c\nd\ne\nf\ng" {
+		t.Errorf("got excerpt %q", got)
+	}
+}
+
+func TestPromotionPattern(t *testing.T) {
+	excerpt := Excerpt([]byte("2026-10-17T08:15:09Z error failed to reach 10.4.2.17:15012 (attempt 3)\n"))
+	re := regexp.MustCompile(PromotionPattern(excerpt))
+
+	if !re.MatchString("2027-01-02T00:00:00Z error failed to reach 192.168.1.1:8080 (attempt 12)") {
+		t.Errorf("pattern %s doesn't match the same failure in another run", re)
+	}
+
+	if re.MatchString("2027-01-02T00:00:00Z error failed to resolve istiod.istio-system (attempt 12)") {
+		t.Errorf("pattern %s matches a different failure", re)
+	}
+
+	raw := "FAIL\tistio.io/pkg\t1.2s\n"
+	re = regexp.MustCompile(PromotionPattern(Excerpt([]byte(raw))))
+	if !re.MatchString(raw) {
+		t.Errorf("pattern %s doesn't match the raw output it was built from", re)
+	}
+}
+
+func TestCatalog(t *testing.T) {
+	records := []config.Record{
+		&signatureRecord{RecordBase: config.RecordBase{Name: "boskos"}, Category: CategoryInfra, File: DefaultFile,
+			Patterns: []string{"failed to get a Boskos resource"}},
+		&signatureRecord{RecordBase: config.RecordBase{Name: "apiserver"}, Category: CategoryInfra,
+			Patterns: []string{"API Server failed to come up", "The connection to the server .* was refused"}},
+		&signatureRecord{RecordBase: config.RecordBase{Name: "junit"}, Category: CategoryTestFailure, File: "artifacts/junit.xml",
+			Patterns: []string{"<failure"}},
+	}
+
+	sigs, err := compile(records)
+	if err != nil {
+		t.Fatalf("unable to compile signatures: %v", err)
+	}
+
+	if got := Files(sigs); !reflect.DeepEqual(got, []string{DefaultFile, "artifacts/junit.xml"}) {
+		t.Errorf("got files %v", got)
+	}
+
+	got := Match(sigs, map[string][]byte{
+		DefaultFile: []byte("The connection to the server 10.0.0.1:443 was refused - did you specify the right host or port?"),
+	})
+	if !reflect.DeepEqual(got, []string{"apiserver"}) {
+		t.Errorf("got signatures %v, expected [apiserver]", got)
+	}
+
+	bad := []*signatureRecord{
+		{RecordBase: config.RecordBase{Name: "category"}, Category: "flaky", Patterns: []string{"x"}},
+		{RecordBase: config.RecordBase{Name: "patterns"}, Category: CategoryTimeout},
+		{RecordBase: config.RecordBase{Name: "regex"}, Category: CategoryTimeout, Patterns: []string{"("}},
+	}
+	for _, sr := range bad {
+		if _, err := compile([]config.Record{sr}); err == nil {
+			t.Errorf("expected an error compiling signature %s", sr.Name)
+		}
+	}
+}
@@ -62,7 +62,7 @@ func rowToStruct(row *spanner.Row, s interface{}) error {
 	columns := row.ColumnNames()
 	for i := 0; i < structType.NumField(); i++ {
 		fieldInfo := structType.Field(i)
-		if fieldInfo.PkgPath != "" { // field is unexported
+		if fieldInfo.PkgPath != "" || fieldInfo.Tag.Get("spanner") == "-" { // field is unexported or ignored
 			continue
 		}
 		switch structVal.Field(i).Interface().(type) {
@@ -151,3 +151,26 @@ func (s store) GetLatestIssueMemberComment(context context.Context, orgLogin str
 
 	return result.LastIssueCommentEvent, err
 }
+
+func (s store) GetLatestIssueUserComment(context context.Context, orgLogin string, repoName string, issueNumber int,
+	userLogin string) (time.Time, error) {
+	stmt := spanner.NewStatement(`SELECT CreatedAt as LastIssueCommentEvent, Actor FROM
+		(SELECT CreatedAt, Actor FROM IssueCommentEvents
+			WHERE OrgLogin = @orgLogin AND RepoName = @repoName AND IssueNumber = @issueNumber AND Actor = @userLogin
+		UNION ALL
+		SELECT CreatedAt, Actor FROM PullRequestReviewCommentEvents
+			WHERE OrgLogin = @orgLogin AND RepoName = @repoName AND PullRequestNumber = @issueNumber AND Actor = @userLogin)
+		ORDER BY LastIssueCommentEvent DESC
+		LIMIT 1`)
+	stmt.Params["orgLogin"] = orgLogin
+	stmt.Params["repoName"] = repoName
+	stmt.Params["issueNumber"] = int64(issueNumber)
+	stmt.Params["userLogin"] = userLogin
+
+	var result getCommentResults
+	err := s.client.Single().Query(context, stmt).Do(func(row *spanner.Row) error {
+		return rowToStruct(row, &result)
+	})
+
+	return result.LastIssueCommentEvent, err
+}
@@ -18,6 +18,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"cloud.google.com/go/spanner"
 	"google.golang.org/api/iterator"
@@ -182,6 +183,68 @@ func (s store) QueryTestResultByTestName(context context.Context, orgLogin strin
 	return err
 }
 
+func (s store) QueryOpenIssuesByField(context context.Context, orgLogin string, fieldName string, valueRegex string,
+	cb func(*storage.Issue) error) error {
+	stmt := spanner.NewStatement(`SELECT i.* FROM Issues AS i
+		JOIN IssueFields AS f ON i.OrgLogin = f.OrgLogin AND i.RepoName = f.RepoName AND i.IssueNumber = f.IssueNumber
+		WHERE i.OrgLogin = @orgLogin AND i.State = 'open' AND f.FieldName = @fieldName AND REGEXP_CONTAINS(f.Value, @valueRegex)`)
+	stmt.Params["orgLogin"] = orgLogin
+	stmt.Params["fieldName"] = fieldName
+	stmt.Params["valueRegex"] = "(?i)" + valueRegex
+
+	iter := s.client.Single().Query(context, stmt)
+	err := iter.Do(func(row *spanner.Row) error {
+		issue := &storage.Issue{}
+		if err := rowToStruct(row, issue); err != nil {
+			return err
+		}
+
+		return cb(issue)
+	})
+
+	return err
+}
+
+func (s store) QueryOpenIssueFields(context context.Context, orgLogin string, fieldName string, cb func(*storage.IssueField) error) error {
+	stmt := spanner.NewStatement(`SELECT f.* FROM IssueFields AS f
+		JOIN Issues AS i ON i.OrgLogin = f.OrgLogin AND i.RepoName = f.RepoName AND i.IssueNumber = f.IssueNumber
+		WHERE f.OrgLogin = @orgLogin AND f.FieldName = @fieldName AND i.State = 'open'`)
+	stmt.Params["orgLogin"] = orgLogin
+	stmt.Params["fieldName"] = fieldName
+
+	iter := s.client.Single().Query(context, stmt)
+	err := iter.Do(func(row *spanner.Row) error {
+		field := &storage.IssueField{}
+		if err := rowToStruct(row, field); err != nil {
+			return err
+		}
+
+		return cb(field)
+	})
+
+	return err
+}
+
+func (s store) QueryIssueFieldsByIssue(context context.Context, orgLogin string, repoName string, issueNumber int,
+	cb func(*storage.IssueField) error) error {
+	stmt := spanner.NewStatement("SELECT * FROM IssueFields WHERE OrgLogin = @orgLogin AND RepoName = @repoName AND IssueNumber = @issueNumber")
+	stmt.Params["orgLogin"] = orgLogin
+	stmt.Params["repoName"] = repoName
+	stmt.Params["issueNumber"] = int64(issueNumber)
+
+	iter := s.client.Single().Query(context, stmt)
+	err := iter.Do(func(row *spanner.Row) error {
+		field := &storage.IssueField{}
+		if err := rowToStruct(row, field); err != nil {
+			return err
+		}
+
+		return cb(field)
+	})
+
+	return err
+}
+
 func (s store) QueryTestResultByPrNumber(
 	context context.Context, orgLogin string, repoName string, pullRequestNumber int64, cb func(*storage.TestResult) error,
 ) error {
@@ -850,6 +913,86 @@ func (s store) QueryAllUserAffiliations(context context.Context, cb func(affilia
 	return err
 }
 
+func (s store) QueryBotLabelsByIssue(context context.Context, orgLogin string, repoName string, issueNumber int,
+	cb func(*storage.BotLabel) error) error {
+	stmt := spanner.NewStatement("SELECT * FROM BotLabels WHERE OrgLogin = @orgLogin AND RepoName = @repoName AND IssueNumber = @issueNumber")
+	stmt.Params["orgLogin"] = orgLogin
+	stmt.Params["repoName"] = repoName
+	stmt.Params["issueNumber"] = int64(issueNumber)
+
+	iter := s.client.Single().Query(context, stmt)
+	err := iter.Do(func(row *spanner.Row) error {
+		label := &storage.BotLabel{}
+		if err := rowToStruct(row, label); err != nil {
+			return err
+		}
+
+		return cb(label)
+	})
+
+	return err
+}
+
+func (s store) QueryBackports(context context.Context, orgLogin string, cb func(*storage.Backport) error) error {
+	stmt := spanner.NewStatement("SELECT * FROM Backports WHERE OrgLogin = @orgLogin ORDER BY UpdatedAt DESC")
+	stmt.Params["orgLogin"] = orgLogin
+
+	iter := s.client.Single().Query(context, stmt)
+	err := iter.Do(func(row *spanner.Row) error {
+		backport := &storage.Backport{}
+		if err := rowToStruct(row, backport); err != nil {
+			return err
+		}
+
+		return cb(backport)
+	})
+
+	return err
+}
+
+func (s store) QueryBackportsByPullRequest(context context.Context, orgLogin string, repoName string, prNumber int,
+	cb func(*storage.Backport) error) error {
+	stmt := spanner.NewStatement("SELECT * FROM Backports WHERE OrgLogin = @orgLogin AND RepoName = @repoName AND PullRequestNumber = @prNumber")
+	stmt.Params["orgLogin"] = orgLogin
+	stmt.Params["repoName"] = repoName
+	stmt.Params["prNumber"] = int64(prNumber)
+
+	iter := s.client.Single().Query(context, stmt)
+	err := iter.Do(func(row *spanner.Row) error {
+		backport := &storage.Backport{}
+		if err := rowToStruct(row, backport); err != nil {
+			return err
+		}
+
+		return cb(backport)
+	})
+
+	return err
+}
+
+func (s store) QueryMergedPullRequestsByMilestone(context context.Context, orgLogin string, repoName string, milestone string, branchName string,
+	cb func(*storage.PullRequest) error) error {
+	stmt := spanner.NewStatement(`SELECT * FROM PullRequests
+		WHERE OrgLogin = @orgLogin AND RepoName = @repoName AND Merged = TRUE AND (Milestone = @milestone OR BranchName = @branchName)
+		ORDER BY MergedAt`)
+	stmt.Params["orgLogin"] = orgLogin
+	stmt.Params["repoName"] = repoName
+	stmt.Params["milestone"] = milestone
+	stmt.Params["branchName"] = branchName
+
+	iter := s.client.Single().Query(context, stmt)
+	err := iter.Do(func(row *spanner.Row) error {
+		pr := &storage.PullRequest{}
+		if err := rowToStruct(row, pr); err != nil {
+			return err
+		}
+
+		return cb(pr)
+	})
+
+	return err
+}
+
 func (s store) QueryPullRequestsByUser(context context.Context, orgLogin string, repoName string, userLogin string, cb func(*storage.PullRequest) error) error {
 	iter := s.client.Single().Query(context,
 		spanner.Statement{SQL: fmt.Sprintf("SELECT * FROM PullRequests WHERE OrgLogin = '%s' AND RepoName = '%s' AND Author = '%s';", orgLogin, repoName, userLogin)})
@@ -865,6 +1008,350 @@ func (s store) QueryPullRequestsByUser(context context.Context, orgLogin string,
 	return err
 }
 
+func (s store) QueryIssuesByUser(context context.Context, orgLogin string, repoName string, userLogin string, cb func(*storage.Issue) error) error {
+	stmt := spanner.NewStatement("SELECT * FROM Issues WHERE OrgLogin = @orgLogin AND RepoName = @repoName AND Author = @author")
+	stmt.Params["orgLogin"] = orgLogin
+	stmt.Params["repoName"] = repoName
+	stmt.Params["author"] = userLogin
+
+	iter := s.client.Single().Query(context, stmt)
+	err := iter.Do(func(row *spanner.Row) error {
+		issue := &storage.Issue{}
+		if err := rowToStruct(row, issue); err != nil {
+			return err
+		}
+
+		return cb(issue)
+	})
+
+	return err
+}
+
+func (s store) QueryWelcomesByUser(context context.Context, orgLogin string, userLogin string, cb func(*storage.Welcome) error) error {
+	stmt := spanner.NewStatement("SELECT * FROM Welcomes WHERE OrgLogin = @orgLogin AND UserLogin = @userLogin")
+	stmt.Params["orgLogin"] = orgLogin
+	stmt.Params["userLogin"] = userLogin
+
+	iter := s.client.Single().Query(context, stmt)
+	err := iter.Do(func(row *spanner.Row) error {
+		welcome := &storage.Welcome{}
+		if err := rowToStruct(row, welcome); err != nil {
+			return err
+		}
+
+		return cb(welcome)
+	})
+
+	return err
+}
+
+func (s store) QueryMentorAssignments(context context.Context, orgLogin string, cb func(*storage.MentorAssignment) error) error {
+	stmt := spanner.NewStatement("SELECT * FROM MentorAssignments WHERE OrgLogin = @orgLogin ORDER BY AssignedAt DESC")
+	stmt.Params["orgLogin"] = orgLogin
+
+	iter := s.client.Single().Query(context, stmt)
+	err := iter.Do(func(row *spanner.Row) error {
+		assignment := &storage.MentorAssignment{}
+		if err := rowToStruct(row, assignment); err != nil {
+			return err
+		}
+
+		return cb(assignment)
+	})
+
+	return err
+}
+
+func (s store) QueryMentorAssignmentsByPullRequest(context context.Context, orgLogin string, repoName string, prNumber int,
+	cb func(*storage.MentorAssignment) error) error {
+	stmt := spanner.NewStatement("SELECT * FROM MentorAssignments WHERE OrgLogin = @orgLogin AND RepoName = @repoName AND PullRequestNumber = @prNumber")
+	stmt.Params["orgLogin"] = orgLogin
+	stmt.Params["repoName"] = repoName
+	stmt.Params["prNumber"] = int64(prNumber)
+
+	iter := s.client.Single().Query(context, stmt)
+	err := iter.Do(func(row *spanner.Row) error {
+		assignment := &storage.MentorAssignment{}
+		if err := rowToStruct(row, assignment); err != nil {
+			return err
+		}
+
+		return cb(assignment)
+	})
+
+	return err
+}
+
+func (s store) QueryLifecycleEventsByIssue(context context.Context, orgLogin string, repoName string, issueNumber int,
+	cb func(*storage.LifecycleEvent) error) error {
+	stmt := spanner.NewStatement(`SELECT * FROM LifecycleEvents
+		WHERE OrgLogin = @orgLogin AND RepoName = @repoName AND IssueNumber = @issueNumber
+		ORDER BY RecordedAt DESC`)
+	stmt.Params["orgLogin"] = orgLogin
+	stmt.Params["repoName"] = repoName
+	stmt.Params["issueNumber"] = int64(issueNumber)
+
+	iter := s.client.Single().Query(context, stmt)
+	err := iter.Do(func(row *spanner.Row) error {
+		event := &storage.LifecycleEvent{}
+		if err := rowToStruct(row, event); err != nil {
+			return err
+		}
+
+		return cb(event)
+	})
+
+	return err
+}
+
+func (s store) QueryLifecycleEventsByRepo(context context.Context, orgLogin string, repoName string, since time.Time,
+	cb func(*storage.LifecycleEvent) error) error {
+	stmt := spanner.NewStatement(`SELECT * FROM LifecycleEvents
+		WHERE OrgLogin = @orgLogin AND RepoName = @repoName AND RecordedAt >= @since
+		ORDER BY RecordedAt DESC`)
+	stmt.Params["orgLogin"] = orgLogin
+	stmt.Params["repoName"] = repoName
+	stmt.Params["since"] = since
+
+	iter := s.client.Single().Query(context, stmt)
+	err := iter.Do(func(row *spanner.Row) error {
+		event := &storage.LifecycleEvent{}
+		if err := rowToStruct(row, event); err != nil {
+			return err
+		}
+
+		return cb(event)
+	})
+
+	return err
+}
+
+func (s store) QueryTestFlakeDays(context context.Context, orgLogin string, since time.Time, cb func(*storage.TestFlakeDay) error) error {
+	stmt := spanner.NewStatement(`SELECT * FROM TestFlakeDays
+		WHERE OrgLogin = @orgLogin AND Day >= @since`)
+	stmt.Params["orgLogin"] = orgLogin
+	stmt.Params["since"] = since
+
+	iter := s.client.Single().Query(context, stmt)
+	err := iter.Do(func(row *spanner.Row) error {
+		day := &storage.TestFlakeDay{}
+		if err := rowToStruct(row, day); err != nil {
+			return err
+		}
+
+		return cb(day)
+	})
+
+	return err
+}
+
+func (s store) QueryOpenFlakeIssues(context context.Context, orgLogin string, repoName string, cb func(*storage.FlakeIssue) error) error {
+	stmt := spanner.NewStatement(`SELECT * FROM FlakeIssues
+		WHERE OrgLogin = @orgLogin AND RepoName = @repoName AND ClosedAt IS NULL`)
+	stmt.Params["orgLogin"] = orgLogin
+	stmt.Params["repoName"] = repoName
+
+	iter := s.client.Single().Query(context, stmt)
+	err := iter.Do(func(row *spanner.Row) error {
+		issue := &storage.FlakeIssue{}
+		if err := rowToStruct(row, issue); err != nil {
+			return err
+		}
+
+		return cb(issue)
+	})
+
+	return err
+}
+
+func (s store) QueryUnfiledFlakes(context context.Context, orgLogin string, repoName string, since time.Time, until time.Time,
+	cb func(*storage.FlakeOccurrence) error) error {
+	stmt := spanner.NewStatement(`SELECT f.OrgLogin, f.RepoName, f.TestName, f.PullRequestNumber, f.RunNumber, f.Done,
+		f.PassingRunNumber, failed.FinishTime, failed.RunPath AS FailedRunPath, passed.RunPath AS PassingRunPath
+		FROM ConfirmedFlakes AS f
+		JOIN TestResults AS failed
+		ON failed.OrgLogin = f.OrgLogin AND
+		failed.RepoName = f.RepoName AND
+		failed.TestName = f.TestName AND
+		failed.PullRequestNumber = f.PullRequestNumber AND
+		failed.RunNumber = f.RunNumber AND
+		failed.Done = f.Done
+		LEFT JOIN TestResults AS passed
+		ON passed.OrgLogin = f.OrgLogin AND
+		passed.RepoName = f.RepoName AND
+		passed.TestName = f.TestName AND
+		passed.PullRequestNumber = f.PullRequestNumber AND
+		passed.RunNumber = f.PassingRunNumber AND
+		passed.Done
+		WHERE f.OrgLogin = @orgLogin AND
+		f.RepoName = @repoName AND
+		f.IssueNum IS NULL AND
+		failed.FinishTime >= @since AND
+		failed.FinishTime < @until
+		ORDER BY failed.FinishTime`)
+	stmt.Params["orgLogin"] = orgLogin
+	stmt.Params["repoName"] = repoName
+	stmt.Params["since"] = since
+	stmt.Params["until"] = until
+
+	iter := s.client.Single().Query(context, stmt)
+	err := iter.Do(func(row *spanner.Row) error {
+		occurrence := &storage.FlakeOccurrence{}
+		if err := rowToStruct(row, occurrence); err != nil {
+			return err
+		}
+
+		return cb(occurrence)
+	})
+
+	return err
+}
+
+func (s store) QueryTestFailures(context context.Context, orgLogin string, repoName string, testName string, since time.Time,
+	cb func(*storage.TestFailure) error) error {
+	stmt := spanner.NewStatement(`SELECT r.OrgLogin, r.RepoName, r.TestName, r.PullRequestNumber, r.RunNumber,
+		r.FinishTime, r.RunPath, r.Result, r.Signatures,
+		(SELECT MIN(f.PassingRunNumber) FROM ConfirmedFlakes AS f
+			WHERE f.OrgLogin = r.OrgLogin AND
+			f.RepoName = r.RepoName AND
+			f.TestName = r.TestName AND
+			f.PullRequestNumber = r.PullRequestNumber AND
+			f.RunNumber = r.RunNumber) AS PassingRunNumber
+		FROM TestResults AS r
+		WHERE r.OrgLogin = @orgLogin AND
+		r.RepoName = @repoName AND
+		r.TestName = @testName AND
+		r.Done AND
+		r.TestPassed = FALSE AND
+		NOT r.CloneFailed AND
+		r.FinishTime >= @since
+		ORDER BY r.FinishTime DESC`)
+	stmt.Params["orgLogin"] = orgLogin
+	stmt.Params["repoName"] = repoName
+	stmt.Params["testName"] = testName
+	stmt.Params["since"] = since
+
+	iter := s.client.Single().Query(context, stmt)
+	err := iter.Do(func(row *spanner.Row) error {
+		failure := &storage.TestFailure{}
+		if err := rowToStruct(row, failure); err != nil {
+			return err
+		}
+
+		return cb(failure)
+	})
+
+	return err
+}
+
+func (s store) QueryTestCaseFlakes(context context.Context, orgLogin string, repoName string, testName string, since time.Time,
+	cb func(*storage.TestCaseFlake) error) error {
+	// the flakes are confirmed ahead of time by UpdateTestCaseFlakeCache
+	stmt := spanner.NewStatement(`SELECT c.PackageName, c.CaseName,
+		COUNT(*) AS Runs,
+		COUNTIF(c.Status != 'passed') AS Failures,
+		COUNTIF(f.RunNumber IS NOT NULL) AS FlakyFailures,
+		COUNT(DISTINCT IF(f.RunNumber IS NOT NULL, c.PullRequestNumber, NULL)) AS PullRequests,
+		AVG(c.Duration) AS MeanDuration,
+		MAX(IF(f.RunNumber IS NOT NULL, c.FinishTime, NULL)) AS LastFlaked
+		FROM TestCaseResults AS c
+		LEFT JOIN ConfirmedTestCaseFlakes AS f
+		ON f.OrgLogin = c.OrgLogin AND
+		f.RepoName = c.RepoName AND
+		f.TestName = c.TestName AND
+		f.PullRequestNumber = c.PullRequestNumber AND
+		f.RunNumber = c.RunNumber AND
+		f.Done = c.Done AND
+		f.PackageName = c.PackageName AND
+		f.CaseName = c.CaseName
+		WHERE c.OrgLogin = @orgLogin AND
+		c.RepoName = @repoName AND
+		c.TestName = @testName AND
+		c.Status != 'skipped' AND
+		c.FinishTime >= @since
+		GROUP BY c.PackageName, c.CaseName
+		HAVING COUNTIF(f.RunNumber IS NOT NULL) > 0
+		ORDER BY FlakyFailures DESC, c.PackageName, c.CaseName`)
+	stmt.Params["orgLogin"] = orgLogin
+	stmt.Params["repoName"] = repoName
+	stmt.Params["testName"] = testName
+	stmt.Params["since"] = since
+
+	iter := s.client.Single().Query(context, stmt)
+	err := iter.Do(func(row *spanner.Row) error {
+		flake := &storage.TestCaseFlake{}
+		if err := rowToStruct(row, flake); err != nil {
+			return err
+		}
+
+		return cb(flake)
+	})
+
+	return err
+}
+
+func (s store) QueryTestCaseHistory(context context.Context, orgLogin string, repoName string, packageName string, caseName string,
+	since time.Time, cb func(*storage.TestCaseResult) error) error {
+	stmt := spanner.NewStatement(`SELECT * FROM TestCaseResults
+		WHERE OrgLogin = @orgLogin AND
+		RepoName = @repoName AND
+		PackageName = @packageName AND
+		CaseName = @caseName AND
+		FinishTime >= @since
+		ORDER BY FinishTime DESC`)
+	stmt.Params["orgLogin"] = orgLogin
+	stmt.Params["repoName"] = repoName
+	stmt.Params["packageName"] = packageName
+	stmt.Params["caseName"] = caseName
+	stmt.Params["since"] = since
+
+	iter := s.client.Single().Query(context, stmt)
+	err := iter.Do(func(row *spanner.Row) error {
+		result := &storage.TestCaseResult{}
+		if err := rowToStruct(row, result); err != nil {
+			return err
+		}
+
+		return cb(result)
+	})
+
+	return err
+}
+
+func (s store) QueryFailureClusters(context context.Context, orgLogin string, since time.Time, limit int,
+	cb func(*storage.FailureCluster) error) error {
+	stmt := spanner.NewStatement(`SELECT FailureFingerprint AS Fingerprint,
+		ANY_VALUE(FailureExcerpt) AS Excerpt,
+		COUNT(*) AS Runs,
+		COUNT(DISTINCT CONCAT(RepoName, '/', CAST(PullRequestNumber AS STRING))) AS PullRequests,
+		COUNT(DISTINCT CONCAT(RepoName, '/', TestName)) AS Tests,
+		MIN(FinishTime) AS FirstSeen,
+		MAX(FinishTime) AS LastSeen
+		FROM TestResults
+		WHERE OrgLogin = @orgLogin AND
+		Done AND
+		FailureFingerprint IS NOT NULL AND
+		FinishTime >= @since
+		GROUP BY FailureFingerprint
+		ORDER BY Runs DESC, LastSeen DESC
+		LIMIT @limit`)
+	stmt.Params["orgLogin"] = orgLogin
+	stmt.Params["since"] = since
+	stmt.Params["limit"] = int64(limit)
+
+	iter := s.client.Single().Query(context, stmt)
+	err := iter.Do(func(row *spanner.Row) error {
+		cluster := &storage.FailureCluster{}
+		if err := rowToStruct(row, cluster); err != nil {
+			return err
+		}
+
+		return cb(cluster)
+	})
+
+	return err
+}
+
 func (s store) QueryLatestBaseSha(context context.Context) (*storage.LatestBaseShaSummary, error) {
 	sql := `SELECT BaseSha, COUNT(TestOutcomes.TestOutcomeName) AS NumberOfTest, MAX(FinishTime) AS LastFinishTime
 			FROM PostSubmitTestResults
@@ -207,6 +207,38 @@ func (s store) ReadBotActivity(context context.Context, orgLogin string, repoNam
 	return &result, nil
 }
 
+func (s store) ReadAuthorWait(context context.Context, orgLogin string, repoName string, issueNumber int) (*storage.AuthorWait, error) {
+	row, err := s.client.Single().ReadRow(context, authorWaitTable, authorWaitKey(orgLogin, repoName, int64(issueNumber)), authorWaitColumns)
+	if spanner.ErrCode(err) == codes.NotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var result storage.AuthorWait
+	if err := rowToStruct(row, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (s store) ReadFlakeIssue(context context.Context, orgLogin string, repoName string, testName string) (*storage.FlakeIssue, error) {
+	row, err := s.client.Single().ReadRow(context, flakeIssueTable, flakeIssueKey(orgLogin, repoName, testName), flakeIssueColumns)
+	if spanner.ErrCode(err) == codes.NotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var result storage.FlakeIssue
+	if err := rowToStruct(row, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
 func (s store) ReadTestResult(context context.Context, orgLogin string,
 	repoName string, testName string, pullRequestNumber int64, runNum int64,
 ) (*storage.TestResult, error) {
@@ -33,11 +33,14 @@ const (
 	labelTable                         = "Labels"
 	issueTable                         = "Issues"
 	issueCommentTable                  = "IssueComments"
+	issueFieldTable                    = "IssueFields"
 	pullRequestTable                   = "PullRequests"
 	pullRequestReviewCommentTable      = "PullRequestReviewComments"
 	pullRequestReviewTable             = "PullRequestReviews"
 	memberTable                        = "Members"
 	botActivityTable                   = "BotActivity"
+	botLabelTable                      = "BotLabels"
+	backportTable                      = "Backports"
 	maintainerTable                    = "Maintainers"
 	issueEventTable                    = "IssueEvents"
 	issueCommentEventTable             = "IssueCommentEvents"
@@ -46,6 +49,7 @@ const (
 	pullRequestReviewEventTable        = "PullRequestReviewEvents"
 	repoCommentEventTable              = "RepoCommentEvents"
 	testResultTable                    = "TestResults"
+	testCaseResultTable                = "TestCaseResults"
 	postSubmitTestResultTable          = "PostSubmitTestResults"
 	suiteOutcomesTable                 = "SuiteOutcomes"
 	testOutcomeTable                   = "TestOutcomes"
@@ -53,6 +57,13 @@ const (
 	coverageDataTable                  = "CoverageData"
 	userAffiliationTable               = "UserAffiliation"
 	confirmedFlakesTable               = "ConfirmedFlakes"
+	testFlakeDayTable                  = "TestFlakeDays"
+	confirmedTestCaseFlakesTable       = "ConfirmedTestCaseFlakes"
+	flakeIssueTable                    = "FlakeIssues"
+	welcomeTable                       = "Welcomes"
+	mentorAssignmentTable              = "MentorAssignments"
+	authorWaitTable                    = "AuthorWaits"
+	lifecycleEventTable                = "LifecycleEvents"
 	monitorStatus                      = "MonitorStatus"
 )
 
@@ -72,6 +83,8 @@ var (
 	memberColumns                   []string
 	testResultColumns               []string
 	monitorStatusColumns            []string
+	authorWaitColumns               []string
+	flakeIssueColumns               []string
 )
 
 // Bunch of functions to from keys for the tables and indices in the DB
@@ -120,6 +133,18 @@ func botActivityKey(orgLogin string, repoName string) spanner.Key {
 	return spanner.Key{orgLogin, repoName}
 }
 
+func botLabelKey(orgLogin string, repoName string, issueNumber int64, labelName string) spanner.Key {
+	return spanner.Key{orgLogin, repoName, issueNumber, labelName}
+}
+
+func authorWaitKey(orgLogin string, repoName string, issueNumber int64) spanner.Key {
+	return spanner.Key{orgLogin, repoName, issueNumber}
+}
+
+func flakeIssueKey(orgLogin string, repoName string, testName string) spanner.Key {
+	return spanner.Key{orgLogin, repoName, testName}
+}
+
 func maintainerKey(orgLogin string, userLogin string) spanner.Key {
 	return spanner.Key{orgLogin, userLogin}
 }
@@ -132,6 +157,10 @@ func testResultKey(orgLogin string, repoName string, testName string, prNum int6
 	return spanner.Key{orgLogin, repoName, testName, prNum, runNumber}
 }
 
+func testResultRunKey(orgLogin string, repoName string, testName string, prNum int64, runNumber int64, done bool) spanner.Key {
+	return spanner.Key{orgLogin, repoName, testName, prNum, runNumber, done}
+}
+
 func testPostSubmitResultKey(orgLogin string, repoName string, testName string, runNumber int64) spanner.Key {
 	return spanner.Key{orgLogin, repoName, testName, runNumber}
 }
@@ -150,14 +179,19 @@ func init() {
 	memberColumns = getFields(storage.Member{})
 	testResultColumns = getFields(storage.TestResult{})
 	monitorStatusColumns = getFields(storage.Monitor{})
+	authorWaitColumns = getFields(storage.AuthorWait{})
+	flakeIssueColumns = getFields(storage.FlakeIssue{})
 }
 
 // Produces a string array representing all the fields in the input object
 func getFields(o interface{}) []string {
 	t := reflect.TypeOf(o)
-	result := make([]string, t.NumField())
+	result := make([]string, 0, t.NumField())
 	for i := 0; i < t.NumField(); i++ {
-		result[i] = t.Field(i).Name
+		if t.Field(i).Tag.Get("spanner") == "-" {
+			continue
+		}
+		result = append(result, t.Field(i).Name)
 	}
 
 	return result
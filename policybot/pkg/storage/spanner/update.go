@@ -16,11 +16,13 @@ package spanner
 
 import (
 	"context"
+	"time"
 
 	"cloud.google.com/go/spanner"
 	"github.com/hashicorp/go-multierror"
 	"google.golang.org/grpc/codes"
 
+	"istio.io/bots/policybot/pkg/pipeline"
 	"istio.io/bots/policybot/pkg/storage"
 )
 
@@ -53,6 +55,156 @@ func (s store) UpdateFlakeCache(ctx context.Context) (sum int, multierr error) {
 	return sum, multierr
 }
 
+// UpdateTestFlakeDays recomputes the daily flake aggregates since the given time. Whole days are recomputed,
+// since flakes are confirmed some time after the failing run finished.
+func (s store) UpdateTestFlakeDays(ctx context.Context, since time.Time) (sum int, err error) {
+	since = since.UTC().Truncate(24 * time.Hour)
+
+	var iter *spanner.RowIterator
+	lp := pipeline.IterProducer{
+		Setup: func() error {
+			stmt := spanner.NewStatement(`SELECT r.OrgLogin, r.RepoName, r.TestName,
+				TIMESTAMP_TRUNC(r.FinishTime, DAY, "UTC") AS Day,
+				COUNT(*) AS Runs,
+				COUNTIF(r.TestPassed = FALSE) AS Failures,
+				COUNTIF(f.RunNumber IS NOT NULL) AS FlakyFailures,
+				ARRAY_AGG(DISTINCT IF(f.RunNumber IS NOT NULL, r.PullRequestNumber, NULL) IGNORE NULLS) AS PullRequests
+				FROM TestResults AS r
+				LEFT JOIN (SELECT DISTINCT OrgLogin, RepoName, TestName, PullRequestNumber, RunNumber FROM ConfirmedFlakes) AS f
+				ON f.OrgLogin = r.OrgLogin AND
+				f.RepoName = r.RepoName AND
+				f.TestName = r.TestName AND
+				f.PullRequestNumber = r.PullRequestNumber AND
+				f.RunNumber = r.RunNumber
+				WHERE r.Done AND
+				NOT r.CloneFailed AND
+				r.Result != 'ABORTED' AND
+				r.FinishTime >= @since
+				GROUP BY r.OrgLogin, r.RepoName, r.TestName, Day`)
+			stmt.Params["since"] = since
+			iter = s.client.Single().Query(ctx, stmt)
+			return nil
+		},
+		Iterator: func() (res interface{}, err error) {
+			row, err := iter.Next()
+			if err == nil {
+				res = &storage.TestFlakeDay{}
+				err = rowToStruct(row, res)
+			}
+			return res, err
+		},
+	}
+
+	errchan := pipeline.FromIter(lp).OnError(func(err error) {
+		scope.Warnf("Error aggregating test flakes: %v", err)
+	}).Batch(2000).To(func(input interface{}) (err error) {
+		islice := input.([]interface{})
+		mutations := make([]*spanner.Mutation, len(islice))
+		for x, i := range islice {
+			if mutations[x], err = insertOrUpdateStruct(testFlakeDayTable, i.(*storage.TestFlakeDay)); err != nil {
+				return err
+			}
+		}
+
+		if _, err = s.client.Apply(ctx, mutations); err == nil {
+			sum += len(mutations)
+		}
+		return err
+	}).Go()
+
+	var result *multierror.Error
+	for err := range errchan {
+		result = multierror.Append(result, err.Err())
+	}
+	return sum, result.ErrorOrNil()
+}
+
+// UpdateTestCaseFlakeCache confirms the test cases that flaked since the given time, so that reporting on them
+// doesn't have to look for passing retries of each failure.
+func (s store) UpdateTestCaseFlakeCache(ctx context.Context, since time.Time) (sum int, err error) {
+	var iter *spanner.RowIterator
+	lp := pipeline.IterProducer{
+		Setup: func() error {
+			stmt := spanner.NewStatement(`SELECT c.OrgLogin, c.RepoName, c.TestName, c.PullRequestNumber, c.RunNumber, c.Done,
+				c.PackageName, c.CaseName, MIN(p.RunNumber) AS PassingRunNumber, c.FinishTime
+				FROM TestCaseResults AS c
+				JOIN TestResults AS r
+				ON r.OrgLogin = c.OrgLogin AND
+				r.RepoName = c.RepoName AND
+				r.TestName = c.TestName AND
+				r.PullRequestNumber = c.PullRequestNumber AND
+				r.RunNumber = c.RunNumber AND
+				r.Done = c.Done
+				JOIN TestCaseResults AS p
+				ON p.OrgLogin = c.OrgLogin AND
+				p.RepoName = c.RepoName AND
+				p.TestName = c.TestName AND
+				p.PullRequestNumber = c.PullRequestNumber AND
+				p.RunNumber != c.RunNumber AND
+				p.PackageName = c.PackageName AND
+				p.CaseName = c.CaseName AND
+				p.Status = 'passed'
+				JOIN TestResults AS pr
+				ON pr.OrgLogin = p.OrgLogin AND
+				pr.RepoName = p.RepoName AND
+				pr.TestName = p.TestName AND
+				pr.PullRequestNumber = p.PullRequestNumber AND
+				pr.RunNumber = p.RunNumber AND
+				pr.Done = p.Done AND
+				pr.Sha = r.Sha
+				LEFT JOIN ConfirmedTestCaseFlakes AS f
+				ON f.OrgLogin = c.OrgLogin AND
+				f.RepoName = c.RepoName AND
+				f.TestName = c.TestName AND
+				f.PullRequestNumber = c.PullRequestNumber AND
+				f.RunNumber = c.RunNumber AND
+				f.Done = c.Done AND
+				f.PackageName = c.PackageName AND
+				f.CaseName = c.CaseName
+				WHERE c.Status != 'passed' AND
+				c.Status != 'skipped' AND
+				c.FinishTime >= @since AND
+				f.RunNumber IS NULL
+				GROUP BY c.OrgLogin, c.RepoName, c.TestName, c.PullRequestNumber, c.RunNumber, c.Done,
+				c.PackageName, c.CaseName, c.FinishTime`)
+			stmt.Params["since"] = since
+			iter = s.client.Single().Query(ctx, stmt)
+			return nil
+		},
+		Iterator: func() (res interface{}, err error) {
+			row, err := iter.Next()
+			if err == nil {
+				res = &storage.ConfirmedTestCaseFlake{}
+				err = rowToStruct(row, res)
+			}
+			return res, err
+		},
+	}
+
+	errchan := pipeline.FromIter(lp).OnError(func(err error) {
+		scope.Warnf("Error confirming test case flakes: %v", err)
+	}).Batch(2000).To(func(input interface{}) (err error) {
+		islice := input.([]interface{})
+		mutations := make([]*spanner.Mutation, len(islice))
+		for x, i := range islice {
+			if mutations[x], err = insertOrUpdateStruct(confirmedTestCaseFlakesTable, i.(*storage.ConfirmedTestCaseFlake)); err != nil {
+				return err
+			}
+		}
+
+		if _, err = s.client.Apply(ctx, mutations); err == nil {
+			sum += len(mutations)
+		}
+		return err
+	}).Go()
+
+	var result *multierror.Error
+	for err := range errchan {
+		result = multierror.Append(result, err.Err())
+	}
+	return sum, result.ErrorOrNil()
+}
+
 func (s store) UpdateBotActivity(ctx1 context.Context, orgLogin string, repoName string, cb func(*storage.BotActivity) error) error {
 	scope.Debugf("Updating bot activity for repo %s/%s", orgLogin, repoName)
 
@@ -70,12 +70,26 @@ func (s store) WriteRepoComments(context context.Context, comments []*storage.Re
 func (s store) WriteIssues(context context.Context, issues []*storage.Issue) error {
 	scope.Debugf("Writing %d issues", len(issues))
 
-	mutations := make([]*spanner.Mutation, len(issues))
-	for i := 0; i < len(issues); i++ {
-		var err error
-		if mutations[i], err = insertOrUpdateStruct(issueTable, issues[i]); err != nil {
+	mutations := make([]*spanner.Mutation, 0, len(issues))
+	for _, issue := range issues {
+		m, err := insertOrUpdateStruct(issueTable, issue)
+		if err != nil {
 			return err
 		}
+		mutations = append(mutations, m)
+
+		if issue.Fields == nil {
+			continue
+		}
+
+		// replace whatever fields the issue had before
+		mutations = append(mutations, spanner.Delete(issueFieldTable, issueKey(issue.OrgLogin, issue.RepoName, issue.IssueNumber).AsPrefix()))
+		for _, f := range issue.Fields {
+			if m, err = insertOrUpdateStruct(issueFieldTable, f); err != nil {
+				return err
+			}
+			mutations = append(mutations, m)
+		}
 	}
 
 	_, err := s.client.Apply(context, mutations)
@@ -262,6 +276,153 @@ func (s store) WriteBotActivities(context context.Context, activities []*storage
 	return err
 }
 
+func (s store) WriteBotLabels(context context.Context, labels []*storage.BotLabel) error {
+	scope.Debugf("Writing %d bot labels", len(labels))
+
+	mutations := make([]*spanner.Mutation, len(labels))
+	for i := 0; i < len(labels); i++ {
+		var err error
+		if mutations[i], err = insertOrUpdateStruct(botLabelTable, labels[i]); err != nil {
+			return err
+		}
+	}
+
+	_, err := s.client.Apply(context, mutations)
+	return err
+}
+
+func (s store) WriteWelcomes(context context.Context, welcomes []*storage.Welcome) error {
+	scope.Debugf("Writing %d welcomes", len(welcomes))
+
+	mutations := make([]*spanner.Mutation, len(welcomes))
+	for i := 0; i < len(welcomes); i++ {
+		var err error
+		if mutations[i], err = insertOrUpdateStruct(welcomeTable, welcomes[i]); err != nil {
+			return err
+		}
+	}
+
+	_, err := s.client.Apply(context, mutations)
+	return err
+}
+
+func (s store) WriteMentorAssignments(context context.Context, assignments []*storage.MentorAssignment) error {
+	scope.Debugf("Writing %d mentor assignments", len(assignments))
+
+	mutations := make([]*spanner.Mutation, len(assignments))
+	for i := 0; i < len(assignments); i++ {
+		var err error
+		if mutations[i], err = insertOrUpdateStruct(mentorAssignmentTable, assignments[i]); err != nil {
+			return err
+		}
+	}
+
+	_, err := s.client.Apply(context, mutations)
+	return err
+}
+
+func (s store) DeleteBotLabels(context context.Context, labels []*storage.BotLabel) error {
+	scope.Debugf("Deleting %d bot labels", len(labels))
+
+	mutations := make([]*spanner.Mutation, len(labels))
+	for i, l := range labels {
+		mutations[i] = spanner.Delete(botLabelTable, botLabelKey(l.OrgLogin, l.RepoName, l.IssueNumber, l.LabelName))
+	}
+
+	_, err := s.client.Apply(context, mutations)
+	return err
+}
+
+func (s store) WriteAuthorWaits(context context.Context, waits []*storage.AuthorWait) error {
+	scope.Debugf("Writing %d author waits", len(waits))
+
+	mutations := make([]*spanner.Mutation, len(waits))
+	for i := 0; i < len(waits); i++ {
+		var err error
+		if mutations[i], err = insertOrUpdateStruct(authorWaitTable, waits[i]); err != nil {
+			return err
+		}
+	}
+
+	_, err := s.client.Apply(context, mutations)
+	return err
+}
+
+func (s store) DeleteAuthorWaits(context context.Context, waits []*storage.AuthorWait) error {
+	scope.Debugf("Deleting %d author waits", len(waits))
+
+	mutations := make([]*spanner.Mutation, len(waits))
+	for i, w := range waits {
+		mutations[i] = spanner.Delete(authorWaitTable, authorWaitKey(w.OrgLogin, w.RepoName, w.IssueNumber))
+	}
+
+	_, err := s.client.Apply(context, mutations)
+	return err
+}
+
+func (s store) WriteLifecycleEvents(context context.Context, events []*storage.LifecycleEvent) error {
+	scope.Debugf("Writing %d lifecycle events", len(events))
+
+	mutations := make([]*spanner.Mutation, len(events))
+	for i := 0; i < len(events); i++ {
+		var err error
+		if mutations[i], err = insertOrUpdateStruct(lifecycleEventTable, events[i]); err != nil {
+			return err
+		}
+	}
+
+	_, err := s.client.Apply(context, mutations)
+	return err
+}
+
+func (s store) WriteFlakeIssues(context context.Context, issues []*storage.FlakeIssue) error {
+	scope.Debugf("Writing %d flake issues", len(issues))
+
+	mutations := make([]*spanner.Mutation, len(issues))
+	for i := 0; i < len(issues); i++ {
+		var err error
+		if mutations[i], err = insertOrUpdateStruct(flakeIssueTable, issues[i]); err != nil {
+			return err
+		}
+	}
+
+	_, err := s.client.Apply(context, mutations)
+	return err
+}
+
+func (s store) WriteConfirmedFlakes(context context.Context, flakes []*storage.ConfirmedFlake) error {
+	scope.Debugf("Writing %d confirmed flakes", len(flakes))
+
+	mutations := make([]*spanner.Mutation, len(flakes))
+	for i := 0; i < len(flakes); i++ {
+		var err error
+		if mutations[i], err = insertOrUpdateStruct(confirmedFlakesTable, flakes[i]); err != nil {
+			return err
+		}
+	}
+
+	_, err := s.client.Apply(context, mutations)
+	return err
+}
+
+func (s store) WriteBackports(context context.Context, backports []*storage.Backport) error {
+	scope.Debugf("Writing %d backports", len(backports))
+
+	mutations := make([]*spanner.Mutation, len(backports))
+	for i := 0; i < len(backports); i++ {
+		var err error
+		if mutations[i], err = insertOrUpdateStruct(backportTable, backports[i]); err != nil {
+			return err
+		}
+	}
+
+	_, err := s.client.Apply(context, mutations)
+	return err
+}
+
+// the most test case rows written in a single commit, well under Spanner's limit on mutations per commit
+const testCaseBatchSize = 1000
+
 func (s store) WriteTestResults(context context.Context, testResults []*storage.TestResult) error {
 	scope.Debugf("Writing %d test results", len(testResults))
 
@@ -273,8 +434,50 @@ func (s store) WriteTestResults(context context.Context, testResults []*storage.
 		}
 	}
 
-	_, err := s.client.Apply(context, mutations)
-	return err
+	if _, err := s.client.Apply(context, mutations); err != nil {
+		return err
+	}
+
+	// a job can run thousands of test cases, so they're written in batches once their runs exist
+	mutations = mutations[:0]
+	for _, tr := range testResults {
+		if tr.TestCases == nil {
+			continue
+		}
+
+		// replace whatever cases the run had before
+		key := testResultRunKey(tr.OrgLogin, tr.RepoName, tr.TestName, tr.PullRequestNumber, tr.RunNumber, tr.Done)
+		mutations = append(mutations, spanner.Delete(testCaseResultTable, key.AsPrefix()))
+		for _, tc := range tr.TestCases {
+			tc.OrgLogin = tr.OrgLogin
+			tc.RepoName = tr.RepoName
+			tc.TestName = tr.TestName
+			tc.PullRequestNumber = tr.PullRequestNumber
+			tc.RunNumber = tr.RunNumber
+			tc.Done = tr.Done
+			tc.FinishTime = tr.FinishTime
+
+			m, err := insertOrUpdateStruct(testCaseResultTable, tc)
+			if err != nil {
+				return err
+			}
+			mutations = append(mutations, m)
+
+			if len(mutations) >= testCaseBatchSize {
+				if _, err := s.client.Apply(context, mutations); err != nil {
+					return err
+				}
+				mutations = mutations[:0]
+			}
+		}
+	}
+
+	if len(mutations) > 0 {
+		_, err := s.client.Apply(context, mutations)
+		return err
+	}
+
+	return nil
 }
 
 func (s store) WritePostSumbitTestResults(context context.Context, postSubmitTestResults []*storage.PostSubmitTestResult) error {
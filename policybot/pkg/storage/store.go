@@ -37,6 +37,16 @@ type Store interface {
 	WriteAllMembers(context context.Context, members []*Member) error
 	WriteAllMaintainers(context context.Context, maintainers []*Maintainer) error
 	WriteBotActivities(context context.Context, activities []*BotActivity) error
+	WriteBotLabels(context context.Context, labels []*BotLabel) error
+	DeleteBotLabels(context context.Context, labels []*BotLabel) error
+	WriteWelcomes(context context.Context, welcomes []*Welcome) error
+	WriteMentorAssignments(context context.Context, assignments []*MentorAssignment) error
+	WriteAuthorWaits(context context.Context, waits []*AuthorWait) error
+	DeleteAuthorWaits(context context.Context, waits []*AuthorWait) error
+	WriteFlakeIssues(context context.Context, issues []*FlakeIssue) error
+	WriteConfirmedFlakes(context context.Context, flakes []*ConfirmedFlake) error
+	WriteLifecycleEvents(context context.Context, events []*LifecycleEvent) error
+	WriteBackports(context context.Context, backports []*Backport) error
 	WriteTestResults(context context.Context, testResults []*TestResult) error
 	WritePostSumbitTestResults(context context.Context, postSubmitTestResults []*PostSubmitTestResult) error
 	WriteSuiteOutcome(context context.Context, suiteOutcomes []*SuiteOutcome) error
@@ -52,6 +62,10 @@ type Store interface {
 	WriteAllUserAffiliations(context context.Context, affiliation []*UserAffiliation) error
 	UpdateBotActivity(context context.Context, orgLogin string, repoName string, cb func(*BotActivity) error) error
 	UpdateFlakeCache(context context.Context) (int, error)
+	// UpdateTestFlakeDays recomputes the daily flake aggregates of every test for the days since the given time
+	UpdateTestFlakeDays(context context.Context, since time.Time) (int, error)
+	// UpdateTestCaseFlakeCache records the test cases that failed since the given time and then passed on a retry
+	UpdateTestCaseFlakeCache(context context.Context, since time.Time) (int, error)
 	ReadOrg(context context.Context, orgLogin string) (*Org, error)
 	ReadRepo(context context.Context, orgLogin string, repoName string) (*Repo, error)
 	ReadIssue(context context.Context, orgLogin string, repoName string, number int) (*Issue, error)
@@ -61,6 +75,8 @@ type Store interface {
 	ReadPullRequest(context context.Context, orgLogin string, repoName string, prNumber int) (*PullRequest, error)
 	ReadPullRequestReviewComment(context context.Context, orgLogin string, repoName string, prNumber int, prCommentID int) (*PullRequestReviewComment, error)
 	ReadPullRequestReview(context context.Context, orgLogin string, repoName string, prNumber int, prReviewID int) (*PullRequestReview, error)
+	ReadAuthorWait(context context.Context, orgLogin string, repoName string, issueNumber int) (*AuthorWait, error)
+	ReadFlakeIssue(context context.Context, orgLogin string, repoName string, testName string) (*FlakeIssue, error)
 	ReadBotActivity(context context.Context, orgLogin string, repoName string) (*BotActivity, error)
 	ReadMaintainer(context context.Context, orgLogin string, userLogin string) (*Maintainer, error)
 	ReadMember(context context.Context, orgLogin string, userLogin string) (*Member, error)
@@ -75,6 +91,11 @@ type Store interface {
 	QueryIssuesByRepo(context context.Context, orgLogin string, repoName string, cb func(*Issue) error) error
 	QueryOpenIssues(context context.Context, orgLogin string, cb func(*Issue) error) error
 	QueryOpenIssuesByRepo(context context.Context, orgLogin string, repoName string, cb func(*Issue) error) error
+	// QueryOpenIssuesByField returns the open issues whose named field has a value matching the regular expression
+	QueryOpenIssuesByField(context context.Context, orgLogin string, fieldName string, valueRegex string, cb func(*Issue) error) error
+	// QueryOpenIssueFields returns the named field of all open issues that have it
+	QueryOpenIssueFields(context context.Context, orgLogin string, fieldName string, cb func(*IssueField) error) error
+	QueryIssueFieldsByIssue(context context.Context, orgLogin string, repoName string, issueNumber int, cb func(*IssueField) error) error
 	QueryTestResultByPrNumber(context context.Context, orgLogin string, repoName string, pullRequestNumber int64, cb func(*TestResult) error) error
 	QueryTestResultByUndone(context context.Context, orgLogin string, repoName string, cb func(*TestResult) error) error
 	QueryTestResultByDone(context context.Context, orgLogin string, repoName string, cb func(*TestResult) error) error
@@ -85,16 +106,51 @@ type Store interface {
 	QueryCoverageDataBySHA(context context.Context, orgLogin string, repoName string, sha string, cb func(*CoverageData) error) error
 	QueryAllUserAffiliations(context context.Context, cb func(affiliation *UserAffiliation) error) error
 	QueryAllUsers(context context.Context, cb func(user *User) error) error
+	QueryBotLabelsByIssue(context context.Context, orgLogin string, repoName string, issueNumber int, cb func(*BotLabel) error) error
+	QueryBackports(context context.Context, orgLogin string, cb func(*Backport) error) error
+	QueryBackportsByPullRequest(context context.Context, orgLogin string, repoName string, prNumber int, cb func(*Backport) error) error
+	QueryMergedPullRequestsByMilestone(context context.Context, orgLogin string, repoName string, milestone string, branchName string,
+		cb func(*PullRequest) error) error
 	QueryPullRequestsByUser(context context.Context, orgLogin string, repoName string, userLogin string, cb func(*PullRequest) error) error
+	QueryIssuesByUser(context context.Context, orgLogin string, repoName string, userLogin string, cb func(*Issue) error) error
+	QueryWelcomesByUser(context context.Context, orgLogin string, userLogin string, cb func(*Welcome) error) error
+	QueryMentorAssignments(context context.Context, orgLogin string, cb func(*MentorAssignment) error) error
+	QueryMentorAssignmentsByPullRequest(context context.Context, orgLogin string, repoName string, prNumber int,
+		cb func(*MentorAssignment) error) error
+	// QueryLifecycleEventsByIssue returns the lifecycle events of an issue or PR, newest first
+	QueryLifecycleEventsByIssue(context context.Context, orgLogin string, repoName string, issueNumber int, cb func(*LifecycleEvent) error) error
+	// QueryLifecycleEventsByRepo returns the lifecycle events of a repo recorded since the given time, newest first
+	QueryLifecycleEventsByRepo(context context.Context, orgLogin string, repoName string, since time.Time, cb func(*LifecycleEvent) error) error
 	QueryLatestBaseSha(context context.Context) (*LatestBaseShaSummary, error)
 	QueryAllBaseSha(context context.Context) ([]string, error)
 	QueryPostSubmitTestEnvLabel(context context.Context, baseSha string, cb func(*PostSubmitTestEnvLabel) error) error
 	QueryTestNameByEnvLabel(context context.Context, baseSha string, env string, label string) ([]*TestNameByEnvLabel, error)
 	QueryTestFlakeIssues(context context.Context, orgLogin string, repoName string, inactiveDays, createdDays int) ([]*Issue, error)
+	// QueryTestFlakeDays returns the daily flake aggregates of an org's tests since the given time
+	QueryTestFlakeDays(context context.Context, orgLogin string, since time.Time, cb func(*TestFlakeDay) error) error
+	// QueryOpenFlakeIssues returns the flake issues of a repo that haven't been closed for being quiet
+	QueryOpenFlakeIssues(context context.Context, orgLogin string, repoName string, cb func(*FlakeIssue) error) error
+	// QueryUnfiledFlakes returns the confirmed flakes of a repo not yet linked to an issue whose failed run finished
+	// in the given time range, oldest first
+	QueryUnfiledFlakes(context context.Context, orgLogin string, repoName string, since time.Time, until time.Time,
+		cb func(*FlakeOccurrence) error) error
+	// QueryTestFailures returns the failed pre-submit runs of a test since the given time, newest first
+	QueryTestFailures(context context.Context, orgLogin string, repoName string, testName string, since time.Time,
+		cb func(*TestFailure) error) error
+	// QueryTestCaseFlakes returns the test cases of a job that flaked since the given time, flakiest first
+	QueryTestCaseFlakes(context context.Context, orgLogin string, repoName string, testName string, since time.Time,
+		cb func(*TestCaseFlake) error) error
+	// QueryTestCaseHistory returns the runs of a test case across all jobs since the given time, newest first
+	QueryTestCaseHistory(context context.Context, orgLogin string, repoName string, packageName string, caseName string,
+		since time.Time, cb func(*TestCaseResult) error) error
+	// QueryFailureClusters returns the largest clusters of unrecognized failures in an org since the given time
+	QueryFailureClusters(context context.Context, orgLogin string, since time.Time, limit int, cb func(*FailureCluster) error) error
 	// QueryMonitorStatus queries monitor status of release qualification test
 	QueryMonitorStatus(context context.Context, cb func(*Monitor) error) error
 	// QueryReleaseQualTestMetadata queries release qualification test metadata
 	QueryReleaseQualTestMetadata(context context.Context, cb func(metadata *ReleaseQualTestMetadata) error) error
 	GetLatestIssueMemberActivity(context context.Context, orgLogin string, repoName string, issueNumber int) (time.Time, error)
 	GetLatestIssueMemberComment(context context.Context, orgLogin string, repoName string, issueNumber int) (time.Time, error)
+	// GetLatestIssueUserComment returns when a user last commented on an issue or PR, or the zero time if they never did
+	GetLatestIssueUserComment(context context.Context, orgLogin string, repoName string, issueNumber int, userLogin string) (time.Time, error)
 }
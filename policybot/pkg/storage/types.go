@@ -44,6 +44,20 @@ type Issue struct {
 	State       string
 	Author      string
 	Assignees   []string
+
+	// Fields holds the sections of the issue body, stored in their own table. A nil value leaves the
+	// stored fields as they are.
+	Fields []*IssueField `spanner:"-"`
+}
+
+// IssueField is a section of an issue body, as produced by issue forms and templates.
+type IssueField struct {
+	OrgLogin    string
+	RepoName    string
+	IssueNumber int64
+	FieldName   string
+	Heading     string
+	Value       string
 }
 
 type IssueComment struct {
@@ -105,6 +119,7 @@ type PullRequest struct {
 	BranchName         string
 	HeadCommit         string
 	Merged             bool
+	Milestone          *string
 }
 
 type PullRequestReviewComment struct {
@@ -185,6 +200,78 @@ type BotActivity struct {
 	LastPullRequestReviewCommentSyncStart time.Time
 }
 
+// BotLabel records a label the bot applied to an issue or PR, so that it can later tell its own labels
+// apart from those added by humans.
+type BotLabel struct {
+	OrgLogin    string
+	RepoName    string
+	IssueNumber int64 // an issue or PR number
+	LabelName   string
+	AppliedAt   time.Time
+
+	// ReappliedBy is the person who added the label back by hand after the bot applied it, if any. The label
+	// is theirs from then on, so the bot no longer removes it.
+	ReappliedBy *string
+}
+
+// Welcome records that a user was greeted by the welcome wagon in a repo. It is kept apart from the issue
+// and PR tables, which get rebuilt from GitHub, so that people aren't welcomed twice.
+type Welcome struct {
+	OrgLogin   string
+	UserLogin  string
+	RepoName   string
+	Kind       string // either "issue" or "pr"
+	Number     int64  // the issue or PR that carries the welcome message
+	WelcomedAt time.Time
+}
+
+// MentorAssignment records a mentor asked to look after the PR of a new contributor.
+type MentorAssignment struct {
+	OrgLogin          string
+	RepoName          string
+	PullRequestNumber int64
+	Mentor            string
+	Author            string
+	AssignedAt        time.Time
+	RespondedAt       *time.Time // when the mentor first reviewed or commented on the PR
+}
+
+// AuthorWait tracks an issue or PR on which the lifecycle manager is waiting for its author to respond.
+type AuthorWait struct {
+	OrgLogin    string
+	RepoName    string
+	IssueNumber int64 // an issue or PR number
+	Author      string
+	StartedAt   time.Time
+	NudgedAt    *time.Time // when the author was reminded that a response is expected
+	ClosedAt    *time.Time // when the bot closed the issue or PR for lack of a response
+}
+
+// LifecycleEvent records a change the lifecycle manager made to an issue or PR, along with why it was made.
+type LifecycleEvent struct {
+	OrgLogin    string
+	RepoName    string
+	IssueNumber int64 // an issue or PR number
+	RecordedAt  time.Time
+	Transition  string     // what changed, such as "marked-stale" or "closed"
+	Policy      string     // the lifecycle policy the issue or PR followed
+	Reason      string     // a human-readable explanation of the change
+	ClockStart  time.Time  // the start of the clock that led to the change, such as the latest member comment
+	Deadline    *time.Time // when the next change is due, if one is
+}
+
+// Backport tracks the automated cherry-pick of a merged PR to another branch.
+type Backport struct {
+	OrgLogin          string
+	RepoName          string
+	PullRequestNumber int64  // the original PR
+	TargetBranch      string // the branch being backported to
+	Status            string // one of "created", "conflict", or "failed"
+	BackportNumber    int64  // the backport PR, when one was created
+	Details           string // extra human-readable information, such as conflicting files
+	UpdatedAt         time.Time
+}
+
 type Maintainer struct {
 	OrgLogin   string
 	UserLogin  string
@@ -234,6 +321,57 @@ type TestResult struct {
 	Done              bool
 	HasArtifacts      bool
 	Artifacts         []string
+
+	// For failures not recognized by any signature, the normalized output describing the failure and its fingerprint
+	FailureExcerpt     *string
+	FailureFingerprint *string
+
+	// TestCases holds the cases parsed from the run's JUnit XML artifacts, stored in their own table. Their key
+	// fields are filled in from the result when written. A nil value leaves the stored cases as they are.
+	TestCases []*TestCaseResult `spanner:"-"`
+}
+
+// TestCaseResult is the outcome of a single test case within a test run, as reported by the run's JUnit XML.
+type TestCaseResult struct {
+	OrgLogin          string
+	RepoName          string
+	TestName          string
+	PullRequestNumber int64
+	RunNumber         int64
+	Done              bool
+	PackageName       string
+	CaseName          string
+	FinishTime        time.Time // when the run finished, to query a case's history without joining its run
+	Duration          float64   // in seconds
+	Status            string    // passed, failed, error, or skipped
+	FailureMessage    *string
+}
+
+// TestCaseFlake summarizes how often a test case within a job failed and then passed on a retry at the same commit.
+type TestCaseFlake struct {
+	PackageName   string
+	CaseName      string
+	Runs          int64 // the number of runs of the case, not counting skips
+	Failures      int64
+	FlakyFailures int64 // the failures the case passed on retry
+	PullRequests  int64 // the number of distinct pull requests the case flaked on
+	MeanDuration  float64
+	LastFlaked    time.Time
+}
+
+// ConfirmedTestCaseFlake is a test case that failed in a run and passed in another run of the same job on the
+// same pull request and commit.
+type ConfirmedTestCaseFlake struct {
+	OrgLogin          string
+	RepoName          string
+	TestName          string
+	PullRequestNumber int64
+	RunNumber         int64
+	Done              bool
+	PackageName       string
+	CaseName          string
+	PassingRunNumber  int64     // the first run that passed the case
+	FinishTime        time.Time // when the failed run finished
 }
 
 type PostSubmitTestResult struct {
@@ -427,5 +565,68 @@ type ConfirmedFlake struct {
 	TestName          string
 	Done              bool
 	PassingRunNumber  int64
-	IssueNum          *int64
+	IssueNum          *int64 // the issue the flake is reported on, 0 for flakes that predate issue filing
+}
+
+// TestFlakeDay aggregates one day of a test's pre-submit runs, as the basis for scoring how flaky the test is.
+type TestFlakeDay struct {
+	OrgLogin      string
+	RepoName      string
+	TestName      string
+	Day           time.Time // midnight UTC of the day the runs finished
+	Runs          int64
+	Failures      int64
+	FlakyFailures int64   // failures that passed on a retry at the same SHA
+	PullRequests  []int64 // the PRs hit by a flaky failure
+}
+
+// TestFailure is a failed pre-submit run of a test, along with the run that passed on retry if there was one.
+type TestFailure struct {
+	OrgLogin          string
+	RepoName          string
+	TestName          string
+	PullRequestNumber int64
+	RunNumber         int64
+	FinishTime        time.Time
+	RunPath           string
+	Result            string
+	Signatures        []string
+	PassingRunNumber  *int64
+}
+
+// FlakeIssue tracks the GitHub issue filed for a flaky test.
+type FlakeIssue struct {
+	OrgLogin    string
+	RepoName    string
+	TestName    string
+	IssueNumber int64
+	OpenedAt    time.Time
+	LastSeenAt  time.Time  // when the test was last seen flaking
+	Occurrences int64      // the number of flaky runs reported on the issue
+	ClosedAt    *time.Time // when the issue was closed for being quiet, nil while open
+}
+
+// FlakeOccurrence is a confirmed flake that isn't yet linked to an issue, along with where to find its runs.
+type FlakeOccurrence struct {
+	OrgLogin          string
+	RepoName          string
+	TestName          string
+	PullRequestNumber int64
+	RunNumber         int64
+	Done              bool
+	PassingRunNumber  int64
+	FinishTime        time.Time
+	FailedRunPath     string
+	PassingRunPath    *string
+}
+
+// FailureCluster groups the failed test runs no signature recognized by the fingerprint of their failure excerpt.
+type FailureCluster struct {
+	Fingerprint  string
+	Excerpt      string
+	Runs         int64 // the number of failed runs in the cluster
+	PullRequests int64 // the number of distinct pull requests the runs belong to
+	Tests        int64 // the number of distinct tests the runs belong to
+	FirstSeen    time.Time
+	LastSeen     time.Time
 }
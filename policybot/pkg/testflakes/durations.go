@@ -0,0 +1,61 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testflakes
+
+import (
+	"math"
+	"sort"
+)
+
+// Durations summarizes how long a test case took over a number of runs, in seconds.
+type Durations struct {
+	Runs   int
+	Mean   float64
+	Median float64
+	P90    float64
+	Max    float64
+}
+
+// SummarizeDurations computes the spread of a test case's durations. Percentiles use the nearest-rank method,
+// so they're always durations that were actually seen.
+func SummarizeDurations(durations []float64) Durations {
+	if len(durations) == 0 {
+		return Durations{}
+	}
+
+	sorted := append([]float64(nil), durations...)
+	sort.Float64s(sorted)
+
+	var sum float64
+	for _, d := range sorted {
+		sum += d
+	}
+
+	return Durations{
+		Runs:   len(sorted),
+		Mean:   sum / float64(len(sorted)),
+		Median: percentile(sorted, 50),
+		P90:    percentile(sorted, 90),
+		Max:    sorted[len(sorted)-1],
+	}
+}
+
+func percentile(sorted []float64, p float64) float64 {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
@@ -0,0 +1,50 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testflakes_test
+
+import (
+	"testing"
+
+	"istio.io/bots/policybot/pkg/testflakes"
+)
+
+func TestSummarizeDurations(t *testing.T) {
+	cases := []struct {
+		name      string
+		durations []float64
+		want      testflakes.Durations
+	}{
+		{"none", nil, testflakes.Durations{}},
+		{"single", []float64{2.5}, testflakes.Durations{Runs: 1, Mean: 2.5, Median: 2.5, P90: 2.5, Max: 2.5}},
+		{
+			"unsorted",
+			[]float64{10, 1, 9, 2, 8, 3, 7, 4, 6, 5},
+			testflakes.Durations{Runs: 10, Mean: 5.5, Median: 5, P90: 9, Max: 10},
+		},
+		{
+			"outlier",
+			[]float64{1, 1, 1, 1, 100},
+			testflakes.Durations{Runs: 5, Mean: 20.8, Median: 1, P90: 100, Max: 100},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := testflakes.SummarizeDurations(c.durations); got != c.want {
+				t.Errorf("got %+v, want %+v", got, c.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testflakes
+
+import (
+	"sort"
+	"time"
+
+	store "istio.io/bots/policybot/pkg/storage"
+)
+
+// Number of trailing days covered by the short and long scoring windows.
+const (
+	ShortWindowDays = 7
+	LongWindowDays  = 30
+)
+
+// Orders in which SortScores can arrange scores.
+const (
+	ByShortRate = "rate7"
+	ByLongRate  = "rate30"
+	ByShortPRs  = "prs7"
+	ByLongPRs   = "prs30"
+	ByTrend     = "trend"
+	ByRuns      = "runs"
+	ByTestName  = "test"
+)
+
+// Window summarizes a test's runs over a number of trailing days.
+type Window struct {
+	Runs          int64
+	Failures      int64
+	FlakyFailures int64
+	PullRequests  int     // distinct PRs hit by a flaky failure
+	Rate          float64 // the fraction of runs that failed and then passed on retry
+}
+
+// FlakeScore rates how flaky a test has been lately, rather than merely whether it ever flaked.
+type FlakeScore struct {
+	OrgLogin string
+	RepoName string
+	TestName string
+	Short    Window
+	Long     Window
+
+	// Short.Rate - Long.Rate: positive when the test has been getting flakier
+	Trend float64
+}
+
+type scoreKey struct {
+	orgLogin string
+	repoName string
+	testName string
+}
+
+// ScoreFlakes rolls daily aggregates up into a score per test, as of the given time. Tests that
+// didn't run within the long window are left out.
+func ScoreFlakes(days []*store.TestFlakeDay, now time.Time) []*FlakeScore {
+	today := now.UTC().Truncate(24 * time.Hour)
+	shortStart := today.AddDate(0, 0, -(ShortWindowDays - 1))
+	longStart := today.AddDate(0, 0, -(LongWindowDays - 1))
+
+	scores := make(map[scoreKey]*FlakeScore)
+	shortPRs := make(map[scoreKey]map[int64]bool)
+	longPRs := make(map[scoreKey]map[int64]bool)
+
+	for _, d := range days {
+		day := d.Day.UTC().Truncate(24 * time.Hour)
+		if day.Before(longStart) || day.After(today) {
+			continue
+		}
+
+		key := scoreKey{d.OrgLogin, d.RepoName, d.TestName}
+		fs, ok := scores[key]
+		if !ok {
+			fs = &FlakeScore{OrgLogin: d.OrgLogin, RepoName: d.RepoName, TestName: d.TestName}
+			scores[key] = fs
+			shortPRs[key] = make(map[int64]bool)
+			longPRs[key] = make(map[int64]bool)
+		}
+
+		fs.Long.add(d, longPRs[key])
+		if !day.Before(shortStart) {
+			fs.Short.add(d, shortPRs[key])
+		}
+	}
+
+	result := make([]*FlakeScore, 0, len(scores))
+	for key, fs := range scores {
+		fs.Short.finish(shortPRs[key])
+		fs.Long.finish(longPRs[key])
+		fs.Trend = fs.Short.Rate - fs.Long.Rate
+		result = append(result, fs)
+	}
+
+	SortScores(result, ByShortRate)
+	return result
+}
+
+func (w *Window) add(d *store.TestFlakeDay, prs map[int64]bool) {
+	w.Runs += d.Runs
+	w.Failures += d.Failures
+	w.FlakyFailures += d.FlakyFailures
+	for _, pr := range d.PullRequests {
+		prs[pr] = true
+	}
+}
+
+func (w *Window) finish(prs map[int64]bool) {
+	w.PullRequests = len(prs)
+	if w.Runs > 0 {
+		w.Rate = float64(w.FlakyFailures) / float64(w.Runs)
+	}
+}
+
+// SortScores arranges scores in the given order, flakiest first. Unknown orders sort by the
+// short window's flake rate. Ties are broken by test name so the order is stable.
+func SortScores(scores []*FlakeScore, by string) {
+	sort.Slice(scores, func(i, j int) bool {
+		a, b := scores[i], scores[j]
+		if ka, kb := sortKey(a, by), sortKey(b, by); ka != kb {
+			return ka > kb
+		}
+
+		if a.TestName != b.TestName {
+			return a.TestName < b.TestName
+		}
+
+		if a.RepoName != b.RepoName {
+			return a.RepoName < b.RepoName
+		}
+
+		return a.OrgLogin < b.OrgLogin
+	})
+}
+
+func sortKey(fs *FlakeScore, by string) float64 {
+	switch by {
+	case ByLongRate:
+		return fs.Long.Rate
+	case ByShortPRs:
+		return float64(fs.Short.PullRequests)
+	case ByLongPRs:
+		return float64(fs.Long.PullRequests)
+	case ByTrend:
+		return fs.Trend
+	case ByRuns:
+		return float64(fs.Long.Runs)
+	case ByTestName:
+		return 0
+	default:
+		return fs.Short.Rate
+	}
+}
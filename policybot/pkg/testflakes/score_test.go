@@ -0,0 +1,80 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testflakes_test
+
+import (
+	"testing"
+	"time"
+
+	"istio.io/bots/policybot/pkg/storage"
+	"istio.io/bots/policybot/pkg/testflakes"
+)
+
+func TestScoreFlakes(t *testing.T) {
+	now := time.Date(2026, 10, 18, 15, 0, 0, 0, time.UTC)
+	day := func(daysAgo int) time.Time {
+		return time.Date(2026, 10, 18-daysAgo, 0, 0, 0, 0, time.UTC)
+	}
+
+	days := []*storage.TestFlakeDay{
+		// getting flakier: nothing a few weeks back, lots this week
+		{OrgLogin: "istio", RepoName: "istio", TestName: "integ-pilot", Day: day(20), Runs: 10},
+		{OrgLogin: "istio", RepoName: "istio", TestName: "integ-pilot", Day: day(2), Runs: 10, Failures: 4, FlakyFailures: 3, PullRequests: []int64{1, 2}},
+		{OrgLogin: "istio", RepoName: "istio", TestName: "integ-pilot", Day: day(0), Runs: 10, Failures: 1, FlakyFailures: 1, PullRequests: []int64{2}},
+
+		// getting better: flaky last month, quiet this week
+		{OrgLogin: "istio", RepoName: "istio", TestName: "unit", Day: day(15), Runs: 20, Failures: 8, FlakyFailures: 8, PullRequests: []int64{5, 6, 7}},
+		{OrgLogin: "istio", RepoName: "istio", TestName: "unit", Day: day(1), Runs: 20},
+
+		// outside the long window
+		{OrgLogin: "istio", RepoName: "istio", TestName: "ancient", Day: day(30), Runs: 5, Failures: 5, FlakyFailures: 5},
+	}
+
+	scores := testflakes.ScoreFlakes(days, now)
+	if len(scores) != 2 {
+		t.Fatalf("got %d scores, expected 2", len(scores))
+	}
+
+	pilot, unit := scores[0], scores[1]
+	if pilot.TestName != "integ-pilot" || unit.TestName != "unit" {
+		t.Fatalf("got order %s, %s; expected integ-pilot first", pilot.TestName, unit.TestName)
+	}
+
+	if pilot.Short.Runs != 20 || pilot.Short.FlakyFailures != 4 || pilot.Short.PullRequests != 2 || pilot.Short.Rate != 0.2 {
+		t.Errorf("unexpected short window for integ-pilot: %+v", pilot.Short)
+	}
+
+	if pilot.Long.Runs != 30 || pilot.Long.Failures != 5 || pilot.Long.PullRequests != 2 {
+		t.Errorf("unexpected long window for integ-pilot: %+v", pilot.Long)
+	}
+
+	if pilot.Trend <= 0 {
+		t.Errorf("expected integ-pilot to trend flakier, got %v", pilot.Trend)
+	}
+
+	if unit.Short.Rate != 0 || unit.Long.Rate != 0.2 || unit.Long.PullRequests != 3 || unit.Trend != -0.2 {
+		t.Errorf("unexpected score for unit: %+v", unit)
+	}
+
+	testflakes.SortScores(scores, testflakes.ByLongPRs)
+	if scores[0].TestName != "unit" {
+		t.Errorf("expected unit first when sorting by PRs over the long window, got %s", scores[0].TestName)
+	}
+
+	testflakes.SortScores(scores, testflakes.ByTrend)
+	if scores[0].TestName != "integ-pilot" {
+		t.Errorf("expected integ-pilot first when sorting by trend, got %s", scores[0].TestName)
+	}
+}
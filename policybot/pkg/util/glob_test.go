@@ -0,0 +1,52 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"testing"
+)
+
+func TestCompileGlob(t *testing.T) {
+	cases := []struct {
+		glob  string
+		path  string
+		match bool
+	}{
+		{"pilot/pkg/networking/**", "pilot/pkg/networking/core/v1alpha3/listener.go", true},
+		{"pilot/pkg/networking/**", "pilot/pkg/networking.go", false},
+		{"pilot/*.go", "pilot/main.go", true},
+		{"pilot/*.go", "pilot/pkg/main.go", false},
+		{"**/*.md", "README.md", true},
+		{"**/*.md", "docs/a/b/README.md", true},
+		{"**/*.md", "docs/README.mdx", false},
+		{"go.?od", "go.mod", true},
+		{"releasenotes/notes/*.yaml", "releasenotes/notes/fix.yaml", true},
+		{"a+b/*", "a+b/c", true},
+		{"a+b/*", "aab/c", false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.glob+"~"+c.path, func(t *testing.T) {
+			r, err := CompileGlob(c.glob)
+			if err != nil {
+				t.Fatalf("unable to compile glob %s: %v", c.glob, err)
+			}
+
+			if got := r.MatchString(c.path); got != c.match {
+				t.Errorf("got %v, expected %v", got, c.match)
+			}
+		})
+	}
+}